@@ -4,16 +4,15 @@ import (
 	"fmt"
 	"github.com/gogf/gf/v2/encoding/gyaml"
 	"github.com/gogf/gf/v2/os/gfile"
-	"github.com/gogf/gf/v2/util/gconv"
-	"gorm.io/driver/mysql"
-	"gorm.io/driver/postgres"
-	"gorm.io/driver/sqlite"
-	"gorm.io/driver/sqlserver"
+	"github.com/gogf/gf/v2/text/gstr"
+	"gopkg.in/yaml.v3"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
-	"gorm.io/gorm/schema"
 	"log"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -32,20 +31,27 @@ const (
 	DbPostgres  string = "postgres"
 	DbSQLite    string = "sqlite"
 	DbSQLServer string = "sqlserver"
+
+	DbMySQLDDL    string = "mysql-ddl"    // dsn is comma separated list of sql files or globs
+	DbPostgresDDL string = "postgres-ddl" // dsn is comma separated list of sql files or globs
 )
 
 var (
 	App = &Conf{
-		DSN:           "host=192.168.32.130 user=postgres password=postgres dbname=status port=5432 sslmode=disable TimeZone=Asia/Shanghai",
-		DB:            DbPostgres,
-		OutPath:       "./app/dao",
-		WithUnitTest:  true,
-		ModelPkgName:  "",
-		FieldNullable: false,
-		//FieldWithIndexTag: false,
-		//FieldWithTypeTag:  false,
-		FieldSignable: false,
+		DSN:               "${GEN_DSN}", // set GEN_DSN, or write dsn with ${VAR} and ${file:path} references
+		DB:                DbPostgres,
+		OutPath:           "./app/dao",
+		WithUnitTest:      true,
+		ModelPkgName:      "",
+		FieldNullable:     false,
+		FieldWithIndexTag: true,
+		FieldWithTypeTag:  true,
+		FieldSignable:     false,
+		Mode:              []string{ModeDefaultQuery, ModeWithoutContext, ModeQueryInterface},
 	}
+
+	// Apps configurations to generate, one per profile of database.yml, or App itself when no profile declared
+	Apps []*Conf
 )
 
 const (
@@ -53,64 +59,346 @@ const (
 )
 
 type Conf struct {
-	*Generator    `yaml:"-"`
-	Reset         bool     `yaml:"reset"`         //是否重置数据库
-	DSN           string   `yaml:"dsn"`           // consult[https://gorm.io/docs/connecting_to_the_database.html]"
-	DB            string   `yaml:"db"`            // input mysql or postgres or sqlite or sqlserver. consult[https://gorm.io/docs/connecting_to_the_database.html]
-	Tables        []string `yaml:"tables"`        // enter the required data table or leave it blank
-	OnlyModel     bool     `yaml:"onlyModel"`     // only generate model
-	OutPath       string   `yaml:"outPath"`       // specify a directory for output
-	OutFile       string   `yaml:"outFile"`       // query code file name, default: gen.go
-	WithUnitTest  bool     `yaml:"withUnitTest"`  // generate unit test for query code
-	ModelPkgName  string   `yaml:"modelPkgName"`  // generated model code's package name
-	FieldNullable bool     `yaml:"fieldNullable"` // generate with pointer when field is nullable
-	//FieldWithIndexTag bool     `yaml:"fieldWithIndexTag"` // generate field with gorm index tag
-	//FieldWithTypeTag  bool     `yaml:"fieldWithTypeTag"`  // generate field with gorm column type tag
-	FieldSignable bool `yaml:"fieldSignable"` // detect integer field's unsigned type, adjust generated data type
+	*Generator        `yaml:"-"`
+	Reset             bool     `yaml:"reset"`             //是否重置数据库, tables are dropped in scope of resetConf
+	DSN               string   `yaml:"dsn"`               // consult[https://gorm.io/docs/connecting_to_the_database.html]"
+	DB                string   `yaml:"db"`                // input mysql or postgres or sqlite or sqlserver. consult[https://gorm.io/docs/connecting_to_the_database.html]
+	Tables            []string `yaml:"tables"`            // enter the required data table or leave it blank, same as include
+	Include           []string `yaml:"include,omitempty"` // include rules of tables: glob, e.g. order_*, or regexp in slashes, e.g. /^log_\d+$/
+	Exclude           []string `yaml:"exclude,omitempty"` // exclude rules of tables, win over include
+	OnlyModel         bool     `yaml:"onlyModel"`         // only generate model
+	OutPath           string   `yaml:"outPath"`           // specify a directory for output
+	OutFile           string   `yaml:"outFile"`           // query code file name, default: gen.go
+	WithUnitTest      bool     `yaml:"withUnitTest"`      // generate unit test for query code
+	ModelPkgName      string   `yaml:"modelPkgName"`      // generated model code's package name or path
+	FieldNullable     bool     `yaml:"fieldNullable"`     // generate with pointer when field is nullable
+	FieldCoverable    bool     `yaml:"fieldCoverable"`    // generate with pointer when field has default value
+	FieldWithIndexTag bool     `yaml:"fieldWithIndexTag"` // generate field with gorm index tag
+	FieldWithTypeTag  bool     `yaml:"fieldWithTypeTag"`  // generate field with gorm column type tag
+	FieldSignable     bool     `yaml:"fieldSignable"`     // detect integer field's unsigned type, adjust generated data type
+	Incremental       bool     `yaml:"incremental"`       // skip rendering models whose schema and config are unchanged since last run
+	Mode              []string `yaml:"mode"`              // generate mode: defaultQuery, withoutContext, queryInterface
+
+	ImportPkgPaths []string          `yaml:"importPkgPaths,omitempty"` // extra import paths of generated code
+	TemplateDir    string            `yaml:"templateDir,omitempty"`    // template overrides and extra templates, see Config.WithTemplateFS
+	DataTypeMap    map[string]string `yaml:"dataTypeMap,omitempty"`    // column database type -> go type, e.g. tinyint: bool
+	JSONTag        string            `yaml:"jsonTag,omitempty"`        // json tag naming strategy: column(default), snake, camel, lowerCamel
+	Naming         NamingConf        `yaml:"naming,omitempty"`         // model and file naming strategy
+	ResetConf      ResetConf         `yaml:"resetConf,omitempty"`      // scope and safety options of reset
+	MigrationDir   string            `yaml:"migrationDir,omitempty"`   // write up/down sql migrations of linked models into dir instead of auto migrating
+	ERDiagram      []string          `yaml:"erDiagram,omitempty"`      // entity relationship diagram formats written into outPath: mermaid, plantuml, dot
+	DataDictionary []string          `yaml:"dataDictionary,omitempty"` // data dictionary formats written into outPath: markdown, html
+	NewTags        map[string]string `yaml:"newTags,omitempty"`        // extra tags of every field, tag name -> naming strategy of its value, see JSONTag
+	DBName         string            `yaml:"dbName,omitempty"`         // database or schema tables are read from, default: current one of dsn
+	InferRelations bool              `yaml:"inferRelations,omitempty"` // generate relation fields from foreign keys and join tables
+	Interfaces     []InterfaceConf   `yaml:"interfaces,omitempty"`     // DIY method interfaces applied to tables
+
+	TableConf map[string]*TableConf `yaml:"tableConf,omitempty"` // per table overrides, by table name
+
+	Name     string               `yaml:"-"`                  // profile name, empty for top level block
+	Profiles map[string]yaml.Node `yaml:"profiles,omitempty"` // named database profiles, inherit settings of top level block
 }
 
-func (r *Conf) Connect() (err error) {
-	if r.DSN == "" {
-		return fmt.Errorf("dsn cannot be empty")
+// NamingConf naming strategy of models and files
+type NamingConf struct {
+	TrimTablePrefix []string `yaml:"trimTablePrefix,omitempty"` // prefixes trimmed from table name before naming model and file
+	ModelPrefix     string   `yaml:"modelPrefix,omitempty"`     // prefix of model struct name
+	ModelSuffix     string   `yaml:"modelSuffix,omitempty"`     // suffix of model struct name
+	FileName        string   `yaml:"fileName,omitempty"`        // file name strategy: table(default), snake case of model
+}
+
+// TableConf overrides of one table
+type TableConf struct {
+	ModelName string                  `yaml:"modelName,omitempty"` // model struct name
+	Ignore    []string                `yaml:"ignore,omitempty"`    // ignored columns
+	Rename    map[string]string       `yaml:"rename,omitempty"`    // column -> field name
+	Type      map[string]string       `yaml:"type,omitempty"`      // column -> field type
+	Tag       map[string]FieldTagConf `yaml:"tag,omitempty"`       // column -> tags
+	JSONTag   string                  `yaml:"jsonTag,omitempty"`   // json tag naming strategy of the table, see Conf.JSONTag
+}
+
+// FieldTagConf tags of a field, empty tag is kept as generated
+type FieldTagConf struct {
+	GORM string `yaml:"gorm,omitempty"`
+	JSON string `yaml:"json,omitempty"`
+}
+
+// generate mode names used in yaml
+const (
+	ModeDefaultQuery   = "defaultQuery"
+	ModeWithoutContext = "withoutContext"
+	ModeQueryInterface = "queryInterface"
+)
+
+// profiles resolve named profiles, each one starts from settings of r and overrides them with its own
+func (r *Conf) profiles() ([]*Conf, error) {
+	if len(r.Profiles) == 0 {
+		return []*Conf{r}, nil
+	}
+	base, err := yaml.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(r.Profiles))
+	for name := range r.Profiles {
+		names = append(names, name)
 	}
-	switch r.DB {
-	case DbMySQL:
-		if r.db, err = gorm.Open(mysql.Open(r.DSN), &gorm.Config{Logger: newLogger}); err != nil {
-			return
+	sort.Strings(names)
+	confs := make([]*Conf, 0, len(names))
+	outPaths := make(map[string]string, len(names))
+	for _, name := range names {
+		conf := new(Conf)
+		if err = yaml.Unmarshal(base, conf); err != nil {
+			return nil, err
 		}
-		return nil
-	case DbPostgres:
-		if r.db, err = gorm.Open(postgres.Open(r.DSN), &gorm.Config{Logger: newLogger}); err != nil {
-			return
+		node := r.Profiles[name]
+		if err = node.Decode(conf); err != nil {
+			return nil, fmt.Errorf("decode profile %s fail: %w", name, err)
 		}
-		return nil
-	case DbSQLite:
-		if r.db, err = gorm.Open(sqlite.Open(r.DSN), &gorm.Config{Logger: newLogger}); err != nil {
-			return
+		conf.Name, conf.Profiles = name, nil
+
+		outPath, err := filepath.Abs(conf.OutPath)
+		if err != nil {
+			return nil, fmt.Errorf("profile %s: outpath is invalid: %w", name, err)
 		}
-		return nil
-	case DbSQLServer:
-		if r.db, err = gorm.Open(sqlserver.Open(r.DSN), &gorm.Config{Logger: newLogger}); err != nil {
-			return
+		if other, ok := outPaths[outPath]; ok {
+			return nil, fmt.Errorf("profiles %s and %s generate into the same outPath %s", other, name, conf.OutPath)
 		}
-		return nil
-	default:
-		return fmt.Errorf("unknow db %q (support mysql || postgres || sqlite || sqlserver for now)", r.DB)
-	}
-}
-func (r *Conf) Delete() {
-	if r.Reset {
-		var (
-			err    error
-			db     = r.db
-			tables = make([]string, 0)
-		)
-		if tables, err = db.Migrator().GetTables(); err == nil {
-			if err = db.Migrator().DropTable(gconv.SliceAny(tables)...); err != nil {
-				log.Println("删除失败", err)
+		outPaths[outPath] = name
+		confs = append(confs, conf)
+	}
+	return confs, nil
+}
+
+// build create generator from yaml config
+func (r *Conf) build() error {
+	cfg, err := r.config()
+	if err != nil {
+		return err
+	}
+	r.Generator = NewGenerator(cfg)
+	if len(r.ERDiagram) > 0 {
+		r.Use(erDiagramOf(r.ERDiagram))
+	}
+	if len(r.DataDictionary) > 0 {
+		r.Use(dataDictionaryOf(r.DataDictionary))
+	}
+	return nil
+}
+
+// config build generator config from yaml
+func (r *Conf) config() (Config, error) {
+	cfg := Config{
+		OutPath:           r.OutPath,
+		OutFile:           r.OutFile,
+		ModelPkgPath:      r.ModelPkgName,
+		WithUnitTest:      r.WithUnitTest,
+		Incremental:       r.Incremental,
+		FieldNullable:     r.FieldNullable,
+		FieldCoverable:    r.FieldCoverable,
+		FieldWithIndexTag: r.FieldWithIndexTag,
+		FieldWithTypeTag:  r.FieldWithTypeTag,
+		FieldSignable:     r.FieldSignable,
+	}
+	cfg.WithDialect(r.DB)
+	cfg.WithTableFilter(append(append([]string(nil), r.Tables...), r.Include...), r.Exclude)
+
+	modes := map[string]GenerateMode{ModeDefaultQuery: WithDefaultQuery, ModeWithoutContext: WithoutContext, ModeQueryInterface: WithQueryInterface}
+	for _, name := range r.Mode {
+		mode, ok := modes[name]
+		if !ok {
+			return cfg, fmt.Errorf("unknown mode %q (support %s || %s || %s)", name, ModeDefaultQuery, ModeWithoutContext, ModeQueryInterface)
+		}
+		cfg.Mode |= mode
+	}
+
+	if len(r.ImportPkgPaths) > 0 {
+		cfg.WithImportPkgPath(r.ImportPkgPaths...)
+	}
+	if r.TemplateDir != "" {
+		cfg.WithTemplateDir(r.TemplateDir)
+	}
+	if r.MigrationDir != "" {
+		cfg.WithMigrationPlan(r.MigrationDir)
+	}
+	if len(r.DataTypeMap) > 0 {
+		dataTypeMap := make(map[string]func(detailType string) (dataType string), len(r.DataTypeMap))
+		for dbType, goType := range r.DataTypeMap {
+			goType := goType
+			dataTypeMap[dbType] = func(string) string { return goType }
+		}
+		cfg.WithDataTypeMap(dataTypeMap)
+	}
+	if r.JSONTag != "" {
+		ns, err := jsonTagNS(r.JSONTag)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.WithJSONTagNameStrategy(ns)
+	}
+	if len(r.NewTags) > 0 {
+		ns, err := newTagNS(r.NewTags)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.WithNewTagNameStrategy(ns)
+	}
+	if r.DBName != "" {
+		dbName := r.DBName
+		cfg.WithDbNameOpts(func(*gorm.DB) string { return dbName })
+	}
+	if r.InferRelations {
+		cfg.WithRelationInference(RelationInference{ForeignKeys: true, ManyToMany: true})
+	}
+
+	if err := r.withNaming(&cfg); err != nil {
+		return cfg, err
+	}
+	strategies, err := yaml.Marshal(map[string]interface{}{
+		"dataTypeMap": r.DataTypeMap, "jsonTag": r.JSONTag, "newTags": r.NewTags, "naming": r.Naming, "tableConf": r.TableConf,
+	})
+	if err != nil {
+		return cfg, err
+	}
+	cfg.strategyFingerprint = string(strategies)
+	for table, tc := range r.TableConf {
+		if tc == nil {
+			continue
+		}
+		if _, err := jsonTagNS(tc.JSONTag); tc.JSONTag != "" && err != nil {
+			return cfg, fmt.Errorf("table %s: %w", table, err)
+		}
+	}
+	return cfg, nil
+}
+
+// withNaming set model and file naming strategies
+func (r *Conf) withNaming(cfg *Config) error {
+	n := r.Naming
+	trim := func(tableName string) string {
+		for _, prefix := range n.TrimTablePrefix {
+			if strings.HasPrefix(tableName, prefix) {
+				return strings.TrimPrefix(tableName, prefix)
 			}
 		}
+		return tableName
+	}
+	modelName := func(tableName string) string {
+		if tc := r.TableConf[tableName]; tc != nil && tc.ModelName != "" {
+			return tc.ModelName
+		}
+		return n.ModelPrefix + r.db.Config.NamingStrategy.SchemaName(trim(tableName)) + n.ModelSuffix
+	}
+
+	hasModelName := false
+	for _, tc := range r.TableConf {
+		hasModelName = hasModelName || (tc != nil && tc.ModelName != "")
+	}
+	if hasModelName || len(n.TrimTablePrefix) > 0 || n.ModelPrefix != "" || n.ModelSuffix != "" {
+		cfg.WithModelNameStrategy(modelName)
 	}
+
+	switch n.FileName {
+	case "", "table":
+		if len(n.TrimTablePrefix) > 0 {
+			cfg.WithFileNameStrategy(func(tableName string) string { return strings.ToLower(trim(tableName)) })
+		}
+	case "model":
+		cfg.WithFileNameStrategy(func(tableName string) string { return gstr.CaseSnake(modelName(tableName)) })
+	default:
+		return fmt.Errorf("unknown file name strategy %q (support table || model)", n.FileName)
+	}
+	return nil
+}
+
+// jsonTagNS json tag naming strategy by name
+func jsonTagNS(name string) (func(columnName string) string, error) {
+	switch name {
+	case "column":
+		return func(columnName string) string { return columnName }, nil
+	case "snake":
+		return gstr.CaseSnake, nil
+	case "camel":
+		return gstr.CaseCamel, nil
+	case "lowerCamel":
+		return gstr.CaseCamelLower, nil
+	default:
+		return nil, fmt.Errorf("unknown json tag strategy %q (support column || snake || camel || lowerCamel)", name)
+	}
+}
+
+// newTagNS tag naming strategy of extra tags, tags are sorted by name
+func newTagNS(tags map[string]string) (func(columnName string) string, error) {
+	names := make([]string, 0, len(tags))
+	strategies := make(map[string]func(string) string, len(tags))
+	for name, strategy := range tags {
+		ns, err := jsonTagNS(strategy)
+		if err != nil {
+			return nil, fmt.Errorf("tag %s: %w", name, err)
+		}
+		names = append(names, name)
+		strategies[name] = ns
+	}
+	sort.Strings(names)
+	return func(columnName string) string {
+		tags := make([]string, len(names))
+		for i, name := range names {
+			tags[i] = fmt.Sprintf("%s:%q", name, strategies[name](columnName))
+		}
+		return strings.Join(tags, " ")
+	}, nil
+}
+
+// tableOpts model options of per table overrides
+func (r *Conf) tableOpts(tableName string) (opts []ModelOpt) {
+	tc := r.TableConf[tableName]
+	if tc == nil {
+		return nil
+	}
+	if len(tc.Ignore) > 0 {
+		opts = append(opts, FieldIgnore(tc.Ignore...))
+	}
+	if tc.JSONTag != "" {
+		if ns, err := jsonTagNS(tc.JSONTag); err == nil {
+			opts = append(opts, FieldJSONTagWithNS(ns))
+		}
+	}
+	for _, column := range sortedKeys(tc.Rename) {
+		opts = append(opts, FieldRename(column, tc.Rename[column]))
+	}
+	for _, column := range sortedKeys(tc.Type) {
+		opts = append(opts, FieldType(column, tc.Type[column]))
+	}
+	columns := make([]string, 0, len(tc.Tag))
+	for column := range tc.Tag {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	for _, column := range columns {
+		if tag := tc.Tag[column]; tag.GORM != "" {
+			opts = append(opts, FieldGORMTag(column, tag.GORM))
+		}
+		if tag := tc.Tag[column]; tag.JSON != "" {
+			opts = append(opts, FieldJSONTag(column, tag.JSON))
+		}
+	}
+	return opts
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Connect open database with dialect registered as db, see RegisterDialector
+func (r *Conf) Connect() (err error) {
+	r.db, err = OpenDB(r.DB, r.DSN, &gorm.Config{Logger: newLogger})
+	return err
 }
 
 func (r *Conf) GenModels() (models []interface{}, err error) {
@@ -120,46 +408,109 @@ func (r *Conf) GenModels() (models []interface{}, err error) {
 	if tablesList, err = r.db.Migrator().GetTables(); err != nil {
 		return nil, fmt.Errorf("GORM migrator get all tables fail: %w", err)
 	}
-	models = make([]interface{}, len(tablesList))
-	for i, tableName := range tablesList {
-		models[i] = r.GenerateModel(tableName)
+	if tablesList, err = r.filterTables(tablesList); err != nil {
+		return nil, err
 	}
-	return models, nil
+	var errs errCollector
+	models = make([]interface{}, 0, len(tablesList))
+	for _, tableName := range tablesList {
+		meta, err := r.GenerateModelE(tableName, r.tableOpts(tableName)...)
+		if err != nil {
+			errs.Add(tableName, "", err)
+			continue
+		}
+		models = append(models, meta)
+	}
+	return models, errs.Err()
 }
 
-func Parse() {
-	if !gfile.Exists("database.yml") {
-		if encode, err := gyaml.Encode(App); err != nil {
-			log.Fatalf("encode fail: %v", err)
-		} else {
-			if err = gfile.PutBytes("database.yml", encode); err != nil {
-				log.Fatalf("write fail: %v", err)
-			}
+// Generate connect database and generate code of the profile, models are linked before reading tables
+func (r *Conf) Generate(models ...any) error {
+	if err := r.Connect(); err != nil {
+		return fmt.Errorf("connect db fail: %w", err)
+	}
+	if err := r.Delete(); err != nil {
+		return fmt.Errorf("reset db fail: %w", err)
+	}
+	if len(models) > 0 {
+		if err := r.LinkModel(models...); err != nil {
+			return fmt.Errorf("link model fail: %w", err)
+		}
+	}
+	tableModels, err := r.GenModels()
+	if err != nil {
+		return err
+	}
+	if !r.OnlyModel {
+		if err = r.ApplyBasicE(tableModels...); err != nil {
+			return err
 		}
-		log.Fatalln("create database.yml success")
-	} else {
-		if decode := gfile.GetBytes("database.yml"); decode == nil {
-			log.Fatalf("read fail")
-		} else {
-			if err := gyaml.DecodeTo(decode, App); err != nil {
-				log.Fatalf("decode fail: %v", err)
+		if err = r.ApplyInterfaceConfE(r.Interfaces, tableModels...); err != nil {
+			return err
+		}
+	}
+	return r.ExecuteE()
+}
+
+// GenerateAll generate code of every profile in Apps
+func GenerateAll() error {
+	for _, conf := range Apps {
+		if err := conf.Generate(); err != nil {
+			if conf.Name == "" {
+				return err
 			}
+			return fmt.Errorf("profile %s: %w", conf.Name, err)
 		}
 	}
-	App.Generator = NewGenerator(Config{
-		Mode:              WithDefaultQuery | WithoutContext | WithQueryInterface,
-		OutPath:           App.OutPath,
-		OutFile:           App.OutFile,
-		ModelPkgPath:      App.ModelPkgName,
-		WithUnitTest:      App.WithUnitTest,
-		FieldNullable:     App.FieldNullable,
-		FieldWithIndexTag: true,
-		FieldWithTypeTag:  true,
-		FieldSignable:     App.FieldSignable,
-	})
-	App.Generator.Schema = Schema{
-		Schema:    make(map[string]*schema.Schema),
-		Model:     make(map[string]any),
-		Generator: App.Generator,
+	return nil
+}
+
+// Profile return configuration of named profile, nil if not exists
+func Profile(name string) *Conf {
+	for _, conf := range Apps {
+		if conf.Name == name {
+			return conf
+		}
+	}
+	return nil
+}
+
+// ConfigFile path of database.yml read by Parse
+var ConfigFile = "database.yml"
+
+// Parse read ConfigFile into App, overlay database.<GEN_ENV>.yml when GEN_ENV is set,
+// then create generators of App and every profile. A default ConfigFile is created and
+// ErrConfigCreated is returned if it does not exist.
+func Parse() error {
+	if !gfile.Exists(ConfigFile) {
+		encode, err := gyaml.Encode(App)
+		if err != nil {
+			return fmt.Errorf("encode config fail: %w", err)
+		}
+		if err = gfile.PutBytes(ConfigFile, encode); err != nil {
+			return fmt.Errorf("write config fail: %w", err)
+		}
+		return ErrConfigCreated
+	}
+	if err := LoadConfigFile(ConfigFile, os.Getenv(ConfigEnv), App); err != nil {
+		return err
+	}
+
+	if err := App.build(); err != nil {
+		return fmt.Errorf("parse config fail: %w", err)
+	}
+	confs, err := App.profiles()
+	if err != nil {
+		return fmt.Errorf("parse profiles fail: %w", err)
+	}
+	for _, conf := range confs {
+		if conf == App {
+			continue
+		}
+		if err = conf.build(); err != nil {
+			return fmt.Errorf("parse profile %s fail: %w", conf.Name, err)
+		}
 	}
+	Apps = confs
+	return nil
 }
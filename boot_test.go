@@ -0,0 +1,228 @@
+package gen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const testBootYAML = `
+db: sqlite
+mode: [defaultQuery, queryInterface]
+fieldCoverable: true
+jsonTag: lowerCamel
+importPkgPaths: [github.com/shopspring/decimal]
+dataTypeMap:
+  integer: int32
+naming:
+  trimTablePrefix: [t_]
+  modelSuffix: Entity
+  fileName: model
+tableConf:
+  t_orders:
+    modelName: Purchase
+    ignore: [secret]
+    rename:
+      user_name: Buyer
+    type:
+      amount: decimal.Decimal
+    tag:
+      amount:
+        json: amount_cents
+  t_users:
+    jsonTag: snake
+`
+
+func TestConf_Config(t *testing.T) {
+	conf := &Conf{FieldWithIndexTag: true}
+	if err := yaml.Unmarshal([]byte(testBootYAML), conf); err != nil {
+		t.Fatalf("decode yaml fail: %s", err)
+	}
+	conf.OutPath = filepath.Join(newModuleDir(t), "query")
+	conf.DSN = "file:" + filepath.Join(t.TempDir(), "boot.db")
+	cfg, err := conf.config()
+	if err != nil {
+		t.Fatalf("build config fail: %s", err)
+	}
+	if cfg.Mode != WithDefaultQuery|WithQueryInterface || !cfg.FieldCoverable || !cfg.FieldWithIndexTag {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+
+	conf.Generator = NewGenerator(cfg)
+	if err = conf.Connect(); err != nil {
+		t.Fatalf("connect fail: %s", err)
+	}
+	for _, sql := range []string{
+		"CREATE TABLE t_orders (id integer primary key, user_name text, amount text, secret text, created_at datetime)",
+		"CREATE TABLE t_users (id integer primary key, user_name text)",
+	} {
+		if err = conf.db.Exec(sql).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	models, err := conf.GenModels()
+	if err != nil {
+		t.Fatalf("generate models fail: %s", err)
+	}
+
+	fields := make(map[string]string)
+	for _, m := range models {
+		meta := m.(*QueryStructMeta)
+		for _, f := range meta.Fields {
+			fields[meta.ModelStructName+"."+f.Name] = f.Type + " " + f.Tags()
+		}
+		if meta.ModelStructName == "Purchase" && meta.FileName != "purchase" {
+			t.Errorf("expect file name purchase, got %s", meta.FileName)
+		}
+	}
+	for name, expects := range map[string][]string{
+		"Purchase.ID":         {"int32 "},
+		"Purchase.Buyer":      {`json:"userName"`},
+		"Purchase.Amount":     {"decimal.Decimal ", `json:"amount_cents"`},
+		"UserEntity.UserName": {`json:"user_name"`},
+	} {
+		for _, expect := range expects {
+			if !strings.Contains(fields[name], expect) {
+				t.Errorf("expect %s contains %q, got %q", name, expect, fields[name])
+			}
+		}
+	}
+	if _, ok := fields["Purchase.Secret"]; ok {
+		t.Errorf("expect column secret ignored")
+	}
+}
+
+func TestConf_Generate(t *testing.T) {
+	dir := newModuleDir(t)
+	methodDir := filepath.Join(dir, "method")
+	if err := os.MkdirAll(methodDir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(methodDir, "querier.go"), []byte(testQuerierSource), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &Conf{}
+	if err := yaml.Unmarshal([]byte(`
+db: sqlite
+modelPkgName: query
+mode: [defaultQuery]
+dbName: main
+newTags:
+  xml: camel
+  form: snake
+interfaces:
+  - name: Querier
+    package: `+methodDir+`
+    tables: [users]
+`), conf); err != nil {
+		t.Fatalf("decode yaml fail: %s", err)
+	}
+	conf.OutPath = filepath.Join(dir, "query")
+	conf.DSN = "file:" + filepath.Join(dir, "boot.db")
+	if err := conf.build(); err != nil {
+		t.Fatalf("build fail: %s", err)
+	}
+	if len(conf.dbNameOpts) != 1 || conf.dbNameOpts[0](nil) != "main" {
+		t.Errorf("expect db name option of main")
+	}
+	if err := conf.Connect(); err != nil {
+		t.Fatalf("connect fail: %s", err)
+	}
+	for _, sql := range []string{
+		"CREATE TABLE users (id integer primary key, user_name text)",
+		"CREATE TABLE orders (id integer primary key, amount integer)",
+	} {
+		if err := conf.db.Exec(sql).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := conf.Generate(); err != nil {
+		t.Fatalf("generate fail: %s", err)
+	}
+
+	users, err := os.ReadFile(filepath.Join(conf.OutPath, "users.gen.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	orders, err := os.ReadFile(filepath.Join(conf.OutPath, "orders.gen.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(users), "FindByName(name string)") || strings.Contains(string(orders), "FindByName") {
+		t.Errorf("expect Querier applied to users only")
+	}
+	user, err := os.ReadFile(filepath.Join(conf.OutPath, "users.model.gen.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(user), `json:"user_name" form:"user_name" xml:"UserName"`) {
+		t.Errorf("expect new tags in users model")
+	}
+}
+
+func TestConf_ConfigInvalid(t *testing.T) {
+	for _, c := range []*Conf{
+		{Mode: []string{"withContext"}},
+		{JSONTag: "kebab"},
+		{NewTags: map[string]string{"form": "kebab"}},
+		{Naming: NamingConf{FileName: "hash"}},
+		{TableConf: map[string]*TableConf{"users": {JSONTag: "upper"}}},
+	} {
+		if _, err := c.config(); err == nil {
+			t.Errorf("expect error for %+v", c)
+		}
+	}
+}
+
+func TestConf_Profiles(t *testing.T) {
+	dir := newModuleDir(t)
+	conf := &Conf{DB: DbSQLite, FieldWithIndexTag: true, ModelPkgName: "query", Mode: []string{ModeDefaultQuery, ModeWithoutContext, ModeQueryInterface}}
+	content := `
+outPath: ` + filepath.Join(dir, "query") + `
+fieldNullable: true
+profiles:
+  billing:
+    dsn: file:` + filepath.Join(dir, "billing.db") + `
+    outPath: ` + filepath.Join(dir, "billing", "query") + `
+  users:
+    dsn: file:` + filepath.Join(dir, "users.db") + `
+    outPath: ` + filepath.Join(dir, "users", "query") + `
+    fieldNullable: false
+`
+	if err := yaml.Unmarshal([]byte(content), conf); err != nil {
+		t.Fatalf("decode yaml fail: %s", err)
+	}
+	confs, err := conf.profiles()
+	if err != nil {
+		t.Fatalf("resolve profiles fail: %s", err)
+	}
+	if len(confs) != 2 || confs[0].Name != "billing" || confs[1].Name != "users" {
+		t.Fatalf("unexpected profiles: %+v", confs)
+	}
+	if !confs[0].FieldNullable || confs[1].FieldNullable || confs[0].DB != DbSQLite || !confs[1].FieldWithIndexTag {
+		t.Errorf("expect profiles inherit top level settings, got %+v %+v", confs[0], confs[1])
+	}
+
+	for _, c := range confs {
+		if err = c.build(); err != nil {
+			t.Fatalf("build profile %s fail: %s", c.Name, err)
+		}
+		if err = c.Generate(goldenCompany{}); err != nil {
+			t.Fatalf("generate profile %s fail: %s", c.Name, err)
+		}
+		content, err := os.ReadFile(filepath.Join(dir, c.Name, "query", "gen.go"))
+		if err != nil || !strings.Contains(string(content), "func SetDefault(") {
+			t.Errorf("expect query entry of profile %s, got %v", c.Name, err)
+		}
+	}
+
+	conf = new(Conf)
+	_ = yaml.Unmarshal([]byte("outPath: query\nprofiles:\n  a: {}\n  b: {dsn: b.db}\n"), conf)
+	if _, err = conf.profiles(); err == nil || !strings.Contains(err.Error(), "same outPath") {
+		t.Errorf("expect duplicated outPath error, got %v", err)
+	}
+}
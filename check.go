@@ -0,0 +1,76 @@
+package gen
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"sort"
+	"sync"
+
+	"gorm.io/gen/internal/utils/diff"
+)
+
+// ErrCodeStale generated code on disk differs from what generator produces
+var ErrCodeStale = errors.New("generated code is stale, please regenerate")
+
+// FileDiff difference between a file on disk and generated code
+type FileDiff struct {
+	File    string // file path
+	Missing bool   // file does not exist on disk
+	Removed bool   // file is stale and will be removed
+	Diff    string // unified diff from file on disk to generated code
+}
+
+// checker compare generated code with files on disk instead of writing them
+type checker struct {
+	mu    sync.Mutex
+	out   Output
+	diffs []*FileDiff
+}
+
+// compare record diff between content and file on disk
+func (c *checker) compare(fileName string, content []byte) error {
+	onDisk, err := c.out.ReadFile(fileName)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err == nil && bytes.Equal(onDisk, content) {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.diffs = append(c.diffs, &FileDiff{
+		File:    fileName,
+		Missing: errors.Is(err, fs.ErrNotExist),
+		Diff:    diff.Unified(fileName, fileName+" (generated)", string(onDisk), string(content)),
+	})
+	return nil
+}
+
+// Check render all model, query, field and test files into memory, compare them with
+// files on disk and return diffs of stale files, nothing is written to disk.
+// The returned error is ErrCodeStale if any file is stale, or generation errors.
+func (g *Generator) Check() ([]*FileDiff, error) {
+	g.checker = &checker{out: g.out}
+	defer func() { g.checker = nil }()
+
+	if err := g.ExecuteE(); err != nil {
+		return nil, err
+	}
+
+	diffs := g.checker.diffs
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].File < diffs[j].File })
+	if len(diffs) > 0 {
+		return diffs, ErrCodeStale
+	}
+	return nil, nil
+}
+
+// mkdir create directory, do nothing in check mode
+func (g *Generator) mkdir(path string) error {
+	if g.checker != nil {
+		return nil
+	}
+	return g.out.MkdirAll(path)
+}
@@ -0,0 +1,87 @@
+package gen
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gorm.io/gen/helper"
+)
+
+// test object
+type testObject struct {
+	tableName, structName string
+	fields                []helper.Field
+}
+
+func (o *testObject) TableName() string        { return o.tableName }
+func (o *testObject) StructName() string       { return o.structName }
+func (o *testObject) FileName() string         { return o.tableName }
+func (o *testObject) ImportPkgPaths() []string { return nil }
+func (o *testObject) Fields() []helper.Field   { return o.fields }
+
+type testObjectField struct {
+	name, typ, columnName, gormTag, jsonTag, tag, comment string
+}
+
+func (f *testObjectField) Name() string       { return f.name }
+func (f *testObjectField) Type() string       { return f.typ }
+func (f *testObjectField) ColumnName() string { return f.columnName }
+func (f *testObjectField) GORMTag() string    { return f.gormTag }
+func (f *testObjectField) JSONTag() string    { return f.jsonTag }
+func (f *testObjectField) Tag() string        { return f.tag }
+func (f *testObjectField) Comment() string    { return f.comment }
+
+var testUserObject = &testObject{
+	tableName:  "users",
+	structName: "User",
+	fields: []helper.Field{
+		&testObjectField{name: "ID", typ: "int64", columnName: "id", gormTag: "column:id;primaryKey", jsonTag: "id", comment: "primary key"},
+		&testObjectField{name: "Name", typ: "string", columnName: "name", gormTag: "column:name", jsonTag: "name"},
+		&testObjectField{name: "Age", typ: "int32", columnName: "age", gormTag: "column:age", jsonTag: "age"},
+	},
+}
+
+func newTestGenerator(t *testing.T, outPath string) *Generator {
+	g := NewGenerator(Config{OutPath: outPath, ModelPkgPath: "query", Mode: WithDefaultQuery | WithoutContext | WithQueryInterface})
+	g.ApplyBasic(g.GenerateModelFrom(testUserObject))
+	return g
+}
+
+func TestGenerator_Check(t *testing.T) {
+	outPath := filepath.Join(newModuleDir(t), "query")
+
+	diffs, err := newTestGenerator(t, outPath).Check()
+	if !errors.Is(err, ErrCodeStale) {
+		t.Fatalf("expect ErrCodeStale before generating, got %v", err)
+	}
+	for _, d := range diffs {
+		if !d.Missing {
+			t.Errorf("expect %s missing on disk", d.File)
+		}
+	}
+	if _, err := os.Stat(outPath); !os.IsNotExist(err) {
+		t.Fatalf("check mode should not write anything, stat %s: %v", outPath, err)
+	}
+
+	if err := newTestGenerator(t, outPath).ExecuteE(); err != nil {
+		t.Fatalf("generate code fail: %s", err)
+	}
+	if diffs, err = newTestGenerator(t, outPath).Check(); err != nil {
+		t.Fatalf("expect generated code up to date, got %v: %+v", err, diffs)
+	}
+
+	modelFile := filepath.Join(outPath, "users.model.gen.go")
+	content, _ := os.ReadFile(modelFile)
+	_ = os.WriteFile(modelFile, []byte(strings.Replace(string(content), "Age", "Old", 1)), 0640)
+
+	diffs, err = newTestGenerator(t, outPath).Check()
+	if !errors.Is(err, ErrCodeStale) {
+		t.Fatalf("expect ErrCodeStale after edit, got %v", err)
+	}
+	if len(diffs) != 1 || diffs[0].File != modelFile || !strings.Contains(diffs[0].Diff, "+\tAge") {
+		t.Errorf("unexpected diffs: %+v", diffs)
+	}
+}
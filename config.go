@@ -2,6 +2,7 @@ package gen
 
 import (
 	"fmt"
+	"io/fs"
 	"path/filepath"
 	"strings"
 
@@ -29,10 +30,15 @@ const (
 type Config struct {
 	db *gorm.DB // db connection
 
-	OutPath      string // query code path
-	OutFile      string // query code file name, default: gen.go
-	ModelPkgPath string // generated model code's package name
-	WithUnitTest bool   // generate unit test for query code
+	OutPath string // query code path
+	OutFile string // query code file name, default: gen.go
+	// generated model code's package name or path, default: model. Models are generated into OutPath
+	// when it is not set, into a sibling directory of OutPath named ModelPkgPath or into ModelPkgPath itself
+	// when it is a path. Relation helpers of models (Query<Relation>, Get<Relation>) are generated only
+	// when models are in OutPath, query package imports models otherwise
+	ModelPkgPath string
+	WithUnitTest bool // generate unit test for query code
+	Incremental  bool // skip rendering models whose schema and config are unchanged since last run, off with render hooks or strategy funcs set by code
 
 	// generate model global configuration
 	FieldNullable     bool // generate pointer when field is nullable
@@ -43,8 +49,14 @@ type Config struct {
 
 	Mode GenerateMode // generate mode
 
-	queryPkgName   string // generated query code's package name
-	modelPkgPath   string // model pkg path in target project
+	queryPkgName   string      // generated query code's package name
+	modelPkgPath   string      // model pkg path in target project
+	queryPkgPath   string      // query pkg path in target project
+	out            Output      // where generated files are written
+	reporter       Reporter    // receive progress events
+	dialect        string      // registered dialect name, see RegisterDialector
+	modelPkgUnset  bool        // ModelPkgPath is not set, models are generated into OutPath
+	tableFilter    TableFilter // include and exclude rules of tables read from db
 	dbNameOpts     []model.SchemaNameOpt
 	importPkgPaths []string
 
@@ -57,7 +69,17 @@ type Config struct {
 	fieldJSONTagNS func(columnName string) (tagContent string)
 	fieldNewTagNS  func(columnName string) (tagContent string)
 
+	strategyFingerprint string // fingerprint of strategy funcs above when they are built from text config, see Generator.unskippableReason
+
 	modelOpts []ModelOpt
+
+	relationInference *RelationInference // infer relations between models generated from tables, nil to disable
+
+	migrationDir string // write migration files of linked models into dir instead of auto migrating, see WithMigrationPlan
+
+	templateFS       fs.FS // template overrides and extra templates
+	modelTemplates   []extraTemplate
+	packageTemplates []extraTemplate
 }
 
 // WithOpts set global  model options
@@ -124,7 +146,9 @@ func (cfg *Config) WithImportPkgPath(paths ...string) {
 func (cfg *Config) Revise() (err error) {
 	if strings.TrimSpace(cfg.ModelPkgPath) == "" {
 		cfg.ModelPkgPath = model.DefaultModelPkg
+		cfg.modelPkgUnset = true
 	}
+	cfg.ModelPkgPath = strings.TrimSpace(cfg.ModelPkgPath)
 
 	cfg.OutPath, err = filepath.Abs(cfg.OutPath)
 	if err != nil {
@@ -140,6 +164,10 @@ func (cfg *Config) Revise() (err error) {
 	}
 	cfg.queryPkgName = filepath.Base(cfg.OutPath)
 
+	if cfg.out == nil {
+		cfg.out = NewFileOutput()
+	}
+
 	if cfg.db == nil {
 		cfg.db, _ = gorm.Open(tests.DummyDialector{})
 	}
@@ -0,0 +1,123 @@
+package gen
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigEnv environment variable selecting config overlay, e.g. GEN_ENV=dev loads database.dev.yml over database.yml
+const ConfigEnv = "GEN_ENV"
+
+// ErrConfigCreated config file did not exist, a default one is created for editing
+var ErrConfigCreated = errors.New("config file created, please edit it and run again")
+
+// interpolation ${VAR}, ${VAR:-default} or ${file:path}
+var interpolation = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+// LoadConfigFile decode yaml config file into out, then decode overlay <name>.<env><ext> over it when env is not empty
+// and the overlay exists. Scalar values are interpolated before decoding:
+//
+//	${VAR}          value of environment variable VAR, error if not set
+//	${VAR:-value}   value of environment variable VAR, or value if not set or empty
+//	${file:path}    content of file without trailing newline, path is relative to the config file
+func LoadConfigFile(path, env string, out interface{}) error {
+	if err := decodeConfigFile(path, out); err != nil {
+		return err
+	}
+	if env == "" {
+		return nil
+	}
+
+	overlay := ConfigOverlay(path, env)
+	if _, err := os.Stat(overlay); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return decodeConfigFile(overlay, out)
+}
+
+// ConfigOverlay return path of overlay <name>.<env><ext> of config file
+func ConfigOverlay(path, env string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + env + ext
+}
+
+func decodeConfigFile(path string, out interface{}) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config %s fail: %w", path, err)
+	}
+	var node yaml.Node
+	if err = yaml.Unmarshal(content, &node); err != nil {
+		return fmt.Errorf("parse config %s fail: %w", path, err)
+	}
+	if err = interpolateNode(&node, filepath.Dir(path)); err != nil {
+		return fmt.Errorf("config %s: %w", path, err)
+	}
+	if err = node.Decode(out); err != nil {
+		return fmt.Errorf("decode config %s fail: %w", path, err)
+	}
+	return nil
+}
+
+// interpolateNode interpolate every scalar value in node
+func interpolateNode(node *yaml.Node, dir string) (err error) {
+	if node.Kind == yaml.ScalarNode {
+		value, err := interpolate(node.Value, dir)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", node.Line, err)
+		}
+		if value != node.Value {
+			node.Value, node.Tag, node.Style = value, "", 0
+		}
+		return nil
+	}
+	for i, child := range node.Content {
+		if node.Kind == yaml.MappingNode && i%2 == 0 { // keys are kept as they are
+			continue
+		}
+		if err = interpolateNode(child, dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// interpolate expand ${...} references in value
+func interpolate(value, dir string) (string, error) {
+	var err error
+	result := interpolation.ReplaceAllStringFunc(value, func(ref string) string {
+		if err != nil {
+			return ref
+		}
+		expr := ref[2 : len(ref)-1]
+		if path := strings.TrimPrefix(expr, "file:"); path != expr {
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(dir, path)
+			}
+			content, readErr := os.ReadFile(path)
+			if readErr != nil {
+				err = fmt.Errorf("read secret file fail: %w", readErr)
+				return ref
+			}
+			return strings.TrimRight(string(content), "\r\n")
+		}
+
+		name, def, hasDef := strings.Cut(expr, ":-")
+		if v, ok := os.LookupEnv(name); ok && (v != "" || !hasDef) {
+			return v
+		}
+		if !hasDef {
+			err = fmt.Errorf("environment variable %s is not set", name)
+			return ref
+		}
+		return def
+	})
+	return result, err
+}
@@ -0,0 +1,76 @@
+package gen
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigFile(t *testing.T) {
+	dir := newModuleDir(t)
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("write %s fail: %s", name, err)
+		}
+		return path
+	}
+	path := write("database.yml", `
+db: mysql
+dsn: "${GEN_TEST_USER}:${file:secret}@tcp(${GEN_TEST_HOST:-127.0.0.1})/app"
+outPath: ./query
+tables: ["${GEN_TEST_USER}_orders"]
+`)
+	write("secret", "p@ss\n")
+	write("database.dev.yml", `
+db: sqlite
+outPath: ./dev/query
+`)
+	t.Setenv("GEN_TEST_USER", "root")
+
+	var conf Conf
+	if err := LoadConfigFile(path, "", &conf); err != nil {
+		t.Fatalf("load config fail: %s", err)
+	}
+	if want := "root:p@ss@tcp(127.0.0.1)/app"; conf.DSN != want {
+		t.Errorf("dsn: expect %q, got %q", want, conf.DSN)
+	}
+	if len(conf.Tables) != 1 || conf.Tables[0] != "root_orders" {
+		t.Errorf("tables: expect [root_orders], got %v", conf.Tables)
+	}
+
+	t.Setenv("GEN_TEST_HOST", "db.local")
+	conf = Conf{}
+	if err := LoadConfigFile(path, "dev", &conf); err != nil {
+		t.Fatalf("load config with overlay fail: %s", err)
+	}
+	if conf.DB != "sqlite" || conf.OutPath != "./dev/query" {
+		t.Errorf("overlay not applied: db=%q outPath=%q", conf.DB, conf.OutPath)
+	}
+	if want := "root:p@ss@tcp(db.local)/app"; conf.DSN != want {
+		t.Errorf("dsn: expect %q, got %q", want, conf.DSN)
+	}
+
+	if err := LoadConfigFile(path, "prod", &Conf{}); err != nil {
+		t.Errorf("missing overlay should be ignored, got %s", err)
+	}
+
+	missing := write("missing.yml", "dsn: ${GEN_TEST_NOT_SET}\n")
+	if err := LoadConfigFile(missing, "", &Conf{}); err == nil || !strings.Contains(err.Error(), "GEN_TEST_NOT_SET") {
+		t.Errorf("expect error of unset variable, got %v", err)
+	}
+}
+
+func TestParse_CreateConfig(t *testing.T) {
+	defer func(file string) { ConfigFile = file }(ConfigFile)
+	ConfigFile = filepath.Join(t.TempDir(), "database.yml")
+
+	if err := Parse(); !errors.Is(err, ErrConfigCreated) {
+		t.Fatalf("expect ErrConfigCreated, got %v", err)
+	}
+	if _, err := os.Stat(ConfigFile); err != nil {
+		t.Fatalf("default config not created: %s", err)
+	}
+}
@@ -0,0 +1,386 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"sort"
+	"strings"
+	"text/template"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+
+	"gorm.io/gen/internal/generate"
+	"gorm.io/gen/internal/model"
+)
+
+// DataDictionaryFormat format of data dictionary
+type DataDictionaryFormat string
+
+const (
+	// DataDictionaryMarkdown markdown document, written into <name>.md
+	DataDictionaryMarkdown DataDictionaryFormat = "markdown"
+	// DataDictionaryHTML standalone html page, written into <name>.html
+	DataDictionaryHTML DataDictionaryFormat = "html"
+)
+
+var dataDictionaryExts = map[DataDictionaryFormat]string{DataDictionaryMarkdown: ".md", DataDictionaryHTML: ".html"}
+
+// DataDictionary plugin writing data dictionary of generated models into OutPath on every Execute,
+// every table is documented with its columns, indexes and relations
+type DataDictionary struct {
+	Formats  []DataDictionaryFormat // default: all formats
+	FileName string                 // file name without extension, default: data_dictionary
+}
+
+// Name plugin name
+func (DataDictionary) Name() string { return "data_dictionary" }
+
+// AfterExecute write data dictionary of all models
+func (d DataDictionary) AfterExecute(g *Generator, metas []*QueryStructMeta) error {
+	formats, name := d.Formats, d.FileName
+	if len(formats) == 0 {
+		formats = []DataDictionaryFormat{DataDictionaryMarkdown, DataDictionaryHTML}
+	}
+	if name == "" {
+		name = "data_dictionary"
+	}
+	tables := g.dictTables(metas)
+	for _, format := range formats {
+		content, err := renderDataDictionary(tables, format)
+		if err != nil {
+			return err
+		}
+		if err = g.Emit(name+dataDictionaryExts[format], content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// usesDataDictionary report whether a DataDictionary plugin is registered, indexes are read with columns then
+func (g *Generator) usesDataDictionary() bool {
+	for _, p := range g.plugins {
+		if _, ok := p.(DataDictionary); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// dataDictionaryOf return DataDictionary plugin of format names
+func dataDictionaryOf(formats []string) DataDictionary {
+	d := DataDictionary{Formats: make([]DataDictionaryFormat, len(formats))}
+	for i, format := range formats {
+		d.Formats[i] = DataDictionaryFormat(strings.TrimSpace(format))
+	}
+	return d
+}
+
+// RenderDataDictionary render data dictionary of models in format, indexes read with columns are reused, read from db otherwise
+func (g *Generator) RenderDataDictionary(metas []*QueryStructMeta, format DataDictionaryFormat) ([]byte, error) {
+	return renderDataDictionary(g.dictTables(metas), format)
+}
+
+func renderDataDictionary(tables []*dictTable, format DataDictionaryFormat) ([]byte, error) {
+	var (
+		buf bytes.Buffer
+		err error
+	)
+	switch format {
+	case DataDictionaryMarkdown:
+		err = dictMarkdownTmpl.Execute(&buf, tables)
+	case DataDictionaryHTML:
+		err = dictHTMLTmpl.Execute(&buf, tables)
+	default:
+		return nil, fmt.Errorf("unknown data dictionary format %q (support %s || %s)", format, DataDictionaryMarkdown, DataDictionaryHTML)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("render data dictionary fail: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+type dictTable struct {
+	Name      string
+	Model     string
+	Columns   []dictColumn
+	Indexes   []dictIndex
+	Relations []dictRelation
+	IndexErr  string // why indexes are not available
+}
+
+type dictColumn struct {
+	Name, DBType, GoType, Nullable, Default, Comment, Key string
+}
+
+type dictIndex struct {
+	Name    string
+	Columns string
+	Unique  bool
+	Primary bool
+}
+
+type dictRelation struct {
+	Field, Type, Target, ForeignKey, References, JoinTable string
+}
+
+// dictTables build data dictionary tables of metas, columns and indexes are read from database for models of tables,
+// from gorm tags of fields for other models
+func (g *Generator) dictTables(metas []*QueryStructMeta) []*dictTable {
+	byStruct := make(map[string]*QueryStructMeta, len(metas))
+	for _, meta := range metas {
+		byStruct[meta.ModelStructName] = meta
+	}
+
+	tables := make([]*dictTable, 0, len(metas))
+	for _, meta := range metas {
+		t := &dictTable{Name: entityName(meta), Model: meta.ModelStructName}
+		fields := make(map[string]*model.Field, len(meta.Fields))
+		for _, f := range meta.Fields {
+			if !f.IsRelation() {
+				fields[f.ColumnName] = f
+			}
+		}
+
+		if meta.Columns != nil {
+			for _, col := range meta.Columns {
+				t.Columns = append(t.Columns, tableDictColumn(col, fields[col.Name()]))
+			}
+			if meta.Indexes == nil { // not read with columns, read once and keep for later runs
+				indexes, err := generate.GetTableIndexes(g.db, meta.SchemaName, meta.TableName)
+				if err != nil {
+					t.IndexErr = err.Error()
+				} else {
+					meta.Indexes = append([]gorm.Index{}, indexes...)
+				}
+			}
+			for _, idx := range meta.Indexes {
+				primary, _ := idx.PrimaryKey()
+				unique, _ := idx.Unique()
+				t.Indexes = append(t.Indexes, dictIndex{Name: idx.Name(), Columns: strings.Join(idx.Columns(), ", "), Unique: unique, Primary: primary})
+			}
+		} else {
+			for _, f := range meta.Fields {
+				if !f.IsRelation() && f.ColumnName != "" {
+					t.Columns = append(t.Columns, fieldDictColumn(f))
+				}
+			}
+			t.Indexes = tagDictIndexes(meta)
+		}
+		sort.Slice(t.Indexes, func(i, j int) bool {
+			if t.Indexes[i].Primary != t.Indexes[j].Primary {
+				return t.Indexes[i].Primary
+			}
+			return t.Indexes[i].Name < t.Indexes[j].Name
+		})
+
+		for _, f := range meta.Fields {
+			if !f.IsRelation() {
+				continue
+			}
+			tag := relationTag(f)
+			r := dictRelation{
+				Field:      f.Name,
+				Type:       string(f.Relation.Relationship()),
+				Target:     relationTarget(f.Relation.Type()),
+				ForeignKey: tag["FOREIGNKEY"],
+				References: tag["REFERENCES"],
+				JoinTable:  tag["MANY2MANY"],
+			}
+			if target := byStruct[r.Target]; target != nil {
+				r.Target = entityName(target)
+				if r.ForeignKey == "" {
+					r.ForeignKey = strings.Join(defaultForeignKeys(f, tag, meta, target), ",")
+				}
+			}
+			t.Relations = append(t.Relations, r)
+		}
+		tables = append(tables, t)
+	}
+	return tables
+}
+
+// tableDictColumn describe column read from table, f is nil if column is not generated into model
+func tableDictColumn(col *model.Column, f *model.Field) dictColumn {
+	c := dictColumn{Name: col.Name(), DBType: col.DatabaseTypeName(), GoType: "-", Nullable: "-"}
+	if colType, ok := col.ColumnType.ColumnType(); ok && colType != "" {
+		c.DBType = colType
+	}
+	if nullable, ok := col.Nullable(); ok {
+		c.Nullable = yesNo(nullable)
+	}
+	if value, ok := col.DefaultValue(); ok {
+		c.Default = value
+		if value == "" { // empty string, not missing default
+			c.Default = "''"
+		}
+	}
+	c.Comment, _ = col.Comment()
+	if pk, ok := col.PrimaryKey(); ok && pk {
+		c.Key = "PK"
+	} else if unique, ok := col.Unique(); ok && unique {
+		c.Key = "UK"
+	}
+	if f != nil {
+		c.GoType = f.Type
+	}
+	return c
+}
+
+// fieldDictColumn describe column of model field from gorm tag
+func fieldDictColumn(f *model.Field) dictColumn {
+	tag := schema.ParseTagSetting(f.GORMTag, ";")
+	c := dictColumn{Name: f.ColumnName, DBType: tag["TYPE"], GoType: f.Type, Nullable: "-", Default: tag["DEFAULT"], Comment: f.ColumnComment}
+	if _, ok := tag["NOT NULL"]; ok {
+		c.Nullable = yesNo(false)
+	}
+	if _, ok := tag["PRIMARYKEY"]; ok {
+		c.Key = "PK"
+	} else if _, ok = tag["UNIQUE"]; ok {
+		c.Key = "UK"
+	}
+	return c
+}
+
+// tagDictIndexes indexes declared by index and uniqueIndex tags of fields, named like gorm does
+func tagDictIndexes(meta *QueryStructMeta) []dictIndex {
+	byName := make(map[string]*dictIndex)
+	var names []string
+	for _, f := range meta.Fields {
+		if f.IsRelation() {
+			continue
+		}
+		tag := schema.ParseTagSetting(f.GORMTag, ";")
+		for _, key := range []string{"INDEX", "UNIQUEINDEX"} {
+			value, ok := tag[key]
+			if !ok {
+				continue
+			}
+			name := strings.TrimSpace(strings.Split(value, ",")[0])
+			if name == "" {
+				name = "idx_" + meta.TableName + "_" + f.ColumnName
+			}
+			idx := byName[name]
+			if idx == nil {
+				idx = &dictIndex{Name: name}
+				byName[name] = idx
+				names = append(names, name)
+			}
+			if idx.Columns != "" {
+				idx.Columns += ", "
+			}
+			idx.Columns += f.ColumnName
+			idx.Unique = idx.Unique || key == "UNIQUEINDEX" || strings.Contains(value, "unique")
+		}
+	}
+	indexes := make([]dictIndex, len(names))
+	for i, name := range names {
+		indexes[i] = *byName[name]
+	}
+	return indexes
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "YES"
+	}
+	return "NO"
+}
+
+// mdCell escape markdown table cell
+func mdCell(s string) string {
+	if s == "" {
+		return ""
+	}
+	return strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>").Replace(s)
+}
+
+var dictFuncs = map[string]interface{}{"cell": mdCell, "anchor": func(s string) string { return "table-" + strings.ReplaceAll(s, ".", "-") }}
+
+var dictMarkdownTmpl = template.Must(template.New("markdown").Funcs(dictFuncs).Parse(`# Data Dictionary
+
+Generated by gorm.io/gen, do not edit.
+
+| Table | Model |
+| --- | --- |
+{{range .}}| [{{.Name}}](#{{anchor .Name}}) | {{.Model}} |
+{{end}}{{range .}}
+<a id="{{anchor .Name}}"></a>
+
+## {{.Name}}
+
+Model: ` + "`{{.Model}}`" + `
+
+### Columns
+
+| Column | DB Type | Go Type | Nullable | Default | Key | Comment |
+| --- | --- | --- | --- | --- | --- | --- |
+{{range .Columns}}| {{cell .Name}} | {{cell .DBType}} | {{cell .GoType}} | {{.Nullable}} | {{cell .Default}} | {{.Key}} | {{cell .Comment}} |
+{{end}}
+### Indexes
+{{if .IndexErr}}
+Indexes are not available: {{.IndexErr}}
+{{else if .Indexes}}
+| Index | Columns | Unique | Primary |
+| --- | --- | --- | --- |
+{{range .Indexes}}| {{cell .Name}} | {{cell .Columns}} | {{if .Unique}}YES{{else}}NO{{end}} | {{if .Primary}}YES{{else}}NO{{end}} |
+{{end}}{{else}}
+None
+{{end}}
+### Relations
+{{if .Relations}}
+| Field | Type | Target | Foreign Key | References | Join Table |
+| --- | --- | --- | --- | --- | --- |
+{{range .Relations}}| {{.Field}} | {{.Type}} | {{cell .Target}} | {{cell .ForeignKey}} | {{cell .References}} | {{cell .JoinTable}} |
+{{end}}{{else}}
+None
+{{end}}{{end}}`))
+
+var dictHTMLTmpl = htmltemplate.Must(htmltemplate.New("html").Funcs(htmltemplate.FuncMap(dictFuncs)).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Data Dictionary</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #24292f; }
+table { border-collapse: collapse; margin: 0.5em 0 1.5em; }
+th, td { border: 1px solid #d0d7de; padding: 4px 10px; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; }
+td.comment { white-space: pre-wrap; }
+h2 { border-bottom: 1px solid #d0d7de; padding-bottom: 0.3em; margin-top: 2em; }
+code { background: #f6f8fa; padding: 1px 4px; }
+</style>
+</head>
+<body>
+<h1>Data Dictionary</h1>
+<p>Generated by gorm.io/gen, do not edit.</p>
+<table>
+<tr><th>Table</th><th>Model</th></tr>
+{{range .}}<tr><td><a href="#{{anchor .Name}}">{{.Name}}</a></td><td>{{.Model}}</td></tr>
+{{end}}</table>
+{{range .}}
+<h2 id="{{anchor .Name}}">{{.Name}}</h2>
+<p>Model: <code>{{.Model}}</code></p>
+<h3>Columns</h3>
+<table>
+<tr><th>Column</th><th>DB Type</th><th>Go Type</th><th>Nullable</th><th>Default</th><th>Key</th><th>Comment</th></tr>
+{{range .Columns}}<tr><td>{{.Name}}</td><td>{{.DBType}}</td><td>{{.GoType}}</td><td>{{.Nullable}}</td><td>{{.Default}}</td><td>{{.Key}}</td><td class="comment">{{.Comment}}</td></tr>
+{{end}}</table>
+<h3>Indexes</h3>
+{{if .IndexErr}}<p>Indexes are not available: {{.IndexErr}}</p>
+{{else if .Indexes}}<table>
+<tr><th>Index</th><th>Columns</th><th>Unique</th><th>Primary</th></tr>
+{{range .Indexes}}<tr><td>{{.Name}}</td><td>{{.Columns}}</td><td>{{if .Unique}}YES{{else}}NO{{end}}</td><td>{{if .Primary}}YES{{else}}NO{{end}}</td></tr>
+{{end}}</table>
+{{else}}<p>None</p>
+{{end}}<h3>Relations</h3>
+{{if .Relations}}<table>
+<tr><th>Field</th><th>Type</th><th>Target</th><th>Foreign Key</th><th>References</th><th>Join Table</th></tr>
+{{range .Relations}}<tr><td>{{.Field}}</td><td>{{.Type}}</td><td><a href="#{{anchor .Target}}">{{.Target}}</a></td><td>{{.ForeignKey}}</td><td>{{.References}}</td><td>{{.JoinTable}}</td></tr>
+{{end}}</table>
+{{else}}<p>None</p>
+{{end}}{{end}}</body>
+</html>
+`))
@@ -0,0 +1,108 @@
+package gen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testDictionaryDDL = testMySQLDDL + "CREATE TABLE `orders` (\n" +
+	"  `id` bigint unsigned NOT NULL AUTO_INCREMENT,\n" +
+	"  `user_id` bigint unsigned NOT NULL,\n" +
+	"  `note` text COMMENT 'note | with pipe',\n" +
+	"  PRIMARY KEY (`id`),\n" +
+	"  KEY `idx_user_id` (`user_id`),\n" +
+	"  CONSTRAINT `fk_orders_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)\n" +
+	");\n"
+
+func TestDataDictionary(t *testing.T) {
+	dir := newModuleDir(t)
+	if err := os.WriteFile(filepath.Join(dir, "schema.sql"), []byte(testDictionaryDDL), 0600); err != nil {
+		t.Fatal(err)
+	}
+	db, err := OpenDDL("mysql", filepath.Join(dir, "schema.sql"))
+	if err != nil {
+		t.Fatalf("open ddl fail: %s", err)
+	}
+
+	out := NewMemoryOutput()
+	outPath := filepath.Join(dir, "query")
+	g := NewGenerator(Config{OutPath: outPath, ModelPkgPath: "query", Mode: WithDefaultQuery})
+	g.WithOutput(out)
+	g.WithRelationInference(RelationInference{ForeignKeys: true})
+	g.Use(DataDictionary{})
+	g.UseDB(db)
+	g.ApplyBasic(g.GenerateAllTable()...)
+	if err = g.ExecuteE(); err != nil {
+		t.Fatalf("execute fail: %s", err)
+	}
+
+	expects := map[string][]string{
+		"data_dictionary.md": {
+			"| [orders](#table-orders) | Order |\n| [users](#table-users) | User |\n",
+			"| name | varchar(64) | string | NO | '' | UK | user name |",
+			"| age | int | int32 | YES |  |  |  |",
+			`| note | text | string | YES |  |  | note \| with pipe |`,
+			"| idx_user_id | user_id | NO | NO |",
+			"| PRIMARY | id | YES | YES |",
+			"| User | belongs_to | users | UserID | ID |  |",
+			"| Orders | has_many | orders | UserID | ID |  |",
+		},
+		"data_dictionary.html": {
+			`<h2 id="table-orders">orders</h2>`,
+			`<tr><td>name</td><td>varchar(64)</td><td>string</td><td>NO</td><td>&#39;&#39;</td><td>UK</td><td class="comment">user name</td></tr>`,
+			`<td><a href="#table-users">users</a></td>`,
+		},
+	}
+	for name, contains := range expects {
+		content, err := out.ReadFile(filepath.Join(outPath, name))
+		if err != nil {
+			t.Errorf("read %s fail: %s", name, err)
+			continue
+		}
+		for _, expect := range contains {
+			if !strings.Contains(string(content), expect) {
+				t.Errorf("expect %s contains %q, got:\n%s", name, expect, content)
+			}
+		}
+	}
+
+	if _, err = g.RenderDataDictionary(nil, "pdf"); err == nil {
+		t.Errorf("expect unknown format fail")
+	}
+}
+
+// indexes read with columns are reused, database is not queried again on execute
+func TestDataDictionary_ReuseIndexes(t *testing.T) {
+	dir := newModuleDir(t)
+	if err := os.WriteFile(filepath.Join(dir, "schema.sql"), []byte(testDictionaryDDL), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "empty.sql"), []byte("CREATE TABLE `others` (`id` bigint NOT NULL);\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	db, err := OpenDDL("mysql", filepath.Join(dir, "schema.sql"))
+	if err != nil {
+		t.Fatalf("open ddl fail: %s", err)
+	}
+
+	g := NewGenerator(Config{OutPath: filepath.Join(dir, "query"), ModelPkgPath: "query"})
+	g.Use(DataDictionary{})
+	g.UseDB(db)
+	order := g.GenerateModel("orders")
+	if order.Indexes == nil {
+		t.Fatalf("expect indexes read with columns")
+	}
+	if g.db, err = OpenDDL("mysql", filepath.Join(dir, "empty.sql")); err != nil {
+		t.Fatalf("open ddl fail: %s", err)
+	}
+
+	content, err := g.RenderDataDictionary([]*QueryStructMeta{order}, DataDictionaryMarkdown)
+	if err != nil {
+		t.Fatalf("render fail: %s", err)
+	}
+	if !strings.Contains(string(content), "| idx_user_id | user_id | NO | NO |") {
+		t.Errorf("expect index read with columns, got:\n%s", content)
+	}
+}
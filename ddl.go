@@ -0,0 +1,197 @@
+package gen
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/migrator"
+	"gorm.io/gorm/utils/tests"
+
+	"gorm.io/gen/internal/ddl"
+)
+
+// DDLDialector dialector reading tables from CREATE TABLE, CREATE INDEX, ALTER TABLE and COMMENT ON
+// statements of sql files instead of database, used to generate code without database
+type DDLDialector struct {
+	tests.DummyDialector
+
+	Dialect string   // mysql or postgres
+	Files   []string // sql files or glob patterns
+
+	schema *ddl.Schema
+}
+
+// NewDDLDialector create dialector of sql files in syntax of dialect mysql or postgres
+func NewDDLDialector(dialect string, files ...string) gorm.Dialector {
+	return &DDLDialector{Dialect: dialect, Files: files}
+}
+
+// OpenDDL open db whose tables are parsed from sql files, files may be glob patterns
+func OpenDDL(dialect string, files ...string) (*gorm.DB, error) {
+	return gorm.Open(NewDDLDialector(dialect, files...), &gorm.Config{DryRun: true, Logger: logger.Discard})
+}
+
+// Name return name of dialect, so generated code is same as of database
+func (d *DDLDialector) Name() string {
+	return d.Dialect
+}
+
+// Initialize parse sql files
+func (d *DDLDialector) Initialize(db *gorm.DB) error {
+	schema, err := ddl.NewSchema(d.Dialect)
+	if err != nil {
+		return err
+	}
+
+	var paths []string
+	for _, pattern := range d.Files {
+		if pattern = strings.TrimSpace(pattern); pattern == "" {
+			continue
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid sql file pattern %q: %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			return fmt.Errorf("no sql file matches %q", pattern)
+		}
+		paths = append(paths, matches...)
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("sql files cannot be empty")
+	}
+
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read sql file fail: %w", err)
+		}
+		if err = schema.Parse(string(content)); err != nil {
+			return fmt.Errorf("parse sql file %s fail: %w", path, err)
+		}
+	}
+	d.schema = schema
+	return d.DummyDialector.Initialize(db)
+}
+
+// Migrator return migrator reporting parsed tables
+func (d *DDLDialector) Migrator(db *gorm.DB) gorm.Migrator {
+	return ddlMigrator{Migrator: migrator.Migrator{Config: migrator.Config{DB: db, Dialector: d}}, schema: d.schema}
+}
+
+type ddlMigrator struct {
+	migrator.Migrator
+	schema *ddl.Schema
+}
+
+func (m ddlMigrator) CurrentDatabase() string { return "" }
+
+func (m ddlMigrator) GetTables() (tableList []string, err error) {
+	for _, t := range m.schema.Tables {
+		tableList = append(tableList, t.Name)
+	}
+	return tableList, nil
+}
+
+func (m ddlMigrator) HasTable(value interface{}) bool {
+	_, err := m.table(value)
+	return err == nil
+}
+
+func (m ddlMigrator) ColumnTypes(value interface{}) ([]gorm.ColumnType, error) {
+	table, err := m.table(value)
+	if err != nil {
+		return nil, err
+	}
+	columnTypes := make([]gorm.ColumnType, 0, len(table.Columns))
+	for _, c := range table.Columns {
+		ct := migrator.ColumnType{
+			NameValue:          nullString(c.Name),
+			DataTypeValue:      nullString(c.DataType),
+			ColumnTypeValue:    nullString(c.ColumnType),
+			PrimaryKeyValue:    nullBool(c.PrimaryKey),
+			UniqueValue:        nullBool(c.Unique),
+			AutoIncrementValue: nullBool(c.AutoIncrement),
+			NullableValue:      nullBool(c.Nullable),
+			CommentValue:       nullString(c.Comment),
+			ScanTypeValue:      ddlScanType(c),
+		}
+		ct.LengthValue.Int64, ct.LengthValue.Valid = c.Length, true
+		ct.DecimalSizeValue.Int64, ct.DecimalSizeValue.Valid = c.Precision, c.Precision > 0
+		ct.ScaleValue.Int64, ct.ScaleValue.Valid = c.Scale, c.Precision > 0
+		if c.Default != nil {
+			ct.DefaultValueValue = nullString(*c.Default)
+		}
+		columnTypes = append(columnTypes, ct)
+	}
+	return columnTypes, nil
+}
+
+func (m ddlMigrator) GetIndexes(value interface{}) ([]gorm.Index, error) {
+	table, err := m.table(value)
+	if err != nil {
+		return nil, err
+	}
+	indexes := make([]gorm.Index, 0, len(table.Indexes))
+	for _, idx := range table.Indexes {
+		indexes = append(indexes, migrator.Index{
+			TableName:       table.Name,
+			NameValue:       idx.Name,
+			ColumnList:      idx.Columns,
+			PrimaryKeyValue: nullBool(idx.Primary),
+			UniqueValue:     nullBool(idx.Unique),
+		})
+	}
+	return indexes, nil
+}
+
+// table return parsed table of value, which is table name or model
+func (m ddlMigrator) table(value interface{}) (table *ddl.Table, err error) {
+	err = m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		if table = m.schema.Table(stmt.Table); table == nil {
+			return fmt.Errorf("table %s not found in sql files", stmt.Table)
+		}
+		return nil
+	})
+	return table, err
+}
+
+// ddlScanType go type which database driver scans column into
+func ddlScanType(c *ddl.Column) reflect.Type {
+	switch c.DataType {
+	case "int2", "smallint", "year":
+		return reflect.TypeOf(int16(0))
+	case "int4", "int", "mediumint":
+		return reflect.TypeOf(int32(0))
+	case "int8", "bigint":
+		return reflect.TypeOf(int64(0))
+	case "tinyint":
+		if c.ColumnType == "tinyint(1)" {
+			return reflect.TypeOf(false)
+		}
+		return reflect.TypeOf(int8(0))
+	case "bool":
+		return reflect.TypeOf(false)
+	case "float4", "float":
+		return reflect.TypeOf(float32(0))
+	case "float8", "double", "numeric", "decimal":
+		return reflect.TypeOf(float64(0))
+	case "date", "datetime", "timestamp", "timestamptz", "time", "timetz":
+		return reflect.TypeOf(time.Time{})
+	case "bytea", "blob", "tinyblob", "mediumblob", "longblob", "binary", "varbinary":
+		return reflect.TypeOf([]byte(nil))
+	default:
+		return reflect.TypeOf("")
+	}
+}
+
+func nullString(s string) sql.NullString { return sql.NullString{String: s, Valid: true} }
+
+func nullBool(b bool) sql.NullBool { return sql.NullBool{Bool: b, Valid: true} }
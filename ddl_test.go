@@ -0,0 +1,107 @@
+package gen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testMySQLDDL = "CREATE TABLE `users` (\n" +
+	"  `id` bigint unsigned NOT NULL AUTO_INCREMENT,\n" +
+	"  `name` varchar(64) NOT NULL DEFAULT '' COMMENT 'user name',\n" +
+	"  `age` int DEFAULT NULL,\n" +
+	"  `is_admin` tinyint(1) NOT NULL DEFAULT 0,\n" +
+	"  `created_at` datetime(3) DEFAULT NULL,\n" +
+	"  PRIMARY KEY (`id`),\n" +
+	"  UNIQUE KEY `idx_name` (`name`)\n" +
+	") ENGINE=InnoDB COMMENT='users of shop';\n"
+
+const testPostgresDDL = `CREATE TABLE accounts (
+    id bigserial PRIMARY KEY,
+    email varchar(255) NOT NULL,
+    score double precision,
+    created_at timestamptz NOT NULL
+);
+CREATE UNIQUE INDEX idx_accounts_email ON accounts (email);
+`
+
+// generateDDL generate code of tables in sql, return content of model and query code
+func generateDDL(t *testing.T, dialect, sql string) (model, query string) {
+	dir := newModuleDir(t)
+	if err := os.WriteFile(filepath.Join(dir, "schema.sql"), []byte(sql), 0600); err != nil {
+		t.Fatal(err)
+	}
+	db, err := OpenDDL(dialect, filepath.Join(dir, "*.sql"))
+	if err != nil {
+		t.Fatalf("open ddl fail: %s", err)
+	}
+
+	out := NewMemoryOutput()
+	g := NewGenerator(Config{OutPath: filepath.Join(dir, "query"), ModelPkgPath: "query", Mode: WithDefaultQuery,
+		FieldNullable: true, FieldWithIndexTag: true, FieldWithTypeTag: true})
+	g.WithOutput(out)
+	g.UseDB(db)
+	models, err := g.GenerateAllTableE()
+	if err != nil {
+		t.Fatalf("generate models fail: %s", err)
+	}
+	g.ApplyBasic(models...)
+	if err = g.ExecuteE(); err != nil {
+		t.Fatalf("execute fail: %s", err)
+	}
+
+	for _, name := range out.Names() {
+		content, _ := out.ReadFile(name)
+		switch {
+		case strings.Contains(string(content), "mapped from table"):
+			model += string(content)
+		case strings.Contains(string(content), "func (") && strings.Contains(string(content), "Do) "):
+			query += string(content)
+		}
+	}
+	return model, query
+}
+
+func TestOpenDDL_MySQL(t *testing.T) {
+	model, query := generateDDL(t, DbMySQL, testMySQLDDL)
+
+	for _, expect := range []string{
+		"ID        int64      `gorm:\"column:id;type:bigint unsigned;primaryKey;autoIncrement:true\" json:\"id\"`",
+		"Name      string     `gorm:\"column:name;type:varchar(64);not null;uniqueIndex:idx_name,priority:1\" json:\"name\"` // user name",
+		"Age       *int32     `gorm:\"column:age;type:int\" json:\"age\"`",
+		"IsAdmin   bool       `gorm:\"column:is_admin;type:tinyint(1);not null;default:0\" json:\"is_admin\"`",
+		"CreatedAt *time.Time `gorm:\"column:created_at;type:datetime(3)\" json:\"created_at\"`",
+	} {
+		if !strings.Contains(model, expect) {
+			t.Errorf("expect model contains %s, got\n%s", expect, model)
+		}
+	}
+	if !strings.Contains(query, "func newUser(db *gorm.DB") {
+		t.Errorf("query code of users not generated")
+	}
+}
+
+func TestOpenDDL_Postgres(t *testing.T) {
+	model, _ := generateDDL(t, DbPostgres, testPostgresDDL)
+
+	for _, expect := range []string{
+		"ID        int64     `gorm:\"column:id;type:int8;primaryKey;autoIncrement:true\" json:\"id\"`",
+		"Email     string    `gorm:\"column:email;type:varchar(255);not null;uniqueIndex:idx_accounts_email,priority:1\" json:\"email\"`",
+		"Score     *float64  `gorm:\"column:score;type:float8\" json:\"score\"`",
+		"CreatedAt time.Time `gorm:\"column:created_at;type:timestamptz;not null\" json:\"created_at\"`",
+	} {
+		if !strings.Contains(model, expect) {
+			t.Errorf("expect model contains %s, got\n%s", expect, model)
+		}
+	}
+}
+
+func TestOpenDDL_Error(t *testing.T) {
+	if _, err := OpenDDL(DbMySQL, filepath.Join(t.TempDir(), "*.sql")); err == nil {
+		t.Errorf("expect error of no sql file")
+	}
+	if _, err := OpenDDL("oracle", "schema.sql"); err == nil {
+		t.Errorf("expect error of unsupported dialect")
+	}
+}
@@ -0,0 +1,140 @@
+package gen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/driver/sqlserver"
+	"gorm.io/gorm"
+
+	"gorm.io/gen/internal/ddl"
+	"gorm.io/gen/internal/model"
+)
+
+// Dialect database dialect registered by name
+type Dialect struct {
+	Name string
+	Open func(dsn string) gorm.Dialector
+
+	// SchemaName return schema name of tables, empty to fall back to current database
+	SchemaName func(db *gorm.DB) string
+	// DataTypeMap column database type -> go type, entries of Config.WithDataTypeMap take precedence
+	DataTypeMap map[string]func(detailType string) (dataType string)
+	// ForeignKeys return foreign keys of table in schema, empty schema for current database, used to infer relations
+	ForeignKeys func(db *gorm.DB, schema, table string) ([]ForeignKey, error)
+}
+
+// DialectOpt option of registered dialect
+type DialectOpt func(*Dialect)
+
+// WithDialectSchemaName specify schema name lookup of dialect
+func WithDialectSchemaName(fn func(db *gorm.DB) string) DialectOpt {
+	return func(d *Dialect) { d.SchemaName = fn }
+}
+
+// WithDialectDataTypeMap specify data type mapping of dialect
+func WithDialectDataTypeMap(m map[string]func(detailType string) (dataType string)) DialectOpt {
+	return func(d *Dialect) { d.DataTypeMap = m }
+}
+
+var dialects = struct {
+	sync.RWMutex
+	m map[string]*Dialect
+}{m: make(map[string]*Dialect)}
+
+func init() {
+	RegisterDialector(DbMySQL, mysql.Open, WithDialectForeignKeys(mysqlForeignKeys))
+	RegisterDialector(DbPostgres, postgres.Open, WithDialectForeignKeys(postgresForeignKeys))
+	RegisterDialector(DbSQLite, sqlite.Open, WithDialectForeignKeys(sqliteForeignKeys))
+	RegisterDialector(DbSQLServer, sqlserver.Open, WithDialectForeignKeys(sqlserverForeignKeys))
+	RegisterDialector(DbMySQLDDL, func(dsn string) gorm.Dialector { return NewDDLDialector(ddl.MySQL, strings.Split(dsn, ",")...) })
+	RegisterDialector(DbPostgresDDL, func(dsn string) gorm.Dialector { return NewDDLDialector(ddl.Postgres, strings.Split(dsn, ",")...) })
+}
+
+// RegisterDialector register dialect name used by database.yml db, gentool -db and Config.WithDialect,
+// a registered name is replaced
+func RegisterDialector(name string, open func(dsn string) gorm.Dialector, opts ...DialectOpt) {
+	d := &Dialect{Name: name, Open: open}
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	dialects.Lock()
+	defer dialects.Unlock()
+	dialects.m[name] = d
+}
+
+// LookupDialect return registered dialect, nil if not exists
+func LookupDialect(name string) *Dialect {
+	dialects.RLock()
+	defer dialects.RUnlock()
+	return dialects.m[name]
+}
+
+// Dialects return names of registered dialects in sorted order
+func Dialects() []string {
+	dialects.RLock()
+	defer dialects.RUnlock()
+	names := make([]string, 0, len(dialects.m))
+	for name := range dialects.m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// OpenDB connect database with registered dialect
+func OpenDB(name, dsn string, opts ...gorm.Option) (*gorm.DB, error) {
+	if dsn == "" {
+		return nil, fmt.Errorf("dsn cannot be empty")
+	}
+	d := LookupDialect(name)
+	if d == nil {
+		return nil, fmt.Errorf("unknow db %q (support %s for now)", name, strings.Join(Dialects(), " || "))
+	}
+	return gorm.Open(d.Open(dsn), opts...)
+}
+
+// WithDialect specify registered dialect whose hooks are used, default: dialect named as gorm dialector of db
+func (cfg *Config) WithDialect(name string) {
+	cfg.dialect = name
+}
+
+// getDialect return dialect of generator, nil if not registered
+func (g *Generator) getDialect() *Dialect {
+	name := g.dialect
+	if name == "" && g.db != nil && g.db.Dialector != nil {
+		name = g.db.Dialector.Name()
+	}
+	return LookupDialect(name)
+}
+
+// schemaNameOpts schema name options, dialect lookup is used after those of Config.WithDbNameOpts
+func (g *Generator) schemaNameOpts() []model.SchemaNameOpt {
+	d := g.getDialect()
+	if d == nil || d.SchemaName == nil {
+		return g.dbNameOpts
+	}
+	return append(append([]model.SchemaNameOpt(nil), g.dbNameOpts...), d.SchemaName)
+}
+
+// dataTypes data type mapping of dialect merged with Config.WithDataTypeMap
+func (g *Generator) dataTypes() map[string]func(detailType string) (dataType string) {
+	d := g.getDialect()
+	if d == nil || len(d.DataTypeMap) == 0 {
+		return g.dataTypeMap
+	}
+	m := make(map[string]func(detailType string) (dataType string), len(d.DataTypeMap)+len(g.dataTypeMap))
+	for k, v := range d.DataTypeMap {
+		m[k] = v
+	}
+	for k, v := range g.dataTypeMap {
+		m[k] = v
+	}
+	return m
+}
@@ -0,0 +1,61 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestRegisterDialector(t *testing.T) {
+	var schemaLookup bool
+	RegisterDialector("memsqlite", func(dsn string) gorm.Dialector {
+		return sqlite.Open("file:" + dsn + "?mode=memory&cache=shared")
+	},
+		WithDialectSchemaName(func(*gorm.DB) string { schemaLookup = true; return "" }),
+		WithDialectDataTypeMap(map[string]func(string) string{
+			"integer": func(string) string { return "int32" },
+			"text":    func(string) string { return "[]byte" },
+		}),
+	)
+	defer func() {
+		dialects.Lock()
+		delete(dialects.m, "memsqlite")
+		dialects.Unlock()
+	}()
+
+	if _, err := OpenDB("nosuchdb", "dsn"); err == nil || !strings.Contains(err.Error(), "memsqlite") {
+		t.Errorf("expect unknown db error listing registered dialects, got %v", err)
+	}
+
+	conf := &Conf{DB: "memsqlite", DSN: t.Name(), OutPath: newModuleDir(t)}
+	if err := conf.build(); err != nil {
+		t.Fatalf("build conf fail: %s", err)
+	}
+	conf.WithDataTypeMap(map[string]func(string) string{"text": func(string) string { return "string" }})
+	if err := conf.Connect(); err != nil {
+		t.Fatalf("connect fail: %s", err)
+	}
+	if err := conf.db.Exec("CREATE TABLE accounts (id integer primary key, name text)").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	meta, err := conf.GenerateModelE("accounts")
+	if err != nil {
+		t.Fatalf("generate model fail: %s", err)
+	}
+	types := make(map[string]string)
+	for _, f := range meta.Fields {
+		types[f.Name] = f.Type
+	}
+	if types["ID"] != "int32" {
+		t.Errorf("expect dialect type mapping int32, got %s", types["ID"])
+	}
+	if types["Name"] != "string" {
+		t.Errorf("expect config type mapping to take precedence, got %s", types["Name"])
+	}
+	if !schemaLookup {
+		t.Errorf("expect schema name lookup of dialect to be used")
+	}
+}
@@ -0,0 +1,454 @@
+package gen
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// dumpTables write statements recreating tables with their data into file readable by owner only.
+// Schema is read from the database itself where it keeps it (sqlite, mysql), other databases get it rebuilt
+// from columns, indexes and constraints. Indexes and foreign keys are created after data is restored.
+func dumpTables(db *gorm.DB, path string, tables []string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close() // nolint
+
+	tables, err = dumpOrder(db, tables)
+	if err != nil {
+		return err
+	}
+	d := &dumper{db: db, dialect: db.Dialector.Name(), w: bufio.NewWriter(f)}
+	if d.dialect == DbMySQL {
+		d.writeln("SET @OLD_FOREIGN_KEY_CHECKS=@@FOREIGN_KEY_CHECKS, FOREIGN_KEY_CHECKS=0;\n")
+	}
+	for _, table := range tables {
+		if err = d.table(table); err != nil {
+			return fmt.Errorf("table %s: %w", table, err)
+		}
+	}
+	if len(d.deferred) > 0 {
+		d.writeln("-- foreign keys")
+		for _, stmt := range d.deferred {
+			d.writeln(stmt)
+		}
+	}
+	if d.dialect == DbMySQL {
+		d.writeln("SET FOREIGN_KEY_CHECKS=@OLD_FOREIGN_KEY_CHECKS;")
+	}
+	if err = d.w.Flush(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// dumpOrder sort tables so referenced tables are restored before tables referencing them, tables in cycle keep their order
+func dumpOrder(db *gorm.DB, tables []string) ([]string, error) {
+	dialect := LookupDialect(db.Dialector.Name())
+	if dialect == nil || dialect.ForeignKeys == nil {
+		return tables, nil
+	}
+	inScope := make(map[string]bool, len(tables))
+	for _, table := range tables {
+		inScope[table] = true
+	}
+	refs := make(map[string][]string, len(tables))
+	for _, table := range tables {
+		fks, err := dialect.ForeignKeys(db, "", table)
+		if err != nil {
+			return nil, fmt.Errorf("read foreign keys of %s fail: %w", table, err)
+		}
+		for _, fk := range fks {
+			if fk.RefTable != table && inScope[fk.RefTable] {
+				refs[table] = append(refs[table], fk.RefTable)
+			}
+		}
+	}
+
+	ordered := make([]string, 0, len(tables))
+	state := make(map[string]int, len(tables)) // 1 visiting, 2 done
+	var visit func(table string)
+	visit = func(table string) {
+		if state[table] != 0 {
+			return
+		}
+		state[table] = 1
+		for _, ref := range refs[table] {
+			visit(ref)
+		}
+		state[table] = 2
+		ordered = append(ordered, table)
+	}
+	for _, table := range tables {
+		visit(table)
+	}
+	return ordered, nil
+}
+
+type dumper struct {
+	db       *gorm.DB
+	dialect  string
+	w        *bufio.Writer
+	deferred []string // foreign keys added after all tables are restored
+}
+
+func (d *dumper) writeln(s string) { d.w.WriteString(s + "\n") } // nolint
+
+func (d *dumper) quote(name string) string {
+	var b strings.Builder
+	d.db.Dialector.QuoteTo(&b, name)
+	return b.String()
+}
+
+// table write create statement, data and indexes of table
+func (d *dumper) table(table string) error {
+	columnTypes, err := d.db.Migrator().ColumnTypes(table)
+	if err != nil {
+		return err
+	}
+
+	var create, after []string
+	switch d.dialect {
+	case DbSQLite:
+		create, after, err = d.sqliteSchema(table)
+	case DbMySQL:
+		create, err = d.mysqlSchema(table)
+	default:
+		create, after, err = d.rebuildSchema(table, columnTypes)
+	}
+	if err != nil {
+		return err
+	}
+
+	d.writeln("-- table " + table)
+	for _, stmt := range create {
+		d.writeln(stmt)
+	}
+	if err = d.rows(table, columnTypes); err != nil {
+		return err
+	}
+	for _, stmt := range after {
+		d.writeln(stmt)
+	}
+	d.writeln("")
+	return nil
+}
+
+// sqliteSchema statements of table, its indexes and triggers as sqlite keeps them
+func (d *dumper) sqliteSchema(table string) (create, after []string, err error) {
+	rows, err := d.db.Raw("SELECT type, sql FROM sqlite_master WHERE tbl_name = ? AND sql IS NOT NULL ORDER BY type = 'table' DESC, name", table).Rows()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close() // nolint
+	for rows.Next() {
+		var typ, stmt string
+		if err = rows.Scan(&typ, &stmt); err != nil {
+			return nil, nil, err
+		}
+		if typ == "table" {
+			create = append(create, stmt+";")
+		} else {
+			after = append(after, stmt+";")
+		}
+	}
+	return create, after, rows.Err()
+}
+
+// mysqlSchema statement of table as mysql shows it, including indexes and foreign keys
+func (d *dumper) mysqlSchema(table string) ([]string, error) {
+	var name, stmt string
+	if err := d.db.Raw("SHOW CREATE TABLE "+d.quote(table)).Row().Scan(&name, &stmt); err != nil {
+		return nil, err
+	}
+	return []string{stmt + ";"}, nil
+}
+
+// rebuildSchema build statements of table from its columns, indexes and constraints
+func (d *dumper) rebuildSchema(table string, columnTypes []gorm.ColumnType) (create, after []string, err error) {
+	var defs, primaryKeys []string
+	for _, col := range columnTypes {
+		name := d.quote(col.Name())
+		def, sequence := d.columnDefinition(col)
+		defs = append(defs, name+" "+def)
+		if pk, ok := col.PrimaryKey(); ok && pk {
+			primaryKeys = append(primaryKeys, name)
+		}
+		if sequence {
+			// restored rows keep their ids, continue the sequence after them
+			after = append(after, fmt.Sprintf("SELECT setval(pg_get_serial_sequence('%s', '%s'), COALESCE(MAX(%s), 0) + 1, false) FROM %s;",
+				strings.ReplaceAll(d.quote(table), "'", "''"), strings.ReplaceAll(col.Name(), "'", "''"), name, d.quote(table)))
+		}
+	}
+	if len(primaryKeys) > 0 {
+		defs = append(defs, "PRIMARY KEY ("+strings.Join(primaryKeys, ", ")+")")
+	}
+	create = []string{fmt.Sprintf("CREATE TABLE %s (%s);", d.quote(table), strings.Join(defs, ", "))}
+
+	if d.dialect == DbPostgres {
+		indexes, constraints, err := d.postgresConstraints(table)
+		if err != nil {
+			return nil, nil, err
+		}
+		d.deferred = append(d.deferred, constraints...)
+		return create, append(indexes, after...), nil
+	}
+
+	indexes, err := d.db.Migrator().GetIndexes(table)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read indexes fail: %w", err)
+	}
+	for _, idx := range indexes {
+		if primary, _ := idx.PrimaryKey(); primary {
+			continue
+		}
+		columns := make([]string, len(idx.Columns()))
+		for i, column := range idx.Columns() {
+			columns[i] = d.quote(column)
+		}
+		stmt := "CREATE INDEX "
+		if unique, _ := idx.Unique(); unique {
+			stmt = "CREATE UNIQUE INDEX "
+		}
+		after = append(after, fmt.Sprintf("%s%s ON %s (%s);", stmt, d.quote(idx.Name()), d.quote(table), strings.Join(columns, ", ")))
+	}
+	if dialect := LookupDialect(d.dialect); dialect != nil && dialect.ForeignKeys != nil {
+		fks, err := dialect.ForeignKeys(d.db, "", table)
+		if err != nil {
+			return nil, nil, fmt.Errorf("read foreign keys fail: %w", err)
+		}
+		for _, fk := range fks {
+			stmt := fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s",
+				d.quote(table), d.quote(fk.Name), d.quoteList(fk.Columns), d.quote(fk.RefTable))
+			if len(fk.RefColumns) > 0 {
+				stmt += " (" + d.quoteList(fk.RefColumns) + ")"
+			}
+			d.deferred = append(d.deferred, stmt+";")
+		}
+	}
+	return create, after, nil
+}
+
+func (d *dumper) quoteList(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = d.quote(name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// columnDefinition return type, nullability and default of column, sequence reports postgres column
+// generating its values from a sequence, which is recreated with the table
+func (d *dumper) columnDefinition(col gorm.ColumnType) (def string, sequence bool) {
+	def = columnTypeOf(col)
+	value, hasDefault := col.DefaultValue()
+	autoIncrement, _ := col.AutoIncrement()
+	switch {
+	case d.dialect == DbPostgres && strings.HasPrefix(value, "nextval("):
+		def += " GENERATED BY DEFAULT AS IDENTITY"
+		hasDefault, sequence = false, true
+	case d.dialect == DbPostgres && autoIncrement:
+		def += " GENERATED BY DEFAULT AS IDENTITY"
+		sequence = true
+	case d.dialect == DbSQLServer && autoIncrement:
+		def += " IDENTITY(1,1)"
+	}
+	if nullable, ok := col.Nullable(); ok && !nullable {
+		def += " NOT NULL"
+	}
+	if hasDefault && value != "" {
+		def += " DEFAULT " + defaultLiteral(value)
+	}
+	return def, sequence
+}
+
+// postgresConstraints return index statements and unique, check, exclusion and foreign key constraints of table
+// as postgres defines them
+func (d *dumper) postgresConstraints(table string) (indexes, constraints []string, err error) {
+	rows, err := d.db.Raw(`SELECT indexdef FROM pg_indexes WHERE schemaname = CURRENT_SCHEMA() AND tablename = ?
+AND indexname NOT IN (SELECT conname FROM pg_constraint WHERE conrelid = ?::regclass) ORDER BY indexname`, table, d.quote(table)).Rows()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close() // nolint
+	for rows.Next() {
+		var stmt string
+		if err = rows.Scan(&stmt); err != nil {
+			return nil, nil, err
+		}
+		indexes = append(indexes, stmt+";")
+	}
+	if err = rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	rows, err = d.db.Raw(`SELECT conname, pg_get_constraintdef(oid) FROM pg_constraint
+WHERE conrelid = ?::regclass AND contype IN ('u', 'c', 'x', 'f') ORDER BY contype = 'f', conname`, d.quote(table)).Rows()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close() // nolint
+	for rows.Next() {
+		var name, def string
+		if err = rows.Scan(&name, &def); err != nil {
+			return nil, nil, err
+		}
+		constraints = append(constraints, fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s %s;", d.quote(table), d.quote(name), def))
+	}
+	return indexes, constraints, rows.Err()
+}
+
+// rows write INSERT statements of every row in table
+func (d *dumper) rows(table string, columnTypes []gorm.ColumnType) error {
+	rows, err := d.db.Table(table).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close() // nolint
+
+	columns, err := rows.ColumnTypes()
+	if err != nil {
+		return err
+	}
+	names := make([]string, len(columns))
+	binary := make([]bool, len(columns))
+	for i, col := range columns {
+		names[i] = d.quote(col.Name())
+		binary[i] = isBinaryType(col.DatabaseTypeName())
+	}
+	identity := d.dialect == DbSQLServer && hasAutoIncrement(columnTypes)
+	if identity {
+		d.writeln("SET IDENTITY_INSERT " + d.quote(table) + " ON;")
+	}
+
+	insert := fmt.Sprintf("INSERT INTO %s (%s) VALUES (", d.quote(table), strings.Join(names, ", "))
+	values := make([]interface{}, len(columns))
+	ptrs := make([]interface{}, len(columns))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	for rows.Next() {
+		if err = rows.Scan(ptrs...); err != nil {
+			return err
+		}
+		literals := make([]string, len(values))
+		for i, v := range values {
+			literals[i] = sqlLiteral(d.dialect, v, binary[i], columns[i].DatabaseTypeName())
+		}
+		d.writeln(insert + strings.Join(literals, ", ") + ");")
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	if identity {
+		d.writeln("SET IDENTITY_INSERT " + d.quote(table) + " OFF;")
+	}
+	return nil
+}
+
+func hasAutoIncrement(columnTypes []gorm.ColumnType) bool {
+	for _, col := range columnTypes {
+		if auto, ok := col.AutoIncrement(); ok && auto {
+			return true
+		}
+	}
+	return false
+}
+
+// isBinaryType report whether database type stores bytes rather than text
+func isBinaryType(dbType string) bool {
+	dbType = strings.ToUpper(dbType)
+	for _, typ := range []string{"BLOB", "BINARY", "BYTEA", "IMAGE"} {
+		if strings.Contains(dbType, typ) {
+			return true
+		}
+	}
+	return false
+}
+
+// sqlLiteral render value scanned from column as literal of dialect, binary reports column storing bytes
+func sqlLiteral(dialect string, v interface{}, binary bool, dbType string) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		switch {
+		case dialect == DbSQLite || dialect == DbSQLServer:
+			return map[bool]string{true: "1", false: "0"}[v]
+		case v:
+			return "TRUE"
+		}
+		return "FALSE"
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return stringLiteral(dialect, strconv.FormatFloat(v, 'g', -1, 64))
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case time.Time:
+		return stringLiteral(dialect, timeLiteral(dialect, v, dbType))
+	case []byte:
+		if binary {
+			return bytesLiteral(dialect, v)
+		}
+		return stringLiteral(dialect, string(v))
+	case string:
+		if binary {
+			return bytesLiteral(dialect, []byte(v))
+		}
+		return stringLiteral(dialect, v)
+	}
+	return stringLiteral(dialect, fmt.Sprint(v))
+}
+
+// stringLiteral quote s, escaping it the way dialect reads it whatever its string options are
+func stringLiteral(dialect, s string) string {
+	switch dialect {
+	case DbMySQL:
+		if strings.ContainsAny(s, "\\\x00") { // backslash escapes depend on sql_mode, hex does not
+			return "X'" + hex.EncodeToString([]byte(s)) + "'"
+		}
+	case DbPostgres:
+		if strings.Contains(s, "\\") { // escape string is read the same whatever standard_conforming_strings is
+			return "E'" + strings.NewReplacer("\\", "\\\\", "'", "''").Replace(s) + "'"
+		}
+	case DbSQLServer:
+		return "N'" + strings.ReplaceAll(s, "'", "''") + "'"
+	}
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func bytesLiteral(dialect string, b []byte) string {
+	switch dialect {
+	case DbPostgres:
+		return "decode('" + hex.EncodeToString(b) + "', 'hex')"
+	case DbSQLServer:
+		return "0x" + hex.EncodeToString(b)
+	}
+	return "X'" + hex.EncodeToString(b) + "'"
+}
+
+// timeLiteral format t as dialect parses it into column of dbType, keeping the offset where column can store it
+func timeLiteral(dialect string, t time.Time, dbType string) string {
+	switch dbType = strings.ToUpper(dbType); {
+	case dialect == DbMySQL:
+		return t.Format("2006-01-02 15:04:05.999999")
+	case dialect == DbSQLServer && strings.Contains(dbType, "OFFSET"):
+		return t.Format("2006-01-02 15:04:05.9999999 -07:00")
+	case dialect == DbSQLServer && (dbType == "DATETIME" || dbType == "SMALLDATETIME"):
+		return t.Format("2006-01-02T15:04:05.999")
+	case dialect == DbSQLServer:
+		return t.Format("2006-01-02T15:04:05.9999999")
+	}
+	return t.Format("2006-01-02 15:04:05.999999999-07:00")
+}
@@ -0,0 +1,430 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm/schema"
+
+	"gorm.io/gen/field"
+	"gorm.io/gen/internal/model"
+)
+
+// ERDiagramFormat format of entity relationship diagram
+type ERDiagramFormat string
+
+const (
+	// ERDiagramMermaid mermaid erDiagram, written into <name>.mmd
+	ERDiagramMermaid ERDiagramFormat = "mermaid"
+	// ERDiagramPlantUML plantuml IE diagram, written into <name>.puml
+	ERDiagramPlantUML ERDiagramFormat = "plantuml"
+	// ERDiagramDOT graphviz dot graph, written into <name>.dot
+	ERDiagramDOT ERDiagramFormat = "dot"
+)
+
+var erDiagramExts = map[ERDiagramFormat]string{ERDiagramMermaid: ".mmd", ERDiagramPlantUML: ".puml", ERDiagramDOT: ".dot"}
+
+// ERDiagram plugin writing entity relationship diagram of generated models into OutPath on every Execute,
+// entities are tables with their columns and keys, relations are read from relation fields of models
+type ERDiagram struct {
+	Formats  []ERDiagramFormat // default: all formats
+	FileName string            // file name without extension, default: schema
+}
+
+// Name plugin name
+func (ERDiagram) Name() string { return "er_diagram" }
+
+// AfterExecute write diagrams of all models
+func (d ERDiagram) AfterExecute(g *Generator, metas []*QueryStructMeta) error {
+	formats, name := d.Formats, d.FileName
+	if len(formats) == 0 {
+		formats = []ERDiagramFormat{ERDiagramMermaid, ERDiagramPlantUML, ERDiagramDOT}
+	}
+	if name == "" {
+		name = "schema"
+	}
+	for _, format := range formats {
+		content, err := RenderERDiagram(metas, format)
+		if err != nil {
+			return err
+		}
+		if err = g.Emit(name+erDiagramExts[format], content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// erDiagramOf return ERDiagram plugin of format names
+func erDiagramOf(formats []string) ERDiagram {
+	d := ERDiagram{Formats: make([]ERDiagramFormat, len(formats))}
+	for i, format := range formats {
+		d.Formats[i] = ERDiagramFormat(strings.TrimSpace(format))
+	}
+	return d
+}
+
+// RenderERDiagram render entity relationship diagram of models in format
+func RenderERDiagram(metas []*QueryStructMeta, format ERDiagramFormat) ([]byte, error) {
+	d := newERModel(metas)
+	var buf bytes.Buffer
+	switch format {
+	case ERDiagramMermaid:
+		d.mermaid(&buf)
+	case ERDiagramPlantUML:
+		d.plantUML(&buf)
+	case ERDiagramDOT:
+		d.dot(&buf)
+	default:
+		return nil, fmt.Errorf("unknown er diagram format %q (support %s || %s || %s)", format, ERDiagramMermaid, ERDiagramPlantUML, ERDiagramDOT)
+	}
+	return buf.Bytes(), nil
+}
+
+type erEntity struct {
+	name    string
+	columns []erColumn
+}
+
+type erColumn struct {
+	name, typ, comment string
+	pk, fk, uk         bool
+}
+
+// erRelation relation between parent entity and child entity holding foreign key
+type erRelation struct {
+	parent, child string
+	cardinality   string // one_to_one, one_to_many or many_to_many
+	label         string
+}
+
+type erModel struct {
+	entities  []*erEntity
+	relations []*erRelation
+}
+
+// newERModel build entities and relations of metas, relation fields of both ends of a foreign key are merged
+func newERModel(metas []*QueryStructMeta) *erModel {
+	d := &erModel{}
+	byStruct := make(map[string]*QueryStructMeta, len(metas))
+	for _, meta := range metas {
+		byStruct[meta.ModelStructName] = meta
+	}
+
+	fkColumns := make(map[string]map[string]bool) // entity -> foreign key columns
+	markFK := func(meta *QueryStructMeta, fieldNames []string) {
+		name := entityName(meta)
+		if fkColumns[name] == nil {
+			fkColumns[name] = make(map[string]bool)
+		}
+		for _, fieldName := range fieldNames {
+			for _, f := range meta.Fields {
+				if f.Name == fieldName && !f.IsRelation() {
+					fkColumns[name][f.ColumnName] = true
+				}
+			}
+		}
+	}
+
+	relations := make(map[string]*erRelation)
+	var keys []string
+	addRelation := func(key string, r *erRelation, owner bool) {
+		if exist, ok := relations[key]; ok {
+			if owner { // cardinality and label of has one / has many field win over belongs to
+				exist.cardinality, exist.label = r.cardinality, r.label
+			}
+			return
+		}
+		relations[key] = r
+		keys = append(keys, key)
+	}
+
+	for _, meta := range metas {
+		for _, f := range meta.Fields {
+			if !f.IsRelation() {
+				continue
+			}
+			target := byStruct[relationTarget(f.Relation.Type())]
+			if target == nil {
+				continue
+			}
+			tag := relationTag(f)
+			foreignKeys := splitTagList(tag["FOREIGNKEY"])
+			if len(foreignKeys) == 0 {
+				foreignKeys = defaultForeignKeys(f, tag, meta, target)
+			}
+			self, other := entityName(meta), entityName(target)
+			switch f.Relation.Relationship() {
+			case field.BelongsTo:
+				markFK(meta, foreignKeys)
+				addRelation(relationKey(other, self, foreignKeys, f.Name), &erRelation{parent: other, child: self, cardinality: "one_to_many", label: f.Name}, false)
+			case field.HasOne, field.HasMany:
+				markFK(target, foreignKeys)
+				cardinality := "one_to_many"
+				if f.Relation.Relationship() == field.HasOne {
+					cardinality = "one_to_one"
+				}
+				addRelation(relationKey(self, other, foreignKeys, f.Name), &erRelation{parent: self, child: other, cardinality: cardinality, label: f.Name}, true)
+			case field.Many2Many:
+				pair := []string{self, other}
+				sort.Strings(pair)
+				key := "m2m:" + pair[0] + ":" + pair[1] + ":" + tag["MANY2MANY"]
+				if tag["MANY2MANY"] == "" {
+					key += f.Name
+				}
+				addRelation(key, &erRelation{parent: pair[0], child: pair[1], cardinality: "many_to_many", label: tag["MANY2MANY"]}, false)
+			}
+		}
+	}
+	for _, key := range keys {
+		d.relations = append(d.relations, relations[key])
+	}
+
+	for _, meta := range metas {
+		e := &erEntity{name: entityName(meta)}
+		uniques := make(map[*model.Field]bool, len(meta.Uniques))
+		for _, f := range meta.Uniques {
+			uniques[f] = true
+		}
+		for _, f := range meta.Fields {
+			if f.IsRelation() || f.ColumnName == "" {
+				continue
+			}
+			tag := schema.ParseTagSetting(f.GORMTag, ";")
+			_, pk := tag["PRIMARYKEY"]
+			_, unique := tag["UNIQUE"]
+			_, uniqueIndex := tag["UNIQUEINDEX"]
+			e.columns = append(e.columns, erColumn{
+				name:    f.ColumnName,
+				typ:     columnDiagramType(f, tag),
+				comment: f.ColumnComment,
+				pk:      pk,
+				fk:      fkColumns[e.name][f.ColumnName],
+				uk:      !pk && (unique || uniqueIndex || uniques[f]),
+			})
+		}
+		d.entities = append(d.entities, e)
+	}
+	return d
+}
+
+// crowFoot crow's foot notation of cardinality used by mermaid and plantuml
+var crowFoot = map[string]string{
+	"one_to_one":   "||--o|",
+	"one_to_many":  "||--o{",
+	"many_to_many": "}o--o{",
+}
+
+func (d *erModel) mermaid(buf *bytes.Buffer) {
+	buf.WriteString("erDiagram\n")
+	for _, e := range d.entities {
+		fmt.Fprintf(buf, "    %s {\n", mermaidName(e.name))
+		for _, c := range e.columns {
+			fmt.Fprintf(buf, "        %s %s", mermaidName(c.typ), mermaidName(c.name))
+			if keys := c.keys(); len(keys) > 0 {
+				buf.WriteString(" " + strings.Join(keys, ","))
+			}
+			if c.comment != "" {
+				buf.WriteString(` "` + strings.NewReplacer("\n", " ", `"`, "'").Replace(c.comment) + `"`)
+			}
+			buf.WriteString("\n")
+		}
+		buf.WriteString("    }\n")
+	}
+	for _, r := range d.relations {
+		fmt.Fprintf(buf, "    %s %s %s : %q\n", mermaidName(r.parent), crowFoot[r.cardinality], mermaidName(r.child), r.label)
+	}
+}
+
+func (d *erModel) plantUML(buf *bytes.Buffer) {
+	buf.WriteString("@startuml\nhide circle\nskinparam linetype ortho\n")
+	for _, e := range d.entities {
+		fmt.Fprintf(buf, "\nentity %q as %s {\n", e.name, plantUMLName(e.name))
+		for _, pkSection := range []bool{true, false} {
+			for _, c := range e.columns {
+				if c.pk != pkSection {
+					continue
+				}
+				prefix := "  "
+				if c.pk {
+					prefix = "  * "
+				}
+				fmt.Fprintf(buf, "%s%s : %s", prefix, c.name, c.typ)
+				for _, key := range c.keys() {
+					buf.WriteString(" <<" + key + ">>")
+				}
+				if c.comment != "" {
+					buf.WriteString(" -- " + strings.ReplaceAll(c.comment, "\n", " "))
+				}
+				buf.WriteString("\n")
+			}
+			if pkSection && e.hasPrimaryKey() {
+				buf.WriteString("  --\n")
+			}
+		}
+		buf.WriteString("}\n")
+	}
+	if len(d.relations) > 0 {
+		buf.WriteString("\n")
+	}
+	for _, r := range d.relations {
+		fmt.Fprintf(buf, "%s %s %s : %s\n", plantUMLName(r.parent), crowFoot[r.cardinality], plantUMLName(r.child), r.label)
+	}
+	buf.WriteString("@enduml\n")
+}
+
+func (d *erModel) dot(buf *bytes.Buffer) {
+	buf.WriteString("digraph er {\n  graph [rankdir=LR];\n  node [shape=plaintext, fontname=\"Helvetica\"];\n  edge [fontname=\"Helvetica\", fontsize=10];\n")
+	for _, e := range d.entities {
+		fmt.Fprintf(buf, "\n  %q [label=<\n    <table border=\"0\" cellborder=\"1\" cellspacing=\"0\">\n", e.name)
+		fmt.Fprintf(buf, "      <tr><td bgcolor=\"lightgrey\"><b>%s</b></td></tr>\n", html.EscapeString(e.name))
+		for _, c := range e.columns {
+			text := html.EscapeString(c.name + ": " + c.typ)
+			if c.pk {
+				text = "<u>" + text + "</u>"
+			}
+			if keys := c.keys(); len(keys) > 0 {
+				text += " " + strings.Join(keys, ",")
+			}
+			fmt.Fprintf(buf, "      <tr><td port=%q align=\"left\">%s</td></tr>\n", c.name, text)
+		}
+		buf.WriteString("    </table>>];\n")
+	}
+	if len(d.relations) > 0 {
+		buf.WriteString("\n")
+	}
+	for _, r := range d.relations {
+		fmt.Fprintf(buf, "  %q -> %q [label=%q, dir=both, %s];\n", r.child, r.parent, r.label, map[string]string{
+			"one_to_one":   "arrowtail=teeodot, arrowhead=tee",
+			"one_to_many":  "arrowtail=crowodot, arrowhead=tee",
+			"many_to_many": "arrowtail=crowodot, arrowhead=crowodot",
+		}[r.cardinality])
+	}
+	buf.WriteString("}\n")
+}
+
+func (e *erEntity) hasPrimaryKey() bool {
+	for _, c := range e.columns {
+		if c.pk {
+			return true
+		}
+	}
+	return false
+}
+
+// keys return PK, FK and UK markers of column
+func (c erColumn) keys() (keys []string) {
+	if c.pk {
+		keys = append(keys, "PK")
+	}
+	if c.fk {
+		keys = append(keys, "FK")
+	}
+	if c.uk {
+		keys = append(keys, "UK")
+	}
+	return keys
+}
+
+func entityName(meta *QueryStructMeta) string {
+	if meta.TableName != "" {
+		return meta.TableName
+	}
+	return meta.ModelStructName
+}
+
+// relationTarget return model struct name of relation field type, e.g. []*model.User -> User
+func relationTarget(typ string) string {
+	typ = strings.TrimLeft(typ, "[]*")
+	if i := strings.LastIndex(typ, "."); i >= 0 {
+		typ = typ[i+1:]
+	}
+	return typ
+}
+
+// relationTag return gorm tag settings of relation field
+func relationTag(f *model.Field) map[string]string {
+	tag := f.GORMTag
+	if f.OverwriteTag != "" {
+		if match := gormTagRegexp.FindStringSubmatch(f.OverwriteTag); match != nil {
+			tag = match[1]
+		}
+	}
+	return schema.ParseTagSetting(tag, ";")
+}
+
+var gormTagRegexp = regexp.MustCompile(`gorm:"([^"]*)"`)
+
+// defaultForeignKeys foreign key gorm uses when relation has no foreignKey tag:
+// <Field><Primary> of belongs to, <Owner><Primary> of has one and has many, nil if model has no such field
+func defaultForeignKeys(f *model.Field, tag map[string]string, owner, target *QueryStructMeta) []string {
+	var holder *QueryStructMeta
+	var key string
+	switch f.Relation.Relationship() {
+	case field.BelongsTo:
+		holder, key = owner, f.Name+referenceField(tag, target)
+	case field.HasOne, field.HasMany:
+		holder, key = target, owner.ModelStructName+referenceField(tag, owner)
+	default:
+		return nil
+	}
+	for _, hf := range holder.Fields {
+		if hf.Name == key && !hf.IsRelation() {
+			return []string{key}
+		}
+	}
+	return nil
+}
+
+// referenceField field referenced by foreign key: references tag, or primary key field of meta
+func referenceField(tag map[string]string, meta *QueryStructMeta) string {
+	if ref := tag["REFERENCES"]; ref != "" {
+		return ref
+	}
+	for _, f := range meta.Fields {
+		if f.IsRelation() {
+			continue
+		}
+		if _, pk := schema.ParseTagSetting(f.GORMTag, ";")["PRIMARYKEY"]; pk {
+			return f.Name
+		}
+	}
+	return "ID"
+}
+
+func relationKey(parent, child string, foreignKeys []string, fieldName string) string {
+	if len(foreignKeys) == 0 {
+		return parent + ":" + child + ":field:" + fieldName
+	}
+	return parent + ":" + child + ":" + strings.Join(foreignKeys, ",")
+}
+
+func splitTagList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+// columnDiagramType return database type of gorm tag, or go type without package and pointer
+func columnDiagramType(f *model.Field, tag map[string]string) string {
+	if typ := tag["TYPE"]; typ != "" {
+		return typ
+	}
+	return relationTarget(f.Type)
+}
+
+var invalidMermaidChar = regexp.MustCompile(`[^A-Za-z0-9_\-\[\]()]`)
+
+// mermaidName replace characters not allowed in mermaid entity names and attribute types
+func mermaidName(name string) string {
+	return invalidMermaidChar.ReplaceAllString(name, "_")
+}
+
+func plantUMLName(name string) string {
+	return strings.NewReplacer(".", "_", "-", "_", " ", "_").Replace(name)
+}
@@ -0,0 +1,115 @@
+package gen
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"gorm.io/gen/field"
+)
+
+func TestERDiagram(t *testing.T) {
+	dir := newModuleDir(t)
+	db, err := gorm.Open(sqlite.Open(filepath.Join(dir, "er.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite fail: %s", err)
+	}
+	for _, stmt := range strings.Split(strings.TrimSpace(testRelationSchema), ";\n") {
+		if err = db.Exec(stmt).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out := NewMemoryOutput()
+	outPath := filepath.Join(dir, "query")
+	g := NewGenerator(Config{OutPath: outPath, ModelPkgPath: "query", Mode: WithDefaultQuery})
+	g.WithOutput(out)
+	g.WithRelationInference(RelationInference{ForeignKeys: true, NamingConvention: true, ManyToMany: true})
+	g.Use(ERDiagram{})
+	g.UseDB(db)
+	g.ApplyBasic(g.GenerateAllTable()...)
+	if err = g.ExecuteE(); err != nil {
+		t.Fatalf("execute fail: %s", err)
+	}
+
+	expects := map[string][]string{
+		"schema.mmd": {
+			"erDiagram\n",
+			"    orders {\n        int32 id PK\n        int32 user_id FK\n        int32 reviewer_id FK\n    }\n",
+			`    users ||--o{ orders : "UserOrders"`,
+			`    users ||--o{ orders : "ReviewerOrders"`,
+			`    users ||--o| profiles : "Profile"`,
+			`    orders ||--o{ comments : "Comments"`,
+			`    roles }o--o{ users : "user_roles"`,
+		},
+		"schema.puml": {
+			"entity \"orders\" as orders {\n  * id : int32 <<PK>>\n  --\n  user_id : int32 <<FK>>\n",
+			"users ||--o{ orders : UserOrders\n",
+			"@enduml\n",
+		},
+		"schema.dot": {
+			"digraph er {\n",
+			`<tr><td port="user_id" align="left">user_id: int32 FK</td></tr>`,
+			`"orders" -> "users" [label="UserOrders", dir=both, arrowtail=crowodot, arrowhead=tee];`,
+		},
+	}
+	for name, contains := range expects {
+		content, err := out.ReadFile(filepath.Join(outPath, name))
+		if err != nil {
+			t.Errorf("read %s fail: %s", name, err)
+			continue
+		}
+		for _, expect := range contains {
+			if !strings.Contains(string(content), expect) {
+				t.Errorf("expect %s contains %q, got:\n%s", name, expect, content)
+			}
+		}
+		// relation fields of both ends are merged into one relation
+		if name == "schema.mmd" && strings.Count(string(content), "users ||--o{ orders") != 2 {
+			t.Errorf("expect 2 relations between users and orders, got:\n%s", content)
+		}
+	}
+
+	if _, err = RenderERDiagram(nil, "svg"); err == nil {
+		t.Errorf("expect unknown format fail")
+	}
+}
+
+// relation fields without foreignKey tag are merged by the foreign key gorm uses by default
+func TestERDiagram_DefaultForeignKey(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "er.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite fail: %s", err)
+	}
+	for _, stmt := range []string{
+		"CREATE TABLE users (id integer primary key, name text)",
+		"CREATE TABLE posts (id integer primary key, user_id integer, title text)",
+	} {
+		if err = db.Exec(stmt).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	newGenerator := func() *Generator {
+		g := NewGenerator(Config{OutPath: filepath.Join(newModuleDir(t), "query"), ModelPkgPath: "query"})
+		g.UseDB(db)
+		return g
+	}
+	g := newGenerator()
+	post := g.GenerateModel("posts", FieldRelate(field.BelongsTo, "User", newGenerator().GenerateModel("users"), &field.RelateConfig{RelatePointer: true}))
+	user := g.GenerateModel("users", FieldRelate(field.HasMany, "Posts", post, &field.RelateConfig{RelateSlicePointer: true}))
+
+	content, err := RenderERDiagram([]*QueryStructMeta{post, user}, ERDiagramMermaid)
+	if err != nil {
+		t.Fatalf("render fail: %s", err)
+	}
+	if n := strings.Count(string(content), "users ||--o{ posts"); n != 1 {
+		t.Errorf("expect both ends merged into 1 relation, got %d:\n%s", n, content)
+	}
+	if !strings.Contains(string(content), `users ||--o{ posts : "Posts"`) || !strings.Contains(string(content), "int32 user_id FK") {
+		t.Errorf("expect has many label and user_id marked FK, got:\n%s", content)
+	}
+}
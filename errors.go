@@ -1,8 +1,124 @@
 package gen
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
 
 var (
 	// ErrEmptyCondition empty condition
 	ErrEmptyCondition = errors.New("empty condition")
 )
+
+// GenerateError error occurred while generating code for a table
+type GenerateError struct {
+	Table string // table name or model struct name, empty for package level files
+	File  string // target file, empty if the error is not bound to a file
+	Err   error
+}
+
+// Error implement error interface
+func (e *GenerateError) Error() string {
+	var b strings.Builder
+	if e.Table != "" {
+		b.WriteString(fmt.Sprintf("table <%s>: ", e.Table))
+	}
+	if e.File != "" {
+		b.WriteString(fmt.Sprintf("file %s: ", e.File))
+	}
+	b.WriteString(e.Err.Error())
+	return b.String()
+}
+
+// Unwrap return the underlying error
+func (e *GenerateError) Unwrap() error { return e.Err }
+
+// TemplateError error occurred while rendering a template
+type TemplateError struct {
+	Template string // template name
+	Err      error
+}
+
+// Error implement error interface
+func (e *TemplateError) Error() string {
+	return fmt.Sprintf("render template %s fail: %s", e.Template, e.Err)
+}
+
+// Unwrap return the underlying error
+func (e *TemplateError) Unwrap() error { return e.Err }
+
+// GenerateErrors aggregated errors of one generation run
+type GenerateErrors []*GenerateError
+
+// Error implement error interface
+func (es GenerateErrors) Error() string {
+	if len(es) == 1 {
+		return es[0].Error()
+	}
+	msgs := make([]string, len(es))
+	for i, e := range es {
+		msgs[i] = e.Error()
+	}
+	return fmt.Sprintf("%d errors occurred:\n\t%s", len(es), strings.Join(msgs, "\n\t"))
+}
+
+// Tables return tables which failed to generate
+func (es GenerateErrors) Tables() (tables []string) {
+	exists := make(map[string]bool, len(es))
+	for _, e := range es {
+		if e.Table == "" || exists[e.Table] {
+			continue
+		}
+		exists[e.Table] = true
+		tables = append(tables, e.Table)
+	}
+	return tables
+}
+
+// errCollector collect errors from concurrent generating goroutines
+type errCollector struct {
+	mu   sync.Mutex
+	errs GenerateErrors
+}
+
+// Add add an error, nil error is ignored
+func (c *errCollector) Add(table, file string, err error) {
+	if err == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var es GenerateErrors
+	if errors.As(err, &es) { // flatten aggregated errors
+		c.errs = append(c.errs, es...)
+		return
+	}
+	var ge *GenerateError
+	if errors.As(err, &ge) {
+		c.errs = append(c.errs, ge)
+		return
+	}
+	c.errs = append(c.errs, &GenerateError{Table: table, File: file, Err: err})
+}
+
+// Err return aggregated error, nil if no error collected
+func (c *errCollector) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.errs) == 0 {
+		return nil
+	}
+	errs := append(GenerateErrors(nil), c.errs...)
+	sort.SliceStable(errs, func(i, j int) bool { // goroutines finish in random order
+		if errs[i].Table != errs[j].Table {
+			return errs[i].Table < errs[j].Table
+		}
+		return errs[i].File < errs[j].File
+	})
+	return errs
+}
@@ -0,0 +1,53 @@
+package gen
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGenerateModelE(t *testing.T) {
+	g := NewGenerator(Config{OutPath: t.TempDir()})
+
+	meta, err := g.GenerateModelE("users")
+	if err == nil {
+		t.Fatalf("generate model without db should fail, got %+v", meta)
+	}
+
+	var genErr *GenerateError
+	if !errors.As(err, &genErr) {
+		t.Fatalf("expect *GenerateError, got %T: %s", err, err)
+	}
+	if genErr.Table != "users" {
+		t.Errorf("expect error attributed to table users, got %q", genErr.Table)
+	}
+}
+
+func TestErrCollector(t *testing.T) {
+	var errs errCollector
+	if errs.Err() != nil {
+		t.Fatalf("empty collector should return nil")
+	}
+
+	errs.Add("users", "users.gen.go", errors.New("render fail"))
+	errs.Add("", "", nil)
+	errs.Add("", "", GenerateErrors{
+		{Table: "orders", Err: errors.New("format fail")},
+		{Table: "users", File: "users.model.gen.go", Err: errors.New("format fail")},
+	})
+
+	err := errs.Err()
+	var es GenerateErrors
+	if !errors.As(err, &es) {
+		t.Fatalf("expect GenerateErrors, got %T", err)
+	}
+	if len(es) != 3 {
+		t.Fatalf("expect 3 errors after flatten, got %d: %s", len(es), err)
+	}
+	if got := strings.Join(es.Tables(), ","); got != "orders,users" {
+		t.Errorf("expect tables orders,users, got %s", got)
+	}
+	if !strings.Contains(err.Error(), "table <orders>: format fail") {
+		t.Errorf("unexpected error message: %s", err)
+	}
+}
@@ -0,0 +1,137 @@
+package gen
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"sync"
+	"time"
+)
+
+// EventKind kind of progress event
+type EventKind string
+
+const (
+	// EventStart generation started
+	EventStart EventKind = "start"
+	// EventDone generation finished successfully
+	EventDone EventKind = "done"
+	// EventTableIntrospected columns of table or object are parsed into model
+	EventTableIntrospected EventKind = "table_introspected"
+	// EventFileGenerated file is generated, or compared in check mode
+	EventFileGenerated EventKind = "file_generated"
+	// EventFileSkipped file is kept as it is by incremental generation
+	EventFileSkipped EventKind = "file_skipped"
+	// EventFileRemoved stale file is removed
+	EventFileRemoved EventKind = "file_removed"
+	// EventInfo other progress message
+	EventInfo EventKind = "info"
+	// EventWarning something is ignored or may be wrong, generation continues
+	EventWarning EventKind = "warning"
+	// EventError generation of a table or file failed
+	EventError EventKind = "error"
+)
+
+// Event progress event of generation
+type Event struct {
+	Kind     EventKind `json:"kind"`
+	Time     time.Time `json:"time"`
+	Message  string    `json:"message"`
+	Table    string    `json:"table,omitempty"`
+	Model    string    `json:"model,omitempty"`
+	File     string    `json:"file,omitempty"`
+	FileKind FileKind  `json:"file_kind,omitempty"`
+	Template string    `json:"template,omitempty"`
+	Err      error     `json:"-"`
+}
+
+// MarshalJSON add error message as "error"
+func (e Event) MarshalJSON() ([]byte, error) {
+	type event Event
+	var errMsg string
+	if e.Err != nil {
+		errMsg = e.Err.Error()
+	}
+	return json.Marshal(struct {
+		event
+		Error string `json:"error,omitempty"`
+	}{event(e), errMsg})
+}
+
+// Reporter receive progress events, Report may be called concurrently
+type Reporter interface {
+	Report(e Event)
+}
+
+// ReporterFunc function as Reporter
+type ReporterFunc func(e Event)
+
+// Report implement Reporter
+func (f ReporterFunc) Report(e Event) { f(e) }
+
+// WithReporter specify reporter of progress events, default: log through gorm logger and standard logger
+func (cfg *Config) WithReporter(r Reporter) {
+	cfg.reporter = r
+}
+
+// NewJSONReporter create Reporter writing one JSON object per event into w
+func NewJSONReporter(w io.Writer) Reporter {
+	var mu sync.Mutex
+	enc := json.NewEncoder(w)
+	return ReporterFunc(func(e Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		_ = enc.Encode(e)
+	})
+}
+
+// report deliver event to reporter, or log it by logger of db when no reporter is set
+func (g *Generator) report(e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	if g.reporter != nil {
+		g.reporter.Report(e)
+		return
+	}
+
+	msg := e.Message
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	if g.db == nil || g.db.Logger == nil {
+		log.Println(msg)
+		return
+	}
+	switch e.Kind {
+	case EventWarning:
+		g.db.Logger.Warn(context.Background(), msg)
+	case EventError:
+		g.db.Logger.Error(context.Background(), msg)
+	default:
+		g.db.Logger.Info(context.Background(), msg)
+	}
+}
+
+// info report info message
+func (g *Generator) info(msg string) {
+	g.report(Event{Kind: EventInfo, Message: msg})
+}
+
+// reportError report every error of err with table, file and template name
+func (g *Generator) reportError(err error) {
+	var es GenerateErrors
+	if !errors.As(err, &es) {
+		es = GenerateErrors{{Err: err}}
+	}
+	for _, e := range es {
+		event := Event{Kind: EventError, Message: "generate code fail", Table: e.Table, File: e.File, Err: e.Err}
+		var te *TemplateError
+		if errors.As(e.Err, &te) {
+			event.Template = te.Template
+		}
+		g.report(event)
+	}
+}
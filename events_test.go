@@ -0,0 +1,96 @@
+package gen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestGenerator_Reporter(t *testing.T) {
+	outPath := filepath.Join(newModuleDir(t), "query")
+	var (
+		mu     sync.Mutex
+		events []Event
+	)
+	g := newTestGenerator(t, outPath)
+	g.WithReporter(ReporterFunc(func(e Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, e)
+	}))
+	if err := g.ExecuteE(); err != nil {
+		t.Fatalf("execute fail: %s", err)
+	}
+
+	kinds := make(map[EventKind]int)
+	for _, e := range events {
+		kinds[e.Kind]++
+		if e.Kind == EventFileGenerated && e.FileKind == ModelFileKind && (e.Table != "users" || e.Model != "User") {
+			t.Errorf("expect model file event with table and model, got %+v", e)
+		}
+	}
+	if events[0].Kind != EventStart || events[len(events)-1].Kind != EventDone || kinds[EventFileGenerated] != 4 {
+		t.Errorf("unexpected events: %+v", events)
+	}
+
+	g.WithModelTemplate("broken", "{{.NoSuchField}}")
+	var buf bytes.Buffer
+	g.WithReporter(NewJSONReporter(&buf))
+	if err := g.ExecuteE(); err == nil {
+		t.Fatalf("expect render error")
+	}
+	var found bool
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var e struct {
+			Kind, Table, File, Template, Error string
+		}
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("invalid json line %q: %s", line, err)
+		}
+		if e.Kind == string(EventError) {
+			found = e.Table == "users" && e.Template == "broken" && strings.HasSuffix(e.File, "users.broken.gen.go") && e.Error != ""
+		}
+	}
+	if !found {
+		t.Errorf("expect error event with table and template, got:\n%s", buf.String())
+	}
+}
+
+type recordLogger struct {
+	logger.Interface
+	msgs []string
+}
+
+func (l *recordLogger) Warn(_ context.Context, msg string, _ ...interface{}) {
+	l.msgs = append(l.msgs, msg)
+}
+
+// events are logged once, by logger of db if any
+func TestGenerator_ReportLog(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	rec := &recordLogger{}
+	g := NewGenerator(Config{})
+	g.db = &gorm.DB{Config: &gorm.Config{Logger: rec}}
+	g.report(Event{Kind: EventWarning, Message: "table skipped"})
+	if len(rec.msgs) != 1 || rec.msgs[0] != "table skipped" || buf.Len() != 0 {
+		t.Errorf("expect event logged by db logger only, got %v and %q", rec.msgs, buf.String())
+	}
+
+	g.db = nil
+	g.report(Event{Kind: EventWarning, Message: "table skipped"})
+	if !strings.Contains(buf.String(), "table skipped") {
+		t.Errorf("expect event logged without db")
+	}
+}
@@ -0,0 +1,120 @@
+package gen
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ForeignKey foreign key constraint of table, Columns and RefColumns are in the same order
+type ForeignKey struct {
+	Name       string
+	Table      string
+	Columns    []string
+	RefTable   string
+	RefColumns []string // empty if primary key of RefTable is referenced
+}
+
+// WithDialectForeignKeys specify foreign key lookup of dialect, used to infer relations
+func WithDialectForeignKeys(fn func(db *gorm.DB, schema, table string) ([]ForeignKey, error)) DialectOpt {
+	return func(d *Dialect) { d.ForeignKeys = fn }
+}
+
+// mysqlForeignKeys read foreign keys of table in database schema, current database if schema is empty
+func mysqlForeignKeys(db *gorm.DB, schema, table string) ([]ForeignKey, error) {
+	rows, err := db.Raw(`SELECT CONSTRAINT_NAME, COLUMN_NAME, REFERENCED_TABLE_NAME, REFERENCED_COLUMN_NAME
+FROM information_schema.KEY_COLUMN_USAGE
+WHERE TABLE_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE()) AND TABLE_NAME = ? AND REFERENCED_TABLE_NAME IS NOT NULL
+ORDER BY CONSTRAINT_NAME, ORDINAL_POSITION`, schema, table).Rows()
+	if err != nil {
+		return nil, err
+	}
+	return scanForeignKeys(table, rows)
+}
+
+// postgresForeignKeys read foreign keys of table in schema, current schema if empty
+func postgresForeignKeys(db *gorm.DB, schema, table string) ([]ForeignKey, error) {
+	rows, err := db.Raw(`SELECT c.conname, a.attname, rt.relname, ra.attname
+FROM pg_constraint c
+JOIN pg_class t ON t.oid = c.conrelid
+JOIN pg_namespace n ON n.oid = t.relnamespace
+JOIN pg_class rt ON rt.oid = c.confrelid
+CROSS JOIN LATERAL unnest(c.conkey, c.confkey) WITH ORDINALITY AS k(attnum, refattnum, ord)
+JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = k.attnum
+JOIN pg_attribute ra ON ra.attrelid = c.confrelid AND ra.attnum = k.refattnum
+WHERE c.contype = 'f' AND t.relname = ? AND n.nspname = COALESCE(NULLIF(?, ''), CURRENT_SCHEMA())
+ORDER BY c.conname, k.ord`, table, schema).Rows()
+	if err != nil {
+		return nil, err
+	}
+	return scanForeignKeys(table, rows)
+}
+
+// sqlserverForeignKeys read foreign keys of table in schema, default schema of user if empty
+func sqlserverForeignKeys(db *gorm.DB, schema, table string) ([]ForeignKey, error) {
+	rows, err := db.Raw(`SELECT fk.name, pc.name, rt.name, rc.name
+FROM sys.foreign_key_columns fkc
+JOIN sys.foreign_keys fk ON fk.object_id = fkc.constraint_object_id
+JOIN sys.tables pt ON pt.object_id = fkc.parent_object_id
+JOIN sys.schemas ps ON ps.schema_id = pt.schema_id
+JOIN sys.columns pc ON pc.object_id = fkc.parent_object_id AND pc.column_id = fkc.parent_column_id
+JOIN sys.tables rt ON rt.object_id = fkc.referenced_object_id
+JOIN sys.columns rc ON rc.object_id = fkc.referenced_object_id AND rc.column_id = fkc.referenced_column_id
+WHERE pt.name = ? AND ps.name = COALESCE(NULLIF(?, ''), SCHEMA_NAME())
+ORDER BY fk.name, fkc.constraint_column_id`, table, schema).Rows()
+	if err != nil {
+		return nil, err
+	}
+	return scanForeignKeys(table, rows)
+}
+
+// sqliteForeignKeys read foreign keys of table, sqlite foreign keys have no name, schema is not used
+func sqliteForeignKeys(db *gorm.DB, _, table string) ([]ForeignKey, error) {
+	rows, err := db.Raw(fmt.Sprintf(`SELECT 'fk_%[1]s_' || id, "from", "table", "to" FROM pragma_foreign_key_list('%[1]s') ORDER BY id, seq`,
+		strings.ReplaceAll(table, "'", "''"))).Rows()
+	if err != nil {
+		return nil, err
+	}
+	return scanForeignKeys(table, rows)
+}
+
+// scanForeignKeys group rows of (constraint, column, referenced table, referenced column) by constraint
+func scanForeignKeys(table string, rows *sql.Rows) (fks []ForeignKey, err error) {
+	defer rows.Close()
+
+	for rows.Next() {
+		var name, column, refTable string
+		var refColumn sql.NullString
+		if err = rows.Scan(&name, &column, &refTable, &refColumn); err != nil {
+			return nil, err
+		}
+		if len(fks) == 0 || fks[len(fks)-1].Name != name {
+			fks = append(fks, ForeignKey{Name: name, Table: table, RefTable: refTable})
+		}
+		fk := &fks[len(fks)-1]
+		fk.Columns = append(fk.Columns, column)
+		if refColumn.Valid && refColumn.String != "" {
+			fk.RefColumns = append(fk.RefColumns, refColumn.String)
+		}
+	}
+	return fks, rows.Err()
+}
+
+// ForeignKeys return foreign keys parsed from sql files of table
+func (d *DDLDialector) ForeignKeys(table string) []ForeignKey {
+	t := d.schema.Table(table)
+	if t == nil {
+		return nil
+	}
+	fks := make([]ForeignKey, 0, len(t.ForeignKeys))
+	for i, fk := range t.ForeignKeys {
+		name := fk.Name
+		if name == "" {
+			name = fmt.Sprintf("fk_%s_%d", table, i)
+		}
+		fks = append(fks, ForeignKey{Name: name, Table: table, Columns: fk.Columns, RefTable: fk.RefTable, RefColumns: fk.RefColumns})
+	}
+	return fks
+}
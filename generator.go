@@ -4,17 +4,21 @@ import (
 	"bytes"
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"github.com/gogf/gf/v2/os/gfile"
 	"io"
-	"io/ioutil"
-	"log"
+	"io/fs"
 	"os"
+	"path"
+	"path/filepath"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"text/template"
 
+	"golang.org/x/mod/modfile"
 	"golang.org/x/tools/go/packages"
 	"golang.org/x/tools/imports"
 	"gorm.io/gorm"
@@ -24,7 +28,6 @@ import (
 	"gorm.io/gen/internal/generate"
 	"gorm.io/gen/internal/model"
 	"gorm.io/gen/internal/parser"
-	tmpl "gorm.io/gen/internal/template"
 	"gorm.io/gen/internal/utils/pools"
 )
 
@@ -56,11 +59,17 @@ func NewGenerator(cfg Config) *Generator {
 		panic(fmt.Errorf("create generator fail: %w", err))
 	}
 
-	return &Generator{
+	g := &Generator{
 		Config: cfg,
 		Data:   make(map[string]*genInfo),
 		models: make(map[string]*generate.QueryStructMeta),
 	}
+	g.Schema = Schema{
+		Schema:    make(map[string]*schema.Schema),
+		Model:     make(map[string]any),
+		Generator: g,
+	}
+	return g
 }
 
 // genInfo info about generated code
@@ -93,6 +102,20 @@ type Generator struct {
 	Schema                                      // edit by hinego
 	Data   map[string]*genInfo                  //gen query data
 	models map[string]*generate.QueryStructMeta //gen model data
+
+	relationTables map[string]*relationTable // tables read for relation inference, loaded on first use
+	relationSchema string                    // schema of relationTables, empty for current database
+
+	checker   *checker             // compare with files on disk instead of writing, set by Check
+	manifests map[string]*manifest // files generated by current run, by output root, see manifestRoots
+
+	incremental *incremental // fingerprints of current run, set when Config.Incremental is on
+
+	templates             map[string]string // templates by name, with user overrides
+	extraModelTemplates   []extraTemplate
+	extraPackageTemplates []extraTemplate
+
+	plugins []Plugin
 }
 
 // UseDB set db connection
@@ -109,48 +132,102 @@ func (g *Generator) UseDB(db *gorm.DB) {
 
 // GenerateModel catch table info from db, return a BaseStruct
 func (g *Generator) GenerateModel(tableName string, opts ...ModelOpt) *generate.QueryStructMeta {
+	meta, err := g.GenerateModelE(tableName, opts...)
+	if err != nil {
+		g.db.Logger.Error(context.Background(), "generate struct from table fail: %s", err)
+		panic("generate struct fail")
+	}
+	return meta
+}
+
+// GenerateModelE catch table info from db, return a BaseStruct or error
+func (g *Generator) GenerateModelE(tableName string, opts ...ModelOpt) (*generate.QueryStructMeta, error) {
 	if opt := g.GetModel(tableName); opt != nil {
 		opts = append(opts, WithMethod(opt))
 	}
-	return g.GenerateModelAs(tableName, g.db.Config.NamingStrategy.SchemaName(tableName), opts...)
+	return g.GenerateModelAsE(tableName, g.db.Config.NamingStrategy.SchemaName(tableName), opts...)
 }
 
 // GenerateModelAs catch table info from db, return a BaseStruct
 func (g *Generator) GenerateModelAs(tableName string, modelName string, opts ...ModelOpt) *generate.QueryStructMeta {
-	conf := g.genModelConfig(tableName, modelName, opts)
+	meta, err := g.GenerateModelAsE(tableName, modelName, opts...)
+	if err != nil {
+		g.db.Logger.Error(context.Background(), "generate struct from table fail: %s", err)
+		panic("generate struct fail")
+	}
+	return meta
+}
+
+// GenerateModelAsE catch table info from db, return a BaseStruct or error
+func (g *Generator) GenerateModelAsE(tableName string, modelName string, opts ...ModelOpt) (*generate.QueryStructMeta, error) {
+	conf, err := g.genModelConfig(tableName, modelName, opts)
+	if err != nil {
+		return nil, &GenerateError{Table: tableName, Err: err}
+	}
 	_, structName, _ := conf.GetNames()
 	if _, ok := g.models[structName]; ok {
-		return g.models[structName]
+		return g.models[structName], nil
 	}
-	meta, err := generate.GetQueryStructMeta(g.db, g.genModelConfig(tableName, modelName, opts))
+	meta, err := generate.GetQueryStructMeta(g.db, conf)
 	if err != nil {
-		g.db.Logger.Error(context.Background(), "generate struct from table fail: %s", err)
-		panic("generate struct fail")
+		return nil, &GenerateError{Table: tableName, Err: err}
 	}
 	if meta == nil {
-		g.info(fmt.Sprintf("ignore table <%s>", tableName))
-		return nil
+		g.report(Event{Kind: EventWarning, Message: fmt.Sprintf("ignore table <%s>", tableName), Table: tableName})
+		return nil, nil
+	}
+	if err = g.afterMeta(meta); err != nil {
+		return nil, &GenerateError{Table: tableName, Err: err}
 	}
 	g.models[meta.ModelStructName] = meta
+	if err = g.inferRelations(meta); err != nil {
+		delete(g.models, meta.ModelStructName)
+		return nil, &GenerateError{Table: tableName, Err: err}
+	}
 
-	g.info(fmt.Sprintf("got %d columns from table <%s>", len(meta.Fields), meta.TableName))
-	return meta
+	g.report(Event{
+		Kind:    EventTableIntrospected,
+		Message: fmt.Sprintf("got %d columns from table <%s>", len(meta.Fields), meta.TableName),
+		Table:   meta.TableName,
+		Model:   meta.ModelStructName,
+	})
+	return meta, nil
 }
 
 // GenerateAllTable generate all tables in db
 func (g *Generator) GenerateAllTable(opts ...ModelOpt) (tableModels []interface{}) {
+	tableModels, err := g.GenerateAllTableE(opts...)
+	if err != nil {
+		g.db.Logger.Error(context.Background(), "generate struct from table fail: %s", err)
+		panic("generate struct fail")
+	}
+	return tableModels
+}
+
+// GenerateAllTableE generate all tables in db, tables which fail to generate are reported
+// together in the returned error and left out of tableModels
+func (g *Generator) GenerateAllTableE(opts ...ModelOpt) (tableModels []interface{}, err error) {
 	tableList, err := g.db.Migrator().GetTables()
 	if err != nil {
-		panic(fmt.Errorf("get all tables fail: %w", err))
+		return nil, fmt.Errorf("get all tables fail: %w", err)
 	}
 
 	g.info(fmt.Sprintf("find %d table from db: %s", len(tableList), tableList))
+	if tableList, err = g.filterTables(tableList); err != nil {
+		return nil, err
+	}
 
-	tableModels = make([]interface{}, len(tableList))
-	for i, tableName := range tableList {
-		tableModels[i] = g.GenerateModel(tableName, opts...)
+	var errs errCollector
+	tableModels = make([]interface{}, 0, len(tableList))
+	for _, tableName := range tableList {
+		meta, err := g.GenerateModelE(tableName, opts...)
+		if err != nil {
+			errs.Add(tableName, "", err)
+			continue
+		}
+		tableModels = append(tableModels, meta)
 	}
-	return tableModels
+	return tableModels, errs.Err()
 }
 
 // GenerateModelFrom generate model from object
@@ -159,19 +236,26 @@ func (g *Generator) GenerateModelFrom(obj helper.Object) *generate.QueryStructMe
 	if err != nil {
 		panic(fmt.Errorf("generate struct from object fail: %w", err))
 	}
+	if err = g.afterMeta(s); err != nil {
+		panic(fmt.Errorf("generate struct from object fail: %w", err))
+	}
 	g.models[s.ModelStructName] = s
 
-	g.info(fmt.Sprintf("parse object %s", obj.StructName()))
+	g.report(Event{Kind: EventTableIntrospected, Message: fmt.Sprintf("parse object %s", obj.StructName()), Table: s.TableName, Model: s.ModelStructName})
 	return s
 }
 
-func (g *Generator) genModelConfig(tableName string, modelName string, modelOpts []ModelOpt) *model.Config {
+func (g *Generator) genModelConfig(tableName string, modelName string, modelOpts []ModelOpt) (*model.Config, error) {
 	if modelOpts == nil {
 		modelOpts = g.modelOpts
 	} else {
 		modelOpts = append(modelOpts, g.modelOpts...)
 	}
-	modelOpts = append(modelOpts, g.GetModelOpt(tableName)...) // edit by hinego
+	linkedOpts, err := g.GetModelOpt(tableName) // edit by hinego
+	if err != nil {
+		return nil, err
+	}
+	modelOpts = append(modelOpts, linkedOpts...)
 	return &model.Config{
 		Schema:         g.GetSchema(tableName), // edit by hinego
 		ModelPkg:       g.Config.ModelPkgPath,
@@ -181,24 +265,25 @@ func (g *Generator) genModelConfig(tableName string, modelName string, modelOpts
 		ImportPkgPaths: g.importPkgPaths,
 		ModelOpts:      modelOpts,
 		NameStrategy: model.NameStrategy{
-			SchemaNameOpts: g.dbNameOpts,
+			SchemaNameOpts: g.schemaNameOpts(),
 			TableNameNS:    g.tableNameNS,
 			ModelNameNS:    g.modelNameNS,
 			FileNameNS:     g.fileNameNS,
 		},
 		FieldConfig: model.FieldConfig{
-			DataTypeMap: g.dataTypeMap,
+			DataTypeMap: g.dataTypes(),
 
 			FieldSignable:     g.FieldSignable,
 			FieldNullable:     g.FieldNullable,
 			FieldCoverable:    g.FieldCoverable,
 			FieldWithIndexTag: g.FieldWithIndexTag,
 			FieldWithTypeTag:  g.FieldWithTypeTag,
+			ReadIndexes:       g.usesDataDictionary(),
 
 			FieldJSONTagNS: g.fieldJSONTagNS,
 			FieldNewTagNS:  g.fieldNewTagNS,
 		},
-	}
+	}, nil
 }
 
 func (g *Generator) getTablePrefix() string {
@@ -225,124 +310,209 @@ func (g *Generator) ApplyBasic(models ...interface{}) {
 	g.ApplyInterface(func() {}, models...)
 }
 
+// ApplyBasicE specify models which will implement basic .diy_method, return error instead of panic
+func (g *Generator) ApplyBasicE(models ...interface{}) error {
+	return g.applyInterface(func() {}, "", models...)
+}
+
 // ApplyInterface specifies .diy_method interfaces on structures, implment codes will be generated after calling g.Execute()
 // eg: g.ApplyInterface(func(model.Method){}, model.User{}, model.Company{})
 func (g *Generator) ApplyInterface(fc interface{}, models ...interface{}) {
+	_, callerFile, _, _ := runtime.Caller(1)
+	if err := g.applyInterface(fc, callerFile, models...); err != nil {
+		g.db.Logger.Error(context.Background(), "apply interface fail: %s", err)
+		panic("apply interface fail")
+	}
+}
+
+// ApplyInterfaceE specifies .diy_method interfaces on structures, return error instead of panic
+func (g *Generator) ApplyInterfaceE(fc interface{}, models ...interface{}) error {
+	_, callerFile, _, _ := runtime.Caller(1)
+	return g.applyInterface(fc, callerFile, models...)
+}
+
+// applyInterface apply interfaces of fc, interfaces declared in package main are read from directory of callerFile
+func (g *Generator) applyInterface(fc interface{}, callerFile string, models ...interface{}) error {
 	structs, err := generate.ConvertStructs(g.db, models...)
 	if err != nil {
-		g.db.Logger.Error(context.Background(), "check struct fail: %v", err)
-		panic("check struct fail")
+		return fmt.Errorf("check struct fail: %w", err)
 	}
-	g.apply(fc, structs)
+	return g.apply(fc, callerFile, structs)
 }
 
-func (g *Generator) apply(fc interface{}, structs []*generate.QueryStructMeta) {
-	interfacePaths, err := parser.GetInterfacePath(fc)
+// ApplyInterfaceSourceE specifies .diy_method interfaces located by source on structures,
+// for callers which can not reference the interface type, e.g. gentool
+func (g *Generator) ApplyInterfaceSourceE(sources []InterfaceSource, models ...interface{}) error {
+	interfacePaths := make([]*parser.InterfacePath, 0, len(sources))
+	for _, src := range sources {
+		path, err := src.interfacePath()
+		if err != nil {
+			return fmt.Errorf("get interface %s file fail: %w", src.Name, err)
+		}
+		interfacePaths = append(interfacePaths, path)
+	}
+	structs, err := generate.ConvertStructs(g.db, models...)
+	if err != nil {
+		return fmt.Errorf("check struct fail: %w", err)
+	}
+	return g.applyPaths(interfacePaths, structs)
+}
+
+func (g *Generator) apply(fc interface{}, callerFile string, structs []*generate.QueryStructMeta) error {
+	interfacePaths, err := parser.GetInterfacePath(fc, callerFile)
 	if err != nil {
-		g.db.Logger.Error(context.Background(), "get interface name or file fail: %s", err)
-		panic("check interface fail")
+		return fmt.Errorf("get interface name or file fail: %w", err)
 	}
+	return g.applyPaths(interfacePaths, structs)
+}
 
+func (g *Generator) applyPaths(interfacePaths []*parser.InterfacePath, structs []*generate.QueryStructMeta) error {
 	readInterface := new(parser.InterfaceSet)
-	err = readInterface.ParseFile(interfacePaths, generate.GetStructNames(structs))
+	err := readInterface.ParseFile(interfacePaths, generate.GetStructNames(structs))
 	if err != nil {
-		g.db.Logger.Error(context.Background(), "parser interface file fail: %s", err)
-		panic("parser interface file fail")
+		return fmt.Errorf("parser interface file fail: %w", err)
+	}
+	if err = checkInterfaceFound(interfacePaths, readInterface); err != nil {
+		return err
 	}
 
+	var errs errCollector
 	for _, interfaceStructMeta := range structs {
 		if g.judgeMode(WithoutContext) {
 			interfaceStructMeta.ReviseFieldNameFor(model.GormKeywords)
 		}
 		interfaceStructMeta.ReviseFieldNameFor(model.DOKeywords)
+		if interfaceStructMeta.Source == model.Struct {
+			if err := g.afterMeta(interfaceStructMeta); err != nil {
+				errs.Add(interfaceStructMeta.TableName, "", err)
+				continue
+			}
+		}
 
 		genInfo, err := g.pushQueryStructMeta(interfaceStructMeta)
 		if err != nil {
-			g.db.Logger.Error(context.Background(), "gen struct fail: %v", err)
-			panic("gen struct fail")
+			errs.Add(interfaceStructMeta.TableName, "", fmt.Errorf("gen struct fail: %w", err))
+			continue
 		}
 
 		functions, err := generate.BuildDIYMethod(readInterface, interfaceStructMeta, genInfo.Interfaces)
 		if err != nil {
-			g.db.Logger.Error(context.Background(), "check interface fail: %v", err)
-			panic("check interface fail")
+			errs.Add(interfaceStructMeta.TableName, "", fmt.Errorf("check interface fail: %w", err))
+			continue
 		}
 		genInfo.appendMethods(functions)
 	}
+	return errs.Err()
 }
 
 // Execute generate code to output path
 func (g *Generator) Execute() {
-	g.info("Start generating code.")
+	if err := g.ExecuteE(); err != nil {
+		g.db.Logger.Error(context.Background(), "generate code fail: %s", err)
+		panic("generate code fail")
+	}
+}
 
-	if err := g.generateModelFile(); err != nil {
-		g.db.Logger.Error(context.Background(), "generate model struct fail: %s", err)
-		panic("generate model struct fail")
+// ExecuteE generate code to output path, return all errors occurred as GenerateErrors instead of panic
+func (g *Generator) ExecuteE() (err error) {
+	g.report(Event{Kind: EventStart, Message: "Start generating code."})
+	defer func() {
+		if err != nil {
+			g.reportError(err)
+		}
+	}()
+
+	g.manifests = make(map[string]*manifest)
+	for _, root := range g.manifestRoots() {
+		g.manifests[root] = newManifest()
 	}
+	defer func() { g.manifests = nil }()
 
-	if err := g.generateQueryFile(); err != nil {
-		g.db.Logger.Error(context.Background(), "generate query code fail: %s", err)
-		panic("generate query code fail")
+	if err := g.loadTemplates(); err != nil {
+		return err
 	}
-	if err := g.generateFiledFile(); err != nil {
-		g.db.Logger.Error(context.Background(), "generate field code fail: %s", err)
-		panic("generate field code fail")
+
+	if g.Incremental && g.checker == nil {
+		if err := g.prepareIncremental(); err != nil {
+			return fmt.Errorf("prepare incremental generation fail: %w", err)
+		}
+		defer func() { g.incremental = nil }()
 	}
-	g.info("Generate code done.")
-}
 
-// info logger
-func (g *Generator) info(logInfos ...string) {
-	for _, l := range logInfos {
-		g.db.Logger.Info(context.Background(), l)
-		log.Println(l)
+	var errs errCollector
+	errs.Add("", "", g.generateModelFile())
+	errs.Add("", "", g.generateQueryFile())
+	errs.Add("", "", g.generateFiledFile())
+	errs.Add("", "", g.generateExtraFiles())
+	if err := errs.Err(); err != nil {
+		return err
+	}
+	if err := g.afterExecute(); err != nil {
+		return err
 	}
+	if err := g.cleanStaleFiles(); err != nil {
+		return fmt.Errorf("clean stale files fail: %w", err)
+	}
+	if err := g.saveIncrementalState(); err != nil {
+		return fmt.Errorf("save incremental state fail: %w", err)
+	}
+
+	g.report(Event{Kind: EventDone, Message: "Generate code done."})
+	return nil
 }
 
+// info logger
 func (g *Generator) generateSingleFieldFile(info *genInfo) (err error) {
+	file := &RenderFile{
+		Name: fmt.Sprintf("%s/%s/%s.gen.go", g.OutPath, info.FileName, info.FileName),
+		Kind: FieldFileKind,
+		Meta: info.QueryStructMeta,
+	}
+	if err = g.beforeRender(file); err != nil {
+		return err
+	}
+
 	var (
 		buf  bytes.Buffer
 		data = map[string]any{
 			"Dao":        gfile.Basename(g.OutPath),
+			"DaoPkgPath": g.queryPkgPath,
 			"Package":    info.FileName,
 			"Fields":     info.Fields,
 			"StructName": info.ModelStructName,
 		}
 	)
-	if err = render(tmpl.Field, &buf, data); err != nil {
+	if err = g.render("Field", &buf, data); err != nil {
 		return err
 	}
-	if err = gfile.Mkdir(fmt.Sprintf("%s/%s", g.OutPath, info.FileName)); err != nil {
+	if err = g.mkdir(fmt.Sprintf("%s/%s", g.OutPath, info.FileName)); err != nil {
 		return err
 	}
-	defer g.info(fmt.Sprintf("generate field file: %s/%s/%s.gen.go", g.OutPath, info.FileName, info.FileName))
-	return g.output(fmt.Sprintf("%s/%s/%s.gen.go", g.OutPath, info.FileName, info.FileName), buf.Bytes())
-}
-func (g *Generator) generateFiledFileSub() (err error) {
-	log.Println("generateFiledFile", g.OutPath)
-	for _, info := range g.Data {
-		if err = g.generateSingleFieldFile(info); err != nil {
-			log.Println(err)
-		}
-	}
-	return err
+	return g.outputRendered(file, buf.Bytes())
 }
+
 func (g *Generator) generateFiledFile() (err error) {
-	log.Println("generateFiledFile", g.OutPath)
+	if g.queryPkgPath, err = resolvePkgPath(g.OutPath); err != nil {
+		return fmt.Errorf("resolve query pkg path(%s) fail: %w", g.OutPath, err)
+	}
+
+	var errs errCollector
 	pool := pools.NewPool(concurrent)
-	for _, info := range g.Data {
+	for _, info := range g.sortedData() {
+		fieldFile := fmt.Sprintf("%s/%s/%s.gen.go", g.OutPath, info.FileName, info.FileName)
+		if g.skipUnchanged(info.ModelStructName, fieldFile) {
+			continue
+		}
 		pool.Wait()
 		go func(info *genInfo) {
 			defer pool.Done()
-			if err = g.generateSingleFieldFile(info); err != nil {
-				log.Println(err)
-			}
+			errs.Add(info.TableName, fieldFile, g.generateSingleFieldFile(info))
 		}(info)
 	}
 	select {
 	case <-pool.AsyncWaitAll():
 	}
-	return err
+	return errs.Err()
 }
 
 // generateQueryFile generate query code and save to file
@@ -351,26 +521,29 @@ func (g *Generator) generateQueryFile() (err error) {
 		return nil
 	}
 
-	if err = os.MkdirAll(g.OutPath, os.ModePerm); err != nil {
+	if err = g.mkdir(g.OutPath); err != nil {
 		return fmt.Errorf("make dir outpath(%s) fail: %s", g.OutPath, err)
 	}
 
+	var errs errCollector
 	pool := pools.NewPool(concurrent)
 	// generate query code for all struct
-
-	for _, info := range g.Data {
+	for _, info := range g.sortedData() {
+		queryFile := fmt.Sprintf("%s/%s.gen.go", g.OutPath, info.FileName)
+		testFile := fmt.Sprintf("%s/%s.gen_test.go", g.OutPath, info.FileName)
+		files := []string{queryFile}
+		if g.WithUnitTest {
+			files = append(files, testFile)
+		}
+		if g.skipUnchanged(info.ModelStructName, files...) {
+			continue
+		}
 		pool.Wait()
 		go func(info *genInfo) {
 			defer pool.Done()
-			err := g.generateSingleQueryFile(info)
-			if err != nil {
-				log.Println(err)
-			}
+			errs.Add(info.TableName, queryFile, g.generateSingleQueryFile(info))
 			if g.WithUnitTest {
-				err = g.generateQueryUnitTestFile(info)
-				if err != nil { // do not panic
-					g.db.Logger.Error(context.Background(), "generate unit test fail: %s", err)
-				}
+				errs.Add(info.TableName, testFile, g.generateQueryUnitTestFile(info))
 			}
 		}(info)
 	}
@@ -379,8 +552,25 @@ func (g *Generator) generateQueryFile() (err error) {
 	}
 
 	// generate query file
+	errs.Add("", g.OutFile, g.generateQueryEntryFile())
+
+	// generate query unit test file
+	if g.WithUnitTest {
+		fileName := strings.TrimSuffix(g.OutFile, ".go") + "_test.go"
+		errs.Add("", fileName, g.generateQueryEntryTestFile(fileName))
+	}
+	return errs.Err()
+}
+
+// generateQueryEntryFile generate the query file holding Query and Use
+func (g *Generator) generateQueryEntryFile() (err error) {
+	file := &RenderFile{Name: g.OutFile, Kind: EntryFileKind}
+	if err = g.beforeRender(file); err != nil {
+		return err
+	}
+
 	var buf bytes.Buffer
-	err = render(tmpl.Header, &buf, map[string]interface{}{
+	err = g.render("Header", &buf, map[string]interface{}{
 		"Package":        g.queryPkgName,
 		"ImportPkgPaths": importList.Add(g.importPkgPaths...).Paths(),
 	})
@@ -389,64 +579,59 @@ func (g *Generator) generateQueryFile() (err error) {
 	}
 
 	if g.judgeMode(WithDefaultQuery) {
-		err = render(tmpl.DefaultQuery, &buf, g)
+		err = g.render("DefaultQuery", &buf, g)
 		if err != nil {
 			return err
 		}
 	}
-	err = render(tmpl.QueryMethod, &buf, g)
+	err = g.render("QueryMethod", &buf, g)
 	if err != nil {
 		return err
 	}
 
-	err = g.output(g.OutFile, buf.Bytes())
-	if err != nil {
+	return g.outputRendered(file, buf.Bytes())
+}
+
+// generateQueryEntryTestFile generate unit test file for the query file
+func (g *Generator) generateQueryEntryTestFile(fileName string) (err error) {
+	file := &RenderFile{Name: fileName, Kind: EntryTestFileKind}
+	if err = g.beforeRender(file); err != nil {
 		return err
 	}
-	g.info("generate query file: " + g.OutFile)
-
-	// generate query unit test file
-	if g.WithUnitTest {
-		buf.Reset()
 
-		err = render(tmpl.Header, &buf, map[string]interface{}{
-			"Package":        g.queryPkgName,
-			"ImportPkgPaths": unitTestImportList.Add(g.importPkgPaths...).Paths(),
-		})
-		if err != nil {
-			g.db.Logger.Error(context.Background(), "generate query unit test fail: %s", err)
-			return nil
-		}
-		err = render(tmpl.DIYMethodTestBasic, &buf, nil)
-		if err != nil {
-			return err
-		}
-		err = render(tmpl.QueryMethodTest, &buf, g)
-		if err != nil {
-			g.db.Logger.Error(context.Background(), "generate query unit test fail: %s", err)
-			return nil
-		}
-		fileName := strings.TrimSuffix(g.OutFile, ".go") + "_test.go"
-		err = g.output(fileName, buf.Bytes())
-		if err != nil {
-			g.db.Logger.Error(context.Background(), "generate query unit test fail: %s", err)
-			return nil
-		}
-		g.info("generate unit test file: " + fileName)
+	var buf bytes.Buffer
+	err = g.render("Header", &buf, map[string]interface{}{
+		"Package":        g.queryPkgName,
+		"ImportPkgPaths": unitTestImportList.Add(g.importPkgPaths...).Paths(),
+	})
+	if err != nil {
+		return err
 	}
-
-	return nil
+	err = g.render("DIYMethodTestBasic", &buf, nil)
+	if err != nil {
+		return err
+	}
+	err = g.render("QueryMethodTest", &buf, g)
+	if err != nil {
+		return err
+	}
+	return g.outputRendered(file, buf.Bytes())
 }
 
 // generateSingleQueryFile generate query code and save to file
 func (g *Generator) generateSingleQueryFile(data *genInfo) (err error) {
+	file := &RenderFile{Name: fmt.Sprintf("%s/%s.gen.go", g.OutPath, data.FileName), Kind: QueryFileKind, Meta: data.QueryStructMeta}
+	if err = g.beforeRender(file); err != nil {
+		return err
+	}
+
 	var buf bytes.Buffer
 	data.QueryStructMeta.Do()
 	structPkgPath := data.StructInfo.PkgPath
 	if structPkgPath == "" {
 		structPkgPath = g.modelPkgPath
 	}
-	err = render(tmpl.Header, &buf, map[string]interface{}{
+	err = g.render("Header", &buf, map[string]interface{}{
 		"Package":        g.queryPkgName,
 		"ImportPkgPaths": importList.Add(structPkgPath).Add(getImportPkgPaths(data)...).Paths(),
 	})
@@ -456,47 +641,51 @@ func (g *Generator) generateSingleQueryFile(data *genInfo) (err error) {
 
 	data.QueryStructMeta = data.QueryStructMeta.IfaceMode(g.judgeMode(WithQueryInterface))
 
-	structTmpl := tmpl.TableQueryStructWithContext
+	structTmpl := "TableQueryStructWithContext"
 	if g.judgeMode(WithoutContext) {
-		structTmpl = tmpl.TableQueryStruct
+		structTmpl = "TableQueryStruct"
 	}
-	err = render(structTmpl, &buf, data.QueryStructMeta)
+	err = g.render(structTmpl, &buf, data.QueryStructMeta)
 	if err != nil {
 		return err
 	}
 
 	if g.judgeMode(WithQueryInterface) {
-		err = render(tmpl.TableQueryIface, &buf, data)
+		err = g.render("TableQueryIface", &buf, data)
 		if err != nil {
 			return err
 		}
 	}
 
 	for _, method := range data.Interfaces {
-		err = render(tmpl.DIYMethod, &buf, method)
+		err = g.render("DIYMethod", &buf, method)
 		if err != nil {
 			return err
 		}
 	}
 	data.QueryStructMeta.Do()
-	err = render(tmpl.CRUDMethod, &buf, data.QueryStructMeta)
+	err = g.render("CRUDMethod", &buf, data.QueryStructMeta)
 	if err != nil {
 		return err
 	}
 
-	defer g.info(fmt.Sprintf("generate query file: %s/%s.gen.go", g.OutPath, data.FileName))
-	return g.output(fmt.Sprintf("%s/%s.gen.go", g.OutPath, data.FileName), buf.Bytes())
+	return g.outputRendered(file, buf.Bytes())
 }
 
 // generateQueryUnitTestFile generate unit test file for query
 func (g *Generator) generateQueryUnitTestFile(data *genInfo) (err error) {
+	file := &RenderFile{Name: fmt.Sprintf("%s/%s.gen_test.go", g.OutPath, data.FileName), Kind: QueryTestFileKind, Meta: data.QueryStructMeta}
+	if err = g.beforeRender(file); err != nil {
+		return err
+	}
+
 	var buf bytes.Buffer
 
 	structPkgPath := data.StructInfo.PkgPath
 	if structPkgPath == "" {
 		structPkgPath = g.modelPkgPath
 	}
-	err = render(tmpl.Header, &buf, map[string]interface{}{
+	err = g.render("Header", &buf, map[string]interface{}{
 		"Package":        g.queryPkgName,
 		"ImportPkgPaths": unitTestImportList.Add(structPkgPath).Add(data.ImportPkgPaths...).Paths(),
 	})
@@ -504,20 +693,19 @@ func (g *Generator) generateQueryUnitTestFile(data *genInfo) (err error) {
 		return err
 	}
 
-	err = render(tmpl.CRUDMethodTest, &buf, data.QueryStructMeta)
+	err = g.render("CRUDMethodTest", &buf, data.QueryStructMeta)
 	if err != nil {
 		return err
 	}
 
 	for _, method := range data.Interfaces {
-		err = render(tmpl.DIYMethodTest, &buf, method)
+		err = g.render("DIYMethodTest", &buf, method)
 		if err != nil {
 			return err
 		}
 	}
 
-	defer g.info(fmt.Sprintf("generate unit test file: %s/%s.gen_test.go", g.OutPath, data.FileName))
-	return g.output(fmt.Sprintf("%s/%s.gen_test.go", g.OutPath, data.FileName), buf.Bytes())
+	return g.outputRendered(file, buf.Bytes())
 }
 
 // generateModelFile generate model structures and save to file
@@ -529,101 +717,217 @@ func (g *Generator) generateModelFile() error {
 	if err != nil {
 		return err
 	}
-	if err = os.MkdirAll(modelOutPath, os.ModePerm); err != nil {
+	if err = g.mkdir(modelOutPath); err != nil {
 		return fmt.Errorf("create model pkg path(%s) fail: %s", modelOutPath, err)
 	}
+
+	modelPkgName := g.queryPkgName
+	separate := filepath.Clean(modelOutPath) != filepath.Clean(g.OutPath)
+	if separate {
+		// query code imports models, model package path must be known before rendering
+		if g.Config.modelPkgPath, err = resolvePkgPath(modelOutPath); err != nil {
+			return fmt.Errorf("resolve model pkg path(%s) fail: %w", modelOutPath, err)
+		}
+		modelPkgName = filepath.Base(modelOutPath)
+	}
+
+	var errs errCollector
 	pool := pools.NewPool(concurrent)
-	for _, data := range g.models {
+	for _, data := range g.sortedModels() {
 		if data == nil || !data.Generated {
 			continue
 		}
+		if separate {
+			data.ModelPkgName = modelPkgName
+			data.StructInfo.PkgPath = g.modelPkgPath
+			g.warnRelationHelpers(data)
+		}
+		modelFile := modelOutPath + data.FileName + ".model.gen.go"
+		if g.skipUnchanged(data.ModelStructName, modelFile) {
+			continue
+		}
 		data.Do()
 		pool.Wait()
-		data.Package = g.queryPkgName
+		data.Package = modelPkgName
 		go func(data *generate.QueryStructMeta) {
 			defer pool.Done()
-			var buf bytes.Buffer
-			var err error
-			if err = render(tmpl.Model, &buf, data); err != nil {
-				log.Println(err)
-				return
-			}
-			for _, method := range data.ModelMethods {
-				err = render(tmpl.ModelMethod, &buf, method)
-				if err != nil {
-					log.Println(err)
-					return
-				}
-			}
-			modelFile := modelOutPath + data.FileName + ".model.gen.go"
-			var bt = buf.Bytes()
-			bt = bytes.ReplaceAll(bt, []byte(`"github.com/gogf/gf/v2`), []byte(`"github.com/gogf/gf`))
-			bt = bytes.ReplaceAll(bt, []byte(`"github.com/gogf/gf`), []byte(`"github.com/gogf/gf/v2`))
-
-			if err = g.output(modelFile, bt); err != nil {
-				log.Println(err)
-				return
-			}
-			g.info(fmt.Sprintf("generate model file(table <%s> -> {%s.%s}): %s", data.TableName, data.StructInfo.Package, data.StructInfo.Type, modelFile))
+			errs.Add(data.TableName, modelFile, g.generateSingleModelFile(data, modelFile))
 		}(data)
 	}
 	select {
 	case <-pool.AsyncWaitAll():
-		g.fillModelPkgPath(modelOutPath)
+		if g.modelPkgPath == "" {
+			g.fillModelPkgPath(modelOutPath)
+		}
 	}
-	return nil
+	return errs.Err()
 }
 
+// warnRelationHelpers report relation helpers, e.g. QueryOrders, which are not generated for model in separate package:
+// they are methods of model calling query code, while query package imports model package
+func (g *Generator) warnRelationHelpers(data *generate.QueryStructMeta) {
+	for _, f := range data.Fields {
+		if f.IsRelation() && f.Relation.Key != "" {
+			g.report(Event{Kind: EventWarning, Table: data.TableName,
+				Message: fmt.Sprintf("relation helpers of %s are not generated, model package %s can not import query package", data.ModelStructName, data.ModelPkgName)})
+			return
+		}
+	}
+}
+
+// generateSingleModelFile generate model structure and save to file
+func (g *Generator) generateSingleModelFile(data *generate.QueryStructMeta, modelFile string) (err error) {
+	file := &RenderFile{Name: modelFile, Kind: ModelFileKind, Meta: data}
+	if err = g.beforeRender(file); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err = g.render("Model", &buf, data); err != nil {
+		return err
+	}
+	for _, method := range data.ModelMethods {
+		if err = g.render("ModelMethod", &buf, method); err != nil {
+			return err
+		}
+	}
+	var bt = buf.Bytes()
+	bt = bytes.ReplaceAll(bt, []byte(`"github.com/gogf/gf/v2`), []byte(`"github.com/gogf/gf`))
+	bt = bytes.ReplaceAll(bt, []byte(`"github.com/gogf/gf`), []byte(`"github.com/gogf/gf/v2`))
+
+	return g.outputRendered(file, bt)
+}
+
+// getModelOutputPath return directory of model files: OutPath when ModelPkgPath is not set,
+// ModelPkgPath itself when it is a path, otherwise a sibling directory of OutPath named ModelPkgPath
 func (g *Generator) getModelOutputPath() (outPath string, err error) {
-	//g.ModelPkgPath = "dap"
-	//if strings.Contains(g.ModelPkgPath, string(os.PathSeparator)) {
-	//	outPath, err = filepath.Abs(g.ModelPkgPath)
-	//	if err != nil {
-	//		return "", fmt.Errorf("cannot parse model pkg path: %w", err)
-	//	}
-	//} else {
-	//	outPath = filepath.Join(filepath.Dir(g.OutPath), g.ModelPkgPath)
-	//}
-	return g.OutPath + string(os.PathSeparator), nil
+	switch {
+	case g.ModelPkgPath == "" || g.modelPkgUnset:
+		outPath = g.OutPath
+	case strings.ContainsAny(g.ModelPkgPath, `/\`):
+		outPath, err = filepath.Abs(g.ModelPkgPath)
+		if err != nil {
+			return "", fmt.Errorf("cannot parse model pkg path: %w", err)
+		}
+	default:
+		outPath = filepath.Join(filepath.Dir(g.OutPath), g.ModelPkgPath)
+	}
+	return outPath + string(os.PathSeparator), nil
 }
 
 func (g *Generator) fillModelPkgPath(filePath string) {
+	if pkgPath, err := resolvePkgPath(filePath); err == nil {
+		g.Config.modelPkgPath = pkgPath
+		return
+	}
 	pkgs, err := packages.Load(&packages.Config{
 		Mode: packages.NeedName,
 		Dir:  filePath,
 	})
 	if err != nil {
-		g.db.Logger.Warn(context.Background(), "parse model pkg path fail: %s", err)
+		g.report(Event{Kind: EventWarning, Message: "parse model pkg path fail", File: filePath, Err: err})
 		return
 	}
 	if len(pkgs) == 0 {
-		g.db.Logger.Warn(context.Background(), "parse model pkg path fail: got 0 packages")
+		g.report(Event{Kind: EventWarning, Message: "parse model pkg path fail: got 0 packages", File: filePath})
 		return
 	}
 	g.Config.modelPkgPath = pkgs[0].PkgPath
 }
 
-// output format and output
-func (g *Generator) output(fileName string, content []byte) error {
-	result, err := imports.Process(fileName, content, nil)
+// resolvePkgPath resolve import path of package in dir from the nearest go.mod, dir need not exist yet
+func resolvePkgPath(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
 	if err != nil {
-		ioutil.WriteFile(fileName, content, 0640)
-		lines := strings.Split(string(content), "\n")
-		errLine, _ := strconv.Atoi(strings.Split(err.Error(), ":")[1])
-		startLine, endLine := errLine-5, errLine+5
-		fmt.Println("Format fail:", errLine, err)
-		if startLine < 0 {
-			startLine = 0
+		return "", err
+	}
+	for modDir := dir; ; modDir = filepath.Dir(modDir) {
+		content, err := os.ReadFile(filepath.Join(modDir, "go.mod"))
+		if err == nil {
+			modPath := modfile.ModulePath(content)
+			if modPath == "" {
+				return "", fmt.Errorf("no module path in %s", filepath.Join(modDir, "go.mod"))
+			}
+			rel, err := filepath.Rel(modDir, dir)
+			if err != nil {
+				return "", err
+			}
+			return path.Join(modPath, filepath.ToSlash(rel)), nil
 		}
-		if endLine > len(lines)-1 {
-			endLine = len(lines) - 1
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
 		}
-		for i := startLine; i <= endLine; i++ {
-			fmt.Println(i, lines[i])
+		if filepath.Dir(modDir) == modDir {
+			return "", fmt.Errorf("go.mod not found for %s", dir)
 		}
-		return fmt.Errorf("cannot format file: %w", err)
 	}
-	return ioutil.WriteFile(fileName, result, 0640)
+}
+
+// output format and output
+func (g *Generator) output(fileName string, content []byte) error {
+	result, err := imports.Process(fileName, content, nil)
+	if err != nil && g.checker != nil {
+		return fmt.Errorf("cannot format file: %w\n%s", err, errContext(content, err))
+	}
+	if err != nil {
+		_ = g.out.WriteFile(fileName, content) // keep the unformatted file for debugging
+		return fmt.Errorf("cannot format file: %w\n%s", err, errContext(content, err))
+	}
+	return g.outputRaw(fileName, result)
+}
+
+// errContext return source lines around the line reported by a format error
+func errContext(content []byte, err error) string {
+	parts := strings.Split(err.Error(), ":")
+	if len(parts) < 2 {
+		return ""
+	}
+	errLine, convErr := strconv.Atoi(parts[1])
+	if convErr != nil {
+		return ""
+	}
+
+	lines := strings.Split(string(content), "\n")
+	startLine, endLine := errLine-5, errLine+5
+	if startLine < 0 {
+		startLine = 0
+	}
+	if endLine > len(lines)-1 {
+		endLine = len(lines) - 1
+	}
+	var b strings.Builder
+	for i := startLine; i <= endLine; i++ {
+		b.WriteString(fmt.Sprintf("%d %s\n", i, lines[i]))
+	}
+	return b.String()
+}
+
+// sortedData return query data ordered by model struct name
+func (g *Generator) sortedData() []*genInfo {
+	names := make([]string, 0, len(g.Data))
+	for name := range g.Data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	result := make([]*genInfo, len(names))
+	for i, name := range names {
+		result[i] = g.Data[name]
+	}
+	return result
+}
+
+// sortedModels return model data ordered by model struct name
+func (g *Generator) sortedModels() []*generate.QueryStructMeta {
+	names := make([]string, 0, len(g.models))
+	for name := range g.models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	result := make([]*generate.QueryStructMeta, len(names))
+	for i, name := range names {
+		result[i] = g.models[name]
+	}
+	return result
 }
 
 func (g *Generator) pushQueryStructMeta(meta *generate.QueryStructMeta) (*genInfo, error) {
@@ -662,5 +966,6 @@ func getImportPkgPaths(data *genInfo) []string {
 	for importPath := range importPathMap {
 		importPkgPaths = append(importPkgPaths, importPath)
 	}
+	sort.Strings(importPkgPaths)
 	return importPkgPaths
 }
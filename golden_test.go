@@ -0,0 +1,126 @@
+package gen
+
+import (
+	"bytes"
+	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+const goldenDir = "testdata/golden"
+
+type goldenCompany struct {
+	ID   int64  `gorm:"primaryKey"`
+	Name string `gorm:"size:64;not null" form:"name"`
+}
+
+func (goldenCompany) TableName() string { return "companies" }
+
+type goldenUser struct {
+	ID        int64          `gorm:"primaryKey"`
+	Name      string         `gorm:"size:32;uniqueIndex" validate:"max=32" form:"name" binding:"required" xml:"name"`
+	Age       int32          `gorm:"index" form:"age" binding:"gte=0"`
+	CompanyID int64          `gorm:"index"`
+	Company   *goldenCompany `gorm:"foreignKey:CompanyID"`
+}
+
+func (goldenUser) TableName() string { return "users" }
+
+// generateGolden generate code from sqlite into outPath/query, return generated files
+func generateGolden(t *testing.T, outPath string) map[string][]byte {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite fail: %s", err)
+	}
+
+	g := NewGenerator(Config{
+		OutPath:           filepath.Join(outPath, "query"),
+		ModelPkgPath:      "query",
+		Mode:              WithDefaultQuery | WithoutContext | WithQueryInterface,
+		FieldWithIndexTag: true,
+		FieldWithTypeTag:  true,
+	})
+	g.UseDB(db)
+	if err = g.LinkModel(goldenCompany{}, goldenUser{}); err != nil {
+		t.Fatalf("link model fail: %s", err)
+	}
+	models, err := g.GenerateAllTableE()
+	if err != nil {
+		t.Fatalf("generate model fail: %s", err)
+	}
+	if err = g.ApplyBasicE(models...); err != nil {
+		t.Fatalf("apply basic fail: %s", err)
+	}
+	if err = g.ExecuteE(); err != nil {
+		t.Fatalf("execute fail: %s", err)
+	}
+
+	files := make(map[string][]byte)
+	err = filepath.WalkDir(filepath.Join(outPath, "query"), func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(outPath, path)
+		files[filepath.ToSlash(rel)], err = os.ReadFile(path)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("read generated files fail: %s", err)
+	}
+	return files
+}
+
+func TestGenerator_Golden(t *testing.T) {
+	first := generateGolden(t, newModuleDir(t))
+	second := generateGolden(t, newModuleDir(t))
+	if len(first) != len(second) {
+		t.Fatalf("generated %d files then %d files", len(first), len(second))
+	}
+	for name, content := range first {
+		if !bytes.Equal(content, second[name]) {
+			t.Errorf("%s is not reproducible between runs", name)
+		}
+	}
+
+	if *update {
+		_ = os.RemoveAll(goldenDir)
+		for name, content := range first {
+			goldenFile := filepath.Join(goldenDir, name+".golden")
+			if err := os.MkdirAll(filepath.Dir(goldenFile), os.ModePerm); err != nil {
+				t.Fatalf("create golden dir fail: %s", err)
+			}
+			if err := os.WriteFile(goldenFile, content, 0644); err != nil {
+				t.Fatalf("write golden file fail: %s", err)
+			}
+		}
+		return
+	}
+
+	golden := 0
+	_ = filepath.WalkDir(goldenDir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			golden++
+		}
+		return err
+	})
+	if golden != len(first) {
+		t.Errorf("expect %d golden files, generated %d files, run go test -update to refresh", golden, len(first))
+	}
+	for name, content := range first {
+		expect, err := os.ReadFile(filepath.Join(goldenDir, name+".golden"))
+		if err != nil {
+			t.Errorf("read golden file of %s fail: %s", name, err)
+			continue
+		}
+		if !bytes.Equal(expect, content) {
+			t.Errorf("%s differs from golden file, run go test -update to refresh", name)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package gen
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io/fs"
+	"path/filepath"
+
+	"gorm.io/gen/field"
+	"gorm.io/gen/internal/generate"
+)
+
+// StateFile name of the incremental generation state written into OutPath
+const StateFile = ".gen-state.json"
+
+const stateVersion = 1
+
+// genState fingerprints of config and models used by last run
+type genState struct {
+	Version int               `json:"version"`
+	Config  string            `json:"config"`
+	Models  map[string]string `json:"models"` // model struct name -> fingerprint
+}
+
+// incremental decide which models can skip rendering
+type incremental struct {
+	prevManifests map[string]*manifest // manifests of last run by output root
+	unchanged     map[string]bool      // model struct name -> fingerprint unchanged since last run
+	state         *genState
+}
+
+func loadState(out Output, path string) (*genState, error) {
+	content, err := out.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &genState{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var s genState
+	if err = json.Unmarshal(content, &s); err != nil {
+		return nil, fmt.Errorf("parse state %s fail: %w", path, err)
+	}
+	return &s, nil
+}
+
+// prepareIncremental fingerprint config and models, compare them with last run
+func (g *Generator) prepareIncremental() error {
+	prevState, err := loadState(g.out, filepath.Join(g.OutPath, StateFile))
+	if err != nil {
+		return err
+	}
+	prevManifests, err := g.loadManifests(g.manifestRoots())
+	if err != nil {
+		return err
+	}
+
+	state := &genState{Version: stateVersion, Config: g.configFingerprint(), Models: make(map[string]string)}
+	skippable := true
+	if reason := g.unskippableReason(); reason != "" {
+		skippable = false
+		g.report(Event{Kind: EventInfo, Message: "render every model, " + reason})
+	}
+	unchanged := make(map[string]bool)
+	for _, meta := range g.allMetas() {
+		var interfaces []*generate.InterfaceMethod
+		if info := g.Data[meta.ModelStructName]; info != nil {
+			interfaces = info.Interfaces
+		}
+		name := meta.ModelStructName
+		state.Models[name] = modelFingerprint(meta, interfaces)
+
+		unchanged[name] = skippable && prevState.Version == stateVersion &&
+			prevState.Config == state.Config &&
+			prevState.Models[name] == state.Models[name]
+	}
+
+	g.incremental = &incremental{prevManifests: prevManifests, unchanged: unchanged, state: state}
+	return nil
+}
+
+// unskippableReason reason why unchanged models can not be skipped, empty if they can:
+// render hooks of plugins expect every file, strategy funcs set by code can not be fingerprinted
+func (g *Generator) unskippableReason() string {
+	for _, p := range g.plugins {
+		if _, ok := p.(RenderHook); ok {
+			return fmt.Sprintf("plugin %s hooks rendering", p.Name())
+		}
+	}
+	hasFunc := g.tableNameNS != nil || g.modelNameNS != nil || g.fileNameNS != nil ||
+		g.fieldJSONTagNS != nil || g.fieldNewTagNS != nil || len(g.dataTypeMap) > 0
+	if hasFunc && g.strategyFingerprint == "" {
+		return "naming, tag or data type strategy funcs can not be fingerprinted"
+	}
+	return ""
+}
+
+// skipUnchanged report whether files of model can be kept as they are.
+// Files are kept only when model is unchanged and every file is on disk as generated last time,
+// kept files are recorded into manifest of current run.
+func (g *Generator) skipUnchanged(name string, files ...string) bool {
+	if g.incremental == nil || g.checker != nil || !g.incremental.unchanged[name] {
+		return false
+	}
+
+	roots := g.manifestRoots()
+	contents := make([][]byte, len(files))
+	for i, fileName := range files {
+		root, rel, ok := manifestEntry(roots, fileName)
+		if !ok {
+			return false
+		}
+		content, err := g.out.ReadFile(fileName)
+		if err != nil || g.incremental.prevManifests[root].Files[rel] != hashContent(content) {
+			return false
+		}
+		contents[i] = content
+	}
+
+	for i, fileName := range files {
+		g.recordFile(fileName, contents[i])
+		g.report(Event{Kind: EventFileSkipped, Message: fmt.Sprintf("skip unchanged model %s: %s", name, fileName), Model: name, File: fileName})
+	}
+	return true
+}
+
+// saveIncrementalState persist fingerprints of current run
+func (g *Generator) saveIncrementalState() error {
+	if g.incremental == nil || g.checker != nil {
+		return nil
+	}
+	content, err := json.MarshalIndent(g.incremental.state, "", "\t")
+	if err != nil {
+		return err
+	}
+	return g.out.WriteFile(filepath.Join(g.OutPath, StateFile), append(content, '\n'))
+}
+
+// configFingerprint fingerprint of config and templates which affect every generated file
+func (g *Generator) configFingerprint() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "mode:%d|pkg:%s|model:%s|out:%s|test:%t|nullable:%t|coverable:%t|signable:%t|index:%t|type:%t|imports:%q\n",
+		g.Mode, g.queryPkgName, g.ModelPkgPath, filepath.Base(g.OutFile), g.WithUnitTest,
+		g.FieldNullable, g.FieldCoverable, g.FieldSignable, g.FieldWithIndexTag, g.FieldWithTypeTag, g.importPkgPaths)
+	for _, name := range TemplateNames() {
+		fmt.Fprintf(h, "template:%s\n%s\n", name, g.template(name))
+	}
+	for _, t := range g.extraModelTemplates {
+		fmt.Fprintf(h, "model template:%s\n%s\n", t.name, t.text)
+	}
+	for _, t := range g.extraPackageTemplates {
+		fmt.Fprintf(h, "package template:%s\n%s\n", t.name, t.text)
+	}
+	for _, p := range g.plugins {
+		fmt.Fprintf(h, "plugin:%s|%T\n", p.Name(), p)
+	}
+	fmt.Fprintf(h, "strategy:%s\n", g.strategyFingerprint)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// modelFingerprint fingerprint of everything rendered for a model
+func modelFingerprint(meta *generate.QueryStructMeta, interfaces []*generate.InterfaceMethod) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "table:%s|model:%s|query:%s|file:%s|source:%d|generated:%t|struct:%+v|imports:%q\n",
+		meta.TableName, meta.ModelStructName, meta.QueryStructName, meta.FileName,
+		meta.Source, meta.Generated, meta.StructInfo, meta.ImportPkgPaths)
+	for _, f := range meta.Fields {
+		plain := *f
+		plain.Relation = nil
+		fmt.Fprintf(h, "field:%+v\n", plain)
+		if f.Relation != nil {
+			writeRelation(h, *f.Relation)
+		}
+	}
+	for _, m := range meta.ModelMethods {
+		fmt.Fprintf(h, "method:%+v\n", *m)
+	}
+	for _, m := range interfaces {
+		fmt.Fprintf(h, "interface:%s.%s|%s|%s|%s|%+v|%+v\n",
+			m.Package, m.InterfaceName, m.MethodName, m.Doc, m.SQLString, m.Params, m.Result)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func writeRelation(h hash.Hash, r field.Relation) {
+	fmt.Fprintf(h, "relation:%s|%s|%s|%s|%s\n", r.Name(), r.Type(), r.Path(), r.Relationship(), r.Key)
+	for _, child := range r.ChildRelations() {
+		writeRelation(h, child)
+	}
+}
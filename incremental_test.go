@@ -0,0 +1,102 @@
+package gen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gorm.io/gen/helper"
+)
+
+func TestGenerator_Incremental(t *testing.T) {
+	outPath := filepath.Join(newModuleDir(t), "query")
+	execute := func(objs ...helper.Object) {
+		g := NewGenerator(Config{OutPath: outPath, ModelPkgPath: "query", Incremental: true, Mode: WithDefaultQuery | WithoutContext | WithQueryInterface})
+		for _, obj := range objs {
+			g.ApplyBasic(g.GenerateModelFrom(obj))
+		}
+		if err := g.ExecuteE(); err != nil {
+			t.Fatalf("execute fail: %s", err)
+		}
+	}
+
+	execute(testUserObject, testCompanyObject)
+	if _, err := os.Stat(filepath.Join(outPath, StateFile)); err != nil {
+		t.Fatalf("expect state file written: %s", err)
+	}
+
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	files := []string{"users.model.gen.go", "users.gen.go", "users/users.gen.go", "companies.model.gen.go", "companies.gen.go"}
+	for _, name := range files {
+		_ = os.Chtimes(filepath.Join(outPath, name), past, past)
+	}
+	_ = os.WriteFile(filepath.Join(outPath, "companies.gen.go"), []byte("package query\n"), 0640)
+	_ = os.Chtimes(filepath.Join(outPath, "companies.gen.go"), past, past)
+
+	execute(testUserObject, testCompanyObject)
+	for _, name := range files {
+		info, err := os.Stat(filepath.Join(outPath, name))
+		if err != nil {
+			t.Fatalf("stat %s fail: %s", name, err)
+		}
+		rewritten := !info.ModTime().Equal(past)
+		if expect := name == "companies.gen.go"; rewritten != expect { // only the file edited by hand is regenerated
+			t.Errorf("expect %s rewritten=%t, got %t", name, expect, rewritten)
+		}
+	}
+	if content, _ := os.ReadFile(filepath.Join(outPath, "companies.gen.go")); string(content) == "package query\n" {
+		t.Errorf("expect companies.gen.go regenerated")
+	}
+	if _, err := os.Stat(filepath.Join(outPath, "users.model.gen.go")); err != nil {
+		t.Errorf("skipped file should be kept in manifest: %s", err)
+	}
+}
+
+func TestGenerator_Incremental_Unskippable(t *testing.T) {
+	for name, setup := range map[string]func(g *Generator){
+		"render hook": func(g *Generator) { g.Use(&testPlugin{kinds: make(map[FileKind]int)}) },
+		"strategy func": func(g *Generator) {
+			g.WithJSONTagNameStrategy(func(columnName string) string { return columnName })
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			outPath := filepath.Join(newModuleDir(t), "query")
+			execute := func() (skipped int) {
+				g := NewGenerator(Config{OutPath: outPath, ModelPkgPath: "query", Incremental: true, Mode: WithDefaultQuery})
+				setup(g)
+				g.WithReporter(ReporterFunc(func(e Event) {
+					if e.Kind == EventFileSkipped {
+						skipped++
+					}
+				}))
+				g.ApplyBasic(g.GenerateModelFrom(testUserObject))
+				if err := g.ExecuteE(); err != nil {
+					t.Fatalf("execute fail: %s", err)
+				}
+				return skipped
+			}
+			execute()
+			if skipped := execute(); skipped != 0 {
+				t.Errorf("expect every file rendered, got %d skipped", skipped)
+			}
+		})
+	}
+}
+
+func TestGenerator_ConfigFingerprint(t *testing.T) {
+	g := NewGenerator(Config{OutPath: "query"})
+	base := g.configFingerprint()
+	g.WithPackageTemplate("extra", "package query\n")
+	if err := g.loadTemplates(); err != nil {
+		t.Fatal(err)
+	}
+	withTemplate := g.configFingerprint()
+	if withTemplate == base {
+		t.Errorf("expect package template in fingerprint")
+	}
+	g.Use(ERDiagram{})
+	if g.configFingerprint() == withTemplate {
+		t.Errorf("expect plugins in fingerprint")
+	}
+}
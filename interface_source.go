@@ -0,0 +1,126 @@
+package gen
+
+import (
+	"fmt"
+	gobuild "go/build"
+	goparser "go/parser"
+	"go/token"
+	"path/filepath"
+
+	"gorm.io/gen/internal/parser"
+)
+
+// InterfaceSource .diy_method interface located by name and source files instead of its type
+type InterfaceSource struct {
+	Name    string   `yaml:"name"`            // interface name
+	Package string   `yaml:"package"`         // import path or directory (./dal/method) of package declaring the interface
+	Files   []string `yaml:"files,omitempty"` // go files declaring the interface, default: go files of Package
+}
+
+// InterfaceConf DIY method interface and tables it is applied to
+type InterfaceConf struct {
+	InterfaceSource `yaml:",inline"`
+	Tables          []string `yaml:"tables,omitempty"` // tables applied to, glob or /regexp/ rules are supported, empty for all generated tables
+}
+
+// ApplyInterfaceConfE apply DIY method interfaces to models of matched tables
+func (g *Generator) ApplyInterfaceConfE(confs []InterfaceConf, models ...interface{}) error {
+	tables := make([]string, 0, len(models))
+	byTable := make(map[string]interface{}, len(models))
+	for _, m := range models {
+		meta, ok := m.(*QueryStructMeta)
+		if !ok {
+			return fmt.Errorf("interface config can only be applied to generated models, got %T", m)
+		}
+		tables = append(tables, meta.TableName)
+		byTable[meta.TableName] = m
+	}
+
+	for _, conf := range confs {
+		selected, err := TableFilter{Include: conf.Tables}.Filter(tables, nil)
+		if err != nil {
+			return fmt.Errorf("interface %s: %w", conf.Name, err)
+		}
+
+		applied := make([]interface{}, 0, len(selected))
+		for _, table := range selected {
+			if m, ok := byTable[table]; ok {
+				applied = append(applied, m)
+			}
+		}
+		if len(applied) == 0 {
+			continue
+		}
+		if err = g.ApplyInterfaceSourceE([]InterfaceSource{conf.InterfaceSource}, applied...); err != nil {
+			return fmt.Errorf("apply interface %s fail: %w", conf.Name, err)
+		}
+	}
+	return nil
+}
+
+// SourceFiles return go files declaring the interface
+func (s InterfaceSource) SourceFiles() ([]string, error) {
+	path, err := s.interfacePath()
+	if err != nil {
+		return nil, err
+	}
+	return path.Files, nil
+}
+
+// interfacePath locate package name and files of interface
+func (s InterfaceSource) interfacePath() (*parser.InterfacePath, error) {
+	if s.Name == "" {
+		return nil, fmt.Errorf("interface name cannot be empty")
+	}
+
+	var pkgName string
+	files := s.Files
+	switch {
+	case s.Package != "":
+		var pkg *gobuild.Package
+		var err error
+		if gobuild.IsLocalImport(s.Package) || filepath.IsAbs(s.Package) {
+			pkg, err = gobuild.ImportDir(s.Package, gobuild.ImportComment)
+		} else {
+			pkg, err = gobuild.Import(s.Package, ".", gobuild.ImportComment)
+		}
+		if err != nil {
+			return nil, err
+		}
+		pkgName = pkg.Name
+		if len(files) == 0 {
+			for _, file := range pkg.GoFiles {
+				files = append(files, filepath.Join(pkg.Dir, file))
+			}
+		}
+	case len(files) > 0:
+		f, err := goparser.ParseFile(token.NewFileSet(), files[0], nil, goparser.PackageClauseOnly)
+		if err != nil {
+			return nil, err
+		}
+		pkgName = f.Name.Name
+	default:
+		return nil, fmt.Errorf("package or files of interface must be specified")
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no go file found in %s", s.Package)
+	}
+	return &parser.InterfacePath{Name: s.Name, FullName: pkgName + "." + s.Name, Files: files}, nil
+}
+
+// checkInterfaceFound make sure every interface is declared in its files
+func checkInterfaceFound(paths []*parser.InterfacePath, set *parser.InterfaceSet) error {
+	for _, path := range paths {
+		found := false
+		for _, info := range set.Interfaces {
+			if info.Name == path.Name && info.Package == path.FullName {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("interface %s not found in %v", path.FullName, path.Files)
+		}
+	}
+	return nil
+}
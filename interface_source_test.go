@@ -0,0 +1,143 @@
+package gen
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+const testQuerierSource = `package method
+
+import "gorm.io/gen"
+
+type Querier interface {
+	// SELECT * FROM @@table WHERE name = @name
+	FindByName(name string) (gen.T, error)
+}
+`
+
+func TestGenerator_ApplyInterfaceSource(t *testing.T) {
+	dir := newModuleDir(t)
+	methodDir := filepath.Join(dir, "method")
+	if err := os.MkdirAll(methodDir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(methodDir, "querier.go"), []byte(testQuerierSource), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(dir, "diy.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite fail: %s", err)
+	}
+	if err = db.Exec("CREATE TABLE users (id integer primary key, name text)").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	out := NewMemoryOutput()
+	g := NewGenerator(Config{OutPath: filepath.Join(dir, "query"), ModelPkgPath: "query", Mode: WithDefaultQuery})
+	g.WithOutput(out)
+	g.UseDB(db)
+
+	user, err := g.GenerateModelE("users")
+	if err != nil {
+		t.Fatalf("generate model fail: %s", err)
+	}
+	if err = g.ApplyInterfaceSourceE([]InterfaceSource{{Name: "Missing", Package: methodDir}}, user); err == nil {
+		t.Errorf("expect error of missing interface")
+	}
+	if err = g.ApplyInterfaceSourceE([]InterfaceSource{{Name: "Querier", Files: []string{filepath.Join(methodDir, "querier.go")}}}, user); err != nil {
+		t.Fatalf("apply interface source fail: %s", err)
+	}
+	if err = g.ExecuteE(); err != nil {
+		t.Fatalf("execute fail: %s", err)
+	}
+
+	var found bool
+	for _, name := range out.Names() {
+		content, _ := out.ReadFile(name)
+		if strings.Contains(string(content), "func (u userDo) FindByName(name string)") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("FindByName not generated in %v", out.Names())
+	}
+}
+
+const testApplyMainSource = `package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"gorm.io/gen"
+)
+
+type Querier interface {
+	// SELECT * FROM @@table WHERE name = @name
+	FindByName(name string) (gen.T, error)
+}
+
+type CommonMethod struct {
+	ID   int32
+	Name *string
+}
+
+func (m *CommonMethod) IsEmpty() bool { return m == nil }
+
+func main() {
+	dir := os.Args[1]
+	db, err := gorm.Open(sqlite.Open(filepath.Join(dir, "main.db")), &gorm.Config{})
+	if err != nil {
+		panic(err)
+	}
+	if err = db.Exec("CREATE TABLE users (id integer primary key, name text)").Error; err != nil {
+		panic(err)
+	}
+	g := gen.NewGenerator(gen.Config{OutPath: filepath.Join(dir, "query")})
+	g.UseDB(db)
+	g.ApplyInterface(func(Querier) {}, g.GenerateModel("users", gen.WithMethod(CommonMethod{})))
+	if err = g.ApplyInterfaceE(func(Querier) {}, g.GenerateModelAs("users", "Member")); err != nil {
+		panic(err)
+	}
+	fmt.Println("ok")
+}
+`
+
+// interfaces and methods declared in package main are read from directory of the caller, not of gen
+func TestGenerator_ApplyInterface_MainPackage(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds and runs a main package")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+
+	// inside the module, so the program builds against this tree; "_" keeps it out of ./...
+	mainDir, err := os.MkdirTemp(".", "_apply_main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(mainDir)
+	if err = os.WriteFile(filepath.Join(mainDir, "main.go"), []byte(testApplyMainSource), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(goBin, "run", ".", t.TempDir())
+	cmd.Dir = mainDir
+	output, err := cmd.CombinedOutput()
+	if err != nil || !strings.Contains(string(output), "ok") {
+		t.Fatalf("apply interface of package main fail: %v\n%s", err, output)
+	}
+}
@@ -0,0 +1,854 @@
+// Package ddl parse CREATE TABLE, CREATE INDEX, ALTER TABLE and COMMENT ON statements of
+// mysql and postgres into tables, so models can be generated without database
+package ddl
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// dialects supported
+const (
+	MySQL    = "mysql"
+	Postgres = "postgres"
+)
+
+// Schema tables parsed from ddl
+type Schema struct {
+	Dialect string
+	Tables  []*Table
+}
+
+// Table table parsed from ddl
+type Table struct {
+	Name        string
+	Comment     string
+	Columns     []*Column
+	Indexes     []*Index
+	ForeignKeys []*ForeignKey
+}
+
+// Column column parsed from ddl, DataType and ColumnType are reported as the database does
+type Column struct {
+	Name          string
+	DataType      string // e.g. varchar, int8
+	ColumnType    string // e.g. varchar(64), bigint unsigned
+	Length        int64  // length of char types, 0 if not specified
+	Precision     int64  // precision of decimal types, 0 if not specified
+	Scale         int64
+	Nullable      bool
+	PrimaryKey    bool
+	Unique        bool
+	AutoIncrement bool
+	Default       *string // nil if no default value
+	Comment       string
+}
+
+// Index index parsed from ddl
+type Index struct {
+	Name    string
+	Columns []string
+	Primary bool
+	Unique  bool
+}
+
+// ForeignKey foreign key constraint parsed from ddl
+type ForeignKey struct {
+	Name       string
+	Columns    []string
+	RefTable   string
+	RefColumns []string
+}
+
+// NewSchema create empty schema of dialect
+func NewSchema(dialect string) (*Schema, error) {
+	switch dialect {
+	case MySQL, Postgres:
+		return &Schema{Dialect: dialect}, nil
+	default:
+		return nil, fmt.Errorf("unsupported ddl dialect %q (support %s || %s)", dialect, MySQL, Postgres)
+	}
+}
+
+// Table return table by name, nil if not exists
+func (s *Schema) Table(name string) *Table {
+	for _, t := range s.Tables {
+		if t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// Column return column by name, nil if not exists
+func (t *Table) Column(name string) *Column {
+	for _, c := range t.Columns {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// Parse parse statements of sql into schema, unsupported statements are ignored
+func (s *Schema) Parse(sql string) error {
+	toks, err := tokenize(sql, s.Dialect == MySQL)
+	if err != nil {
+		return err
+	}
+	for len(toks) > 0 {
+		end := 0
+		for end < len(toks) && !(toks[end].kind == tokPunct && toks[end].text == ";") {
+			end++
+		}
+		if end > 0 {
+			p := &parser{toks: toks[:end], schema: s}
+			if err = p.statement(); err != nil {
+				return fmt.Errorf("statement %q: %w", p.summary(), err)
+			}
+		}
+		if end < len(toks) {
+			end++
+		}
+		toks = toks[end:]
+	}
+	return nil
+}
+
+type parser struct {
+	toks   []token
+	pos    int
+	schema *Schema
+}
+
+func (p *parser) summary() string {
+	parts := make([]string, 0, 6)
+	for _, t := range p.toks[:min(len(p.toks), 6)] {
+		parts = append(parts, t.raw())
+	}
+	return strings.Join(parts, " ")
+}
+
+func (p *parser) eof() bool { return p.pos >= len(p.toks) }
+
+func (p *parser) peek() token {
+	if p.eof() {
+		return token{kind: tokPunct}
+	}
+	return p.toks[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+// accept consume keywords if all of them are next
+func (p *parser) accept(keywords ...string) bool {
+	for i, kw := range keywords {
+		if p.pos+i >= len(p.toks) || !p.toks[p.pos+i].is(kw) {
+			return false
+		}
+	}
+	p.pos += len(keywords)
+	return true
+}
+
+func (p *parser) acceptPunct(punct string) bool {
+	if t := p.peek(); t.kind == tokPunct && t.text == punct {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectPunct(punct string) error {
+	if !p.acceptPunct(punct) {
+		return fmt.Errorf("expect %q, got %q", punct, p.peek().raw())
+	}
+	return nil
+}
+
+// name read possibly qualified name, return the last part
+func (p *parser) name() (string, error) {
+	t := p.next()
+	if t.kind != tokWord && t.kind != tokQuoted {
+		return "", fmt.Errorf("expect name, got %q", t.raw())
+	}
+	name := t.text
+	for p.acceptPunct(".") {
+		t = p.next()
+		if t.kind != tokWord && t.kind != tokQuoted {
+			return "", fmt.Errorf("expect name, got %q", t.raw())
+		}
+		name = t.text
+	}
+	return name, nil
+}
+
+// group return tokens in parentheses split by top level commas, the opening parenthesis must be next
+func (p *parser) group() ([][]token, error) {
+	if err := p.expectPunct("("); err != nil {
+		return nil, err
+	}
+	var items [][]token
+	start, depth := p.pos, 0
+	for ; !p.eof(); p.pos++ {
+		t := p.toks[p.pos]
+		if t.kind != tokPunct {
+			continue
+		}
+		switch t.text {
+		case "(":
+			depth++
+		case ")":
+			if depth == 0 {
+				items = append(items, p.toks[start:p.pos])
+				p.pos++
+				return items, nil
+			}
+			depth--
+		case ",":
+			if depth == 0 {
+				items = append(items, p.toks[start:p.pos])
+				start = p.pos + 1
+			}
+		}
+	}
+	return nil, fmt.Errorf("unclosed parenthesis")
+}
+
+// skipGroup skip tokens in parentheses if the opening parenthesis is next
+func (p *parser) skipGroup() error {
+	if t := p.peek(); t.kind != tokPunct || t.text != "(" {
+		return nil
+	}
+	_, err := p.group()
+	return err
+}
+
+func (p *parser) statement() error {
+	switch {
+	case p.accept("CREATE"):
+		p.accept("OR", "REPLACE")
+		for p.accept("TEMPORARY") || p.accept("TEMP") || p.accept("UNLOGGED") || p.accept("GLOBAL") || p.accept("LOCAL") {
+		}
+		switch {
+		case p.accept("TABLE"):
+			return p.createTable()
+		case p.accept("UNIQUE", "INDEX"):
+			return p.createIndex(true)
+		case p.accept("INDEX"):
+			return p.createIndex(false)
+		}
+	case p.accept("ALTER", "TABLE"):
+		return p.alterTable()
+	case p.accept("COMMENT", "ON"):
+		return p.commentOn()
+	}
+	return nil // other statements are ignored
+}
+
+func (p *parser) createTable() error {
+	p.accept("IF", "NOT", "EXISTS")
+	name, err := p.name()
+	if err != nil {
+		return err
+	}
+	if t := p.peek(); t.kind != tokPunct || t.text != "(" {
+		return nil // CREATE TABLE ... AS / LIKE / PARTITION OF are ignored
+	}
+	items, err := p.group()
+	if err != nil {
+		return err
+	}
+
+	table := &Table{Name: name}
+	for _, item := range items {
+		if err = p.sub(item).tableElement(table); err != nil {
+			return err
+		}
+	}
+	p.tableOptions(table)
+
+	if old := p.schema.Table(name); old != nil {
+		*old = *table
+		return nil
+	}
+	p.schema.Tables = append(p.schema.Tables, table)
+	return nil
+}
+
+func (p *parser) sub(toks []token) *parser {
+	return &parser{toks: toks, schema: p.schema}
+}
+
+// tableOptions read mysql table options, only comment is kept
+func (p *parser) tableOptions(table *Table) {
+	for !p.eof() {
+		if p.accept("COMMENT") {
+			p.acceptPunct("=")
+			if t := p.next(); t.kind == tokString {
+				table.Comment = t.text
+			}
+			continue
+		}
+		p.next()
+	}
+}
+
+// tableElement parse column definition or table constraint
+func (p *parser) tableElement(table *Table) error {
+	if p.eof() {
+		return nil
+	}
+	var constraint string
+	if p.accept("CONSTRAINT") {
+		if t := p.peek(); !t.is("PRIMARY") && !t.is("UNIQUE") && !t.is("FOREIGN") && !t.is("CHECK") {
+			p.next()
+			constraint = p.toks[p.pos-1].text
+		}
+	}
+
+	switch t := p.peek(); {
+	case t.is("PRIMARY"), t.is("UNIQUE"), t.is("FOREIGN"), t.is("CHECK"), t.is("EXCLUDE"),
+		t.is("KEY"), t.is("INDEX"), t.is("FULLTEXT"), t.is("SPATIAL"):
+		return p.constraint(table, constraint)
+	}
+	return p.column(table)
+}
+
+// constraint parse table constraint or mysql inline index
+func (p *parser) constraint(table *Table, name string) error {
+	switch {
+	case p.accept("PRIMARY", "KEY"):
+		columns, err := p.indexColumns()
+		if err != nil {
+			return err
+		}
+		p.addIndex(table, &Index{Name: name, Columns: columns, Primary: true, Unique: true})
+	case p.accept("UNIQUE"):
+		_ = p.accept("KEY") || p.accept("INDEX")
+		if name == "" && p.peek().kind != tokPunct {
+			name = p.next().text
+		}
+		columns, err := p.indexColumns()
+		if err != nil {
+			return err
+		}
+		p.addIndex(table, &Index{Name: name, Columns: columns, Unique: true})
+	case p.accept("FOREIGN", "KEY"):
+		if p.peek().kind != tokPunct {
+			p.next() // mysql index name
+		}
+		columns, err := p.indexColumns()
+		if err != nil {
+			return err
+		}
+		fk := &ForeignKey{Name: name, Columns: columns}
+		if err = p.references(fk); err != nil {
+			return err
+		}
+		table.ForeignKeys = append(table.ForeignKeys, fk)
+	case p.accept("KEY") || p.accept("INDEX") || p.accept("FULLTEXT") || p.accept("SPATIAL"):
+		_ = p.accept("KEY") || p.accept("INDEX")
+		if t := p.peek(); t.kind != tokPunct {
+			name = p.next().text
+		}
+		columns, err := p.indexColumns()
+		if err != nil {
+			return err
+		}
+		p.addIndex(table, &Index{Name: name, Columns: columns})
+	}
+	return nil // CHECK and EXCLUDE constraints are ignored
+}
+
+// indexColumns read column list of index, nil for expression index
+func (p *parser) indexColumns() ([]string, error) {
+	p.accept("USING", "BTREE")
+	p.accept("USING", "HASH")
+	items, err := p.group()
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]string, 0, len(items))
+	for _, item := range items {
+		if len(item) == 0 || (item[0].kind != tokWord && item[0].kind != tokQuoted) {
+			return nil, nil
+		}
+		// column name may be followed by prefix length, order or operator class, but not by a call
+		if len(item) > 1 && item[1].kind == tokPunct && item[1].text == "(" && item[0].kind == tokWord && p.schema.Dialect == Postgres {
+			return nil, nil
+		}
+		columns = append(columns, item[0].text)
+	}
+	return columns, nil
+}
+
+// references read REFERENCES clause into fk
+func (p *parser) references(fk *ForeignKey) error {
+	if !p.accept("REFERENCES") {
+		return fmt.Errorf("expect REFERENCES, got %q", p.peek().raw())
+	}
+	refTable, err := p.name()
+	if err != nil {
+		return err
+	}
+	fk.RefTable = refTable
+	if t := p.peek(); t.kind == tokPunct && t.text == "(" {
+		if fk.RefColumns, err = p.indexColumns(); err != nil {
+			return err
+		}
+	}
+	for !p.eof() && !p.peek().is("CONSTRAINT") { // MATCH, ON DELETE, ON UPDATE, DEFERRABLE ...
+		if p.peek().is("NOT") || p.peek().is("NULL") || p.peek().is("DEFAULT") || p.peek().is("COMMENT") {
+			break
+		}
+		p.next()
+	}
+	return nil
+}
+
+// addIndex add index, primary key columns are marked
+func (p *parser) addIndex(table *Table, idx *Index) {
+	if len(idx.Columns) == 0 {
+		return
+	}
+	if idx.Name == "" {
+		idx.Name = p.defaultIndexName(table.Name, idx)
+	}
+	if idx.Primary {
+		for _, name := range idx.Columns {
+			if c := table.Column(name); c != nil {
+				c.PrimaryKey, c.Nullable = true, false
+			}
+		}
+	}
+	if idx.Unique && len(idx.Columns) == 1 {
+		if c := table.Column(idx.Columns[0]); c != nil {
+			c.Unique = true
+		}
+	}
+	for i, old := range table.Indexes {
+		if old.Name == idx.Name {
+			table.Indexes[i] = idx
+			return
+		}
+	}
+	table.Indexes = append(table.Indexes, idx)
+}
+
+// defaultIndexName name of index created without name, as the database does
+func (p *parser) defaultIndexName(table string, idx *Index) string {
+	if p.schema.Dialect == MySQL {
+		if idx.Primary {
+			return "PRIMARY"
+		}
+		return idx.Columns[0]
+	}
+	switch {
+	case idx.Primary:
+		return table + "_pkey"
+	case idx.Unique:
+		return table + "_" + strings.Join(idx.Columns, "_") + "_key"
+	default:
+		return table + "_" + strings.Join(idx.Columns, "_") + "_idx"
+	}
+}
+
+// column parse column definition
+func (p *parser) column(table *Table) error {
+	name, err := p.name()
+	if err != nil {
+		return err
+	}
+	col := &Column{Name: name, Nullable: true}
+	if err = p.columnType(col); err != nil {
+		return fmt.Errorf("column %s: %w", name, err)
+	}
+	table.Columns = append(table.Columns, col)
+
+	for !p.eof() {
+		switch {
+		case p.accept("NOT", "NULL"):
+			col.Nullable = false
+		case p.accept("NULL"):
+			col.Nullable = true
+		case p.accept("DEFAULT"):
+			p.defaultValue(col)
+		case p.accept("AUTO_INCREMENT"), p.accept("AUTOINCREMENT"):
+			col.AutoIncrement = true
+		case p.accept("PRIMARY", "KEY"):
+			p.addIndex(table, &Index{Columns: []string{name}, Primary: true, Unique: true})
+		case p.accept("UNIQUE"):
+			p.accept("KEY")
+			p.addIndex(table, &Index{Columns: []string{name}, Unique: true})
+		case p.accept("COMMENT"):
+			if t := p.next(); t.kind == tokString {
+				col.Comment = t.text
+			}
+		case p.peek().is("REFERENCES"):
+			fk := &ForeignKey{Columns: []string{name}}
+			if err = p.references(fk); err != nil {
+				return err
+			}
+			table.ForeignKeys = append(table.ForeignKeys, fk)
+		case p.accept("GENERATED"):
+			p.accept("ALWAYS")
+			p.accept("BY", "DEFAULT")
+			if p.accept("AS", "IDENTITY") {
+				col.AutoIncrement = true
+			} else {
+				p.accept("AS")
+			}
+			if err = p.skipGroup(); err != nil {
+				return err
+			}
+		case p.accept("ON", "UPDATE"):
+			p.next()
+			if err = p.skipGroup(); err != nil {
+				return err
+			}
+		case p.accept("CHARACTER", "SET"), p.accept("CHARSET"), p.accept("COLLATE"), p.accept("CONSTRAINT"):
+			p.next()
+		case p.accept("CHECK"):
+			if err = p.skipGroup(); err != nil {
+				return err
+			}
+		default:
+			p.next()
+		}
+	}
+	if col.PrimaryKey {
+		col.Nullable = false
+	}
+	return nil
+}
+
+// typeWords words continuing a type name, e.g. double precision, timestamp with time zone
+var typeWords = map[string]bool{
+	"precision": true, "varying": true, "with": true, "without": true, "time": true, "zone": true,
+	"unsigned": true, "signed": true, "zerofill": true,
+}
+
+// columnType read data type of column
+func (p *parser) columnType(col *Column) error {
+	t := p.next()
+	if t.kind != tokWord && t.kind != tokQuoted {
+		return fmt.Errorf("expect data type, got %q", t.raw())
+	}
+	words := []string{strings.ToLower(t.text)}
+	for p.acceptPunct(".") { // schema qualified type
+		words = []string{strings.ToLower(p.next().text)}
+	}
+
+	var args []string
+	var array bool
+	for !p.eof() {
+		switch t := p.peek(); {
+		case t.kind == tokPunct && t.text == "(" && args == nil:
+			items, err := p.group()
+			if err != nil {
+				return err
+			}
+			args = make([]string, 0, len(items))
+			for _, item := range items {
+				args = append(args, joinTokens(item))
+			}
+		case t.kind == tokPunct && t.text == "[":
+			p.next()
+			if p.peek().kind == tokWord {
+				p.next()
+			}
+			if err := p.expectPunct("]"); err != nil {
+				return err
+			}
+			array = true
+		case t.kind == tokWord && typeWords[strings.ToLower(t.text)] &&
+			(!t.is("time") || words[len(words)-1] == "with" || words[len(words)-1] == "without"):
+			p.next()
+			words = append(words, strings.ToLower(t.text))
+		default:
+			normalizeType(p.schema.Dialect, col, words, args, array)
+			return nil
+		}
+	}
+	normalizeType(p.schema.Dialect, col, words, args, array)
+	return nil
+}
+
+// defaultValue read default expression of column
+func (p *parser) defaultValue(col *Column) {
+	start := p.pos
+	depth := 0
+	for !p.eof() {
+		t := p.peek()
+		if depth == 0 && t.kind == tokWord && isColumnKeyword(t.text) && p.pos > start {
+			break
+		}
+		if t.kind == tokPunct && t.text == "(" {
+			depth++
+		}
+		if t.kind == tokPunct && t.text == ")" {
+			depth--
+		}
+		p.next()
+	}
+	expr := p.toks[start:p.pos]
+	switch {
+	case len(expr) == 0, len(expr) == 1 && expr[0].is("NULL"):
+		return
+	case expr[0].kind == tokString:
+		value := expr[0].text
+		col.Default = &value
+	case expr[0].is("nextval"):
+		col.AutoIncrement = true
+	default:
+		value := joinTokens(expr)
+		if i := strings.Index(value, "::"); i > 0 && p.schema.Dialect == Postgres && !strings.Contains(value, "(") {
+			value = value[:i]
+		}
+		col.Default = &value
+	}
+}
+
+// isColumnKeyword report whether word starts a column constraint
+func isColumnKeyword(word string) bool {
+	switch strings.ToUpper(word) {
+	case "NOT", "NULL", "DEFAULT", "PRIMARY", "UNIQUE", "AUTO_INCREMENT", "AUTOINCREMENT", "COMMENT",
+		"REFERENCES", "CHECK", "CONSTRAINT", "COLLATE", "CHARACTER", "CHARSET", "ON", "GENERATED":
+		return true
+	}
+	return false
+}
+
+// joinTokens join tokens back into sql text
+func joinTokens(toks []token) string {
+	var b strings.Builder
+	for i, t := range toks {
+		if i > 0 && t.kind != tokPunct && toks[i-1].kind != tokPunct {
+			b.WriteByte(' ')
+		}
+		b.WriteString(t.raw())
+	}
+	return b.String()
+}
+
+// normalizeType fill data type, column type, length and precision of col as the database reports them
+func normalizeType(dialect string, col *Column, words, args []string, array bool) {
+	name := strings.Join(words, " ")
+	var mods []string
+	for _, w := range []string{"unsigned", "signed", "zerofill"} {
+		if strings.HasSuffix(name, " "+w) || strings.Contains(name, " "+w+" ") {
+			name = strings.TrimSpace(strings.Replace(name, " "+w, "", 1))
+			if w != "signed" {
+				mods = append(mods, w)
+			}
+		}
+	}
+
+	if dialect == MySQL {
+		dataType := mysqlAliases[name]
+		if dataType == "" {
+			dataType = name
+		}
+		if (name == "bool" || name == "boolean") && args == nil {
+			args = []string{"1"}
+		}
+		col.DataType = dataType
+		col.ColumnType = strings.Join(append([]string{dataType + joinArgs(args)}, mods...), " ")
+	} else {
+		dataType := postgresAliases[name]
+		if dataType == "" {
+			dataType = name
+		}
+		switch dataType {
+		case "serial", "serial4":
+			dataType, col.AutoIncrement = "int4", true
+		case "bigserial", "serial8":
+			dataType, col.AutoIncrement = "int8", true
+		case "smallserial", "serial2":
+			dataType, col.AutoIncrement = "int2", true
+		}
+		if array {
+			dataType = "_" + dataType
+		}
+		col.DataType = dataType
+		col.ColumnType = dataType + joinArgs(args)
+	}
+
+	if len(args) > 0 {
+		first, _ := strconv.ParseInt(args[0], 10, 64)
+		switch col.DataType {
+		case "decimal", "numeric", "float", "double":
+			col.Precision = first
+			if len(args) > 1 {
+				col.Scale, _ = strconv.ParseInt(args[1], 10, 64)
+			}
+		case "char", "varchar", "binary", "varbinary", "bpchar", "bit", "varbit":
+			col.Length = first
+		}
+	}
+}
+
+func joinArgs(args []string) string {
+	if args == nil {
+		return ""
+	}
+	return "(" + strings.Join(args, ",") + ")"
+}
+
+var mysqlAliases = map[string]string{
+	"integer":           "int",
+	"bool":              "tinyint",
+	"boolean":           "tinyint",
+	"dec":               "decimal",
+	"numeric":           "decimal",
+	"fixed":             "decimal",
+	"double precision":  "double",
+	"real":              "double",
+	"character":         "char",
+	"character varying": "varchar",
+}
+
+var postgresAliases = map[string]string{
+	"bigint":                      "int8",
+	"integer":                     "int4",
+	"int":                         "int4",
+	"smallint":                    "int2",
+	"boolean":                     "bool",
+	"real":                        "float4",
+	"double precision":            "float8",
+	"float":                       "float8",
+	"decimal":                     "numeric",
+	"character varying":           "varchar",
+	"character":                   "bpchar",
+	"char":                        "bpchar",
+	"bit varying":                 "varbit",
+	"time without time zone":      "time",
+	"time with time zone":         "timetz",
+	"timestamp without time zone": "timestamp",
+	"timestamp with time zone":    "timestamptz",
+}
+
+// createIndex parse CREATE [UNIQUE] INDEX, expression indexes are ignored
+func (p *parser) createIndex(unique bool) error {
+	p.accept("CONCURRENTLY")
+	p.accept("IF", "NOT", "EXISTS")
+	var name string
+	if !p.peek().is("ON") {
+		var err error
+		if name, err = p.name(); err != nil {
+			return err
+		}
+	}
+	p.accept("USING", "BTREE")
+	p.accept("USING", "HASH")
+	if !p.accept("ON") {
+		return fmt.Errorf("expect ON, got %q", p.peek().raw())
+	}
+	p.accept("ONLY")
+	tableName, err := p.name()
+	if err != nil {
+		return err
+	}
+	if p.accept("USING") {
+		p.next()
+	}
+	columns, err := p.indexColumns()
+	if err != nil {
+		return err
+	}
+	table := p.schema.Table(tableName)
+	if table == nil {
+		return fmt.Errorf("table %s not found", tableName)
+	}
+	p.addIndex(table, &Index{Name: name, Columns: columns, Unique: unique})
+	return nil
+}
+
+// alterTable parse ALTER TABLE ... ADD, other alterations are ignored
+func (p *parser) alterTable() error {
+	p.accept("IF", "EXISTS")
+	p.accept("ONLY")
+	tableName, err := p.name()
+	if err != nil {
+		return err
+	}
+	table := p.schema.Table(tableName)
+	if table == nil {
+		return fmt.Errorf("table %s not found", tableName)
+	}
+
+	var actions [][]token
+	start, depth := p.pos, 0
+	for ; p.pos <= len(p.toks); p.pos++ {
+		if p.pos == len(p.toks) {
+			actions = append(actions, p.toks[start:p.pos])
+			break
+		}
+		if t := p.toks[p.pos]; t.kind == tokPunct {
+			switch {
+			case t.text == "(":
+				depth++
+			case t.text == ")":
+				depth--
+			case t.text == "," && depth == 0:
+				actions = append(actions, p.toks[start:p.pos])
+				start = p.pos + 1
+			}
+		}
+	}
+	for _, action := range actions {
+		sub := p.sub(action)
+		if !sub.accept("ADD") {
+			continue
+		}
+		if sub.accept("COLUMN") {
+			sub.accept("IF", "NOT", "EXISTS")
+			err = sub.column(table)
+		} else {
+			err = sub.tableElement(table)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// commentOn parse COMMENT ON TABLE|COLUMN ... IS '...'
+func (p *parser) commentOn() error {
+	switch {
+	case p.accept("TABLE"):
+		name, err := p.name()
+		if err != nil {
+			return err
+		}
+		if table := p.schema.Table(name); table != nil && p.accept("IS") {
+			table.Comment = p.next().text
+		}
+	case p.accept("COLUMN"):
+		var parts []string
+		for {
+			t := p.next()
+			parts = append(parts, t.text)
+			if !p.acceptPunct(".") {
+				break
+			}
+		}
+		if len(parts) < 2 || !p.accept("IS") {
+			return nil
+		}
+		comment := p.next()
+		if table := p.schema.Table(parts[len(parts)-2]); table != nil {
+			if col := table.Column(parts[len(parts)-1]); col != nil && comment.kind == tokString {
+				col.Comment = comment.text
+			}
+		}
+	}
+	return nil
+}
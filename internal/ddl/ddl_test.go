@@ -0,0 +1,171 @@
+package ddl
+
+import (
+	"reflect"
+	"testing"
+)
+
+const mysqlDDL = `
+-- users of shop
+CREATE TABLE IF NOT EXISTS ` + "`users`" + ` (
+  ` + "`id`" + ` bigint unsigned NOT NULL AUTO_INCREMENT,
+  ` + "`name`" + ` varchar(64) NOT NULL DEFAULT '' COMMENT 'user''s name',
+  ` + "`age`" + ` int DEFAULT NULL,
+  ` + "`balance`" + ` decimal(10,2) NOT NULL DEFAULT '0.00',
+  ` + "`is_admin`" + ` boolean NOT NULL DEFAULT 0,
+  ` + "`created_at`" + ` datetime(3) DEFAULT CURRENT_TIMESTAMP(3) ON UPDATE CURRENT_TIMESTAMP(3),
+  PRIMARY KEY (` + "`id`" + `),
+  UNIQUE KEY ` + "`idx_name`" + ` (` + "`name`" + `(32)),
+  KEY ` + "`idx_age_created`" + ` (` + "`age`" + `, ` + "`created_at`" + ` DESC) USING BTREE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='users; of shop';
+
+/* orders */
+CREATE TABLE orders (
+  id int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  user_id bigint unsigned NOT NULL,
+  CONSTRAINT fk_orders_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+CREATE INDEX idx_orders_user ON orders (user_id);
+INSERT INTO orders VALUES (1, 1);
+`
+
+func TestSchema_Parse_MySQL(t *testing.T) {
+	s, _ := NewSchema(MySQL)
+	if err := s.Parse(mysqlDDL); err != nil {
+		t.Fatalf("parse fail: %s", err)
+	}
+	if len(s.Tables) != 2 {
+		t.Fatalf("expect 2 tables, got %d", len(s.Tables))
+	}
+
+	users := s.Table("users")
+	if users.Comment != "users; of shop" {
+		t.Errorf("unexpected table comment %q", users.Comment)
+	}
+	id := users.Column("id")
+	if id.DataType != "bigint" || id.ColumnType != "bigint unsigned" || !id.PrimaryKey || !id.AutoIncrement || id.Nullable {
+		t.Errorf("unexpected id column %+v", id)
+	}
+	name := users.Column("name")
+	if name.ColumnType != "varchar(64)" || name.Length != 64 || name.Default == nil || *name.Default != "" || name.Comment != "user's name" {
+		t.Errorf("unexpected name column %+v", name)
+	}
+	if age := users.Column("age"); !age.Nullable || age.Default != nil {
+		t.Errorf("unexpected age column %+v", age)
+	}
+	if balance := users.Column("balance"); balance.DataType != "decimal" || balance.Precision != 10 || balance.Scale != 2 {
+		t.Errorf("unexpected balance column %+v", balance)
+	}
+	if admin := users.Column("is_admin"); admin.ColumnType != "tinyint(1)" || *admin.Default != "0" {
+		t.Errorf("unexpected is_admin column %+v", admin)
+	}
+	if created := users.Column("created_at"); created.ColumnType != "datetime(3)" || *created.Default != "CURRENT_TIMESTAMP(3)" {
+		t.Errorf("unexpected created_at column %+v", created)
+	}
+
+	expectIndexes := []Index{
+		{Name: "PRIMARY", Columns: []string{"id"}, Primary: true, Unique: true},
+		{Name: "idx_name", Columns: []string{"name"}, Unique: true},
+		{Name: "idx_age_created", Columns: []string{"age", "created_at"}},
+	}
+	for i, idx := range users.Indexes {
+		if i >= len(expectIndexes) || !reflect.DeepEqual(*idx, expectIndexes[i]) {
+			t.Errorf("unexpected index %d: %+v", i, idx)
+		}
+	}
+
+	orders := s.Table("orders")
+	if id := orders.Column("id"); !id.PrimaryKey || id.Nullable {
+		t.Errorf("unexpected orders.id column %+v", id)
+	}
+	if len(orders.Indexes) != 2 || orders.Indexes[1].Name != "idx_orders_user" {
+		t.Errorf("unexpected orders indexes %+v", orders.Indexes)
+	}
+	expectFK := ForeignKey{Name: "fk_orders_user", Columns: []string{"user_id"}, RefTable: "users", RefColumns: []string{"id"}}
+	if len(orders.ForeignKeys) != 1 || !reflect.DeepEqual(*orders.ForeignKeys[0], expectFK) {
+		t.Errorf("unexpected foreign keys %+v", orders.ForeignKeys)
+	}
+}
+
+const postgresDDL = `
+CREATE TABLE public.accounts (
+    id bigserial PRIMARY KEY,
+    email character varying(255) NOT NULL,
+    nick text DEFAULT 'guest'::text,
+    score double precision,
+    tags text[],
+    active boolean DEFAULT true NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT accounts_email_key UNIQUE (email)
+);
+CREATE TABLE "posts" (
+    "id" integer GENERATED BY DEFAULT AS IDENTITY,
+    "account_id" bigint REFERENCES accounts(id) ON DELETE CASCADE,
+    "title" varchar(100) NOT NULL
+);
+ALTER TABLE ONLY public.posts ADD CONSTRAINT posts_pkey PRIMARY KEY (id);
+CREATE UNIQUE INDEX CONCURRENTLY IF NOT EXISTS idx_posts_title ON ONLY public.posts USING btree (title);
+CREATE INDEX idx_posts_lower ON posts (lower(title));
+COMMENT ON TABLE posts IS 'blog posts';
+COMMENT ON COLUMN public.posts.title IS 'title of post';
+`
+
+func TestSchema_Parse_Postgres(t *testing.T) {
+	s, _ := NewSchema(Postgres)
+	if err := s.Parse(postgresDDL); err != nil {
+		t.Fatalf("parse fail: %s", err)
+	}
+
+	accounts := s.Table("accounts")
+	expectTypes := map[string]string{
+		"id": "int8", "email": "varchar", "nick": "text", "score": "float8",
+		"tags": "_text", "active": "bool", "created_at": "timestamptz",
+	}
+	for name, typ := range expectTypes {
+		if col := accounts.Column(name); col == nil || col.DataType != typ {
+			t.Errorf("expect %s of type %s, got %+v", name, typ, col)
+		}
+	}
+	if id := accounts.Column("id"); !id.AutoIncrement || !id.PrimaryKey || id.Nullable {
+		t.Errorf("unexpected id column %+v", id)
+	}
+	if nick := accounts.Column("nick"); nick.Default == nil || *nick.Default != "guest" {
+		t.Errorf("unexpected nick column %+v", nick)
+	}
+	if active := accounts.Column("active"); active.Nullable || *active.Default != "true" {
+		t.Errorf("unexpected active column %+v", active)
+	}
+	if created := accounts.Column("created_at"); *created.Default != "now()" {
+		t.Errorf("unexpected created_at default %q", *created.Default)
+	}
+	if len(accounts.Indexes) != 2 || accounts.Indexes[0].Name != "accounts_pkey" || accounts.Indexes[1].Name != "accounts_email_key" {
+		t.Errorf("unexpected accounts indexes %+v", accounts.Indexes)
+	}
+
+	posts := s.Table("posts")
+	if posts.Comment != "blog posts" || posts.Column("title").Comment != "title of post" {
+		t.Errorf("unexpected comments of posts")
+	}
+	if id := posts.Column("id"); !id.AutoIncrement || !id.PrimaryKey || id.DataType != "int4" {
+		t.Errorf("unexpected posts.id column %+v", id)
+	}
+	if len(posts.Indexes) != 2 || posts.Indexes[1].Name != "idx_posts_title" || !posts.Indexes[1].Unique {
+		t.Errorf("unexpected posts indexes %+v", posts.Indexes)
+	}
+	if len(posts.ForeignKeys) != 1 || posts.ForeignKeys[0].RefTable != "accounts" {
+		t.Errorf("unexpected posts foreign keys %+v", posts.ForeignKeys)
+	}
+}
+
+func TestSchema_Parse_Error(t *testing.T) {
+	s, _ := NewSchema(MySQL)
+	if err := s.Parse("CREATE TABLE t (id int"); err == nil {
+		t.Errorf("expect error of unclosed parenthesis")
+	}
+	if err := s.Parse("CREATE TABLE t (name varchar(10) DEFAULT 'x)"); err == nil {
+		t.Errorf("expect error of unterminated string")
+	}
+	if _, err := NewSchema("oracle"); err == nil {
+		t.Errorf("expect error of unsupported dialect")
+	}
+}
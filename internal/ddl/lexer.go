@@ -0,0 +1,119 @@
+package ddl
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokWord   tokenKind = iota // bare word: keyword, identifier or number
+	tokQuoted                  // quoted identifier: `name` or "name"
+	tokString                  // string literal: 'value'
+	tokPunct                   // punctuation: ( ) , ; . and operators
+)
+
+type token struct {
+	kind tokenKind
+	text string // unquoted content for tokQuoted and tokString
+}
+
+// is report whether token is the keyword, case-insensitive
+func (t token) is(keyword string) bool {
+	return t.kind == tokWord && strings.EqualFold(t.text, keyword)
+}
+
+// raw return token as written in sql
+func (t token) raw() string {
+	switch t.kind {
+	case tokString:
+		return "'" + strings.ReplaceAll(t.text, "'", "''") + "'"
+	case tokQuoted:
+		return `"` + t.text + `"`
+	}
+	return t.text
+}
+
+// tokenize split sql into tokens, comments are dropped.
+// In mysql syntax "..." is a string literal and backslash escapes in string literals.
+func tokenize(sql string, mysql bool) ([]token, error) {
+	var toks []token
+	rs := []rune(sql)
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '-' && i+1 < len(rs) && rs[i+1] == '-', r == '#':
+			for i < len(rs) && rs[i] != '\n' {
+				i++
+			}
+		case r == '/' && i+1 < len(rs) && rs[i+1] == '*':
+			j := i + 2
+			for j+1 < len(rs) && !(rs[j] == '*' && rs[j+1] == '/') {
+				j++
+			}
+			if j+1 >= len(rs) {
+				return nil, fmt.Errorf("unterminated comment")
+			}
+			i = j + 2
+		case r == '\'' || r == '"' || r == '`':
+			text, n, err := readQuoted(rs[i:], r, mysql)
+			if err != nil {
+				return nil, err
+			}
+			kind := tokQuoted
+			if r == '\'' || (mysql && r == '"') {
+				kind = tokString
+			}
+			toks = append(toks, token{kind: kind, text: text})
+			i += n
+		case r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r):
+			start := i
+			for i < len(rs) && (rs[i] == '_' || rs[i] == '$' || unicode.IsLetter(rs[i]) || unicode.IsDigit(rs[i])) {
+				i++
+			}
+			// E'...' escape strings of postgres, N'...' national strings of mysql
+			if i < len(rs) && rs[i] == '\'' && i-start == 1 && strings.ContainsRune("EeNn", rs[start]) {
+				continue
+			}
+			toks = append(toks, token{kind: tokWord, text: string(rs[start:i])})
+		case r == ':' && i+1 < len(rs) && rs[i+1] == ':':
+			toks = append(toks, token{kind: tokPunct, text: "::"})
+			i += 2
+		default:
+			toks = append(toks, token{kind: tokPunct, text: string(r)})
+			i++
+		}
+	}
+	return toks, nil
+}
+
+// readQuoted read quoted text starting at rs[0], doubled quote is unescaped
+func readQuoted(rs []rune, quote rune, backslashEscape bool) (text string, n int, err error) {
+	var b strings.Builder
+	for i := 1; i < len(rs); i++ {
+		switch {
+		case backslashEscape && rs[i] == '\\' && quote != '`' && i+1 < len(rs):
+			i++
+			b.WriteRune(rs[i])
+		case rs[i] == quote && i+1 < len(rs) && rs[i+1] == quote:
+			i++
+			b.WriteRune(quote)
+		case rs[i] == quote:
+			return b.String(), i + 1, nil
+		default:
+			b.WriteRune(rs[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated quoted text %s", string(rs[:min(len(rs), 20)]))
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
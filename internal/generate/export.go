@@ -31,7 +31,8 @@ func GetQueryStructMeta(db *gorm.DB, conf *model.Config) (*QueryStructMeta, erro
 		return nil, fmt.Errorf("model name %q is invalid: %w", structName, err)
 	}
 
-	columns, err := getTableColumns(db, conf.GetSchemaName(db), tableName, conf.FieldWithIndexTag)
+	schemaName := conf.GetSchemaName(db)
+	columns, indexes, err := getTableColumns(db, schemaName, tableName, conf.FieldWithIndexTag, conf.ReadIndexes)
 	if err != nil {
 		return nil, err
 	}
@@ -48,6 +49,9 @@ func GetQueryStructMeta(db *gorm.DB, conf *model.Config) (*QueryStructMeta, erro
 		StructInfo:      parser.Param{Type: structName, Package: conf.ModelPkg},
 		ImportPkgPaths:  conf.ImportPkgPaths,
 		Fields:          getFields(db, conf, columns),
+		Columns:         columns,
+		Indexes:         indexes,
+		SchemaName:      schemaName,
 	}).addMethodFromAddMethodOpt(conf.GetModelMethods()...), nil
 }
 
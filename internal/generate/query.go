@@ -37,7 +37,11 @@ type QueryStructMeta struct {
 	ModelStructName string // origin/model struct name
 	TableName       string // table name in db server
 	StructInfo      parser.Param
+	ModelPkgName    string // package name of model struct referenced in query code, empty when model is in query package
 	Fields          []*model.Field
+	Columns         []*model.Column // columns read from table, nil when model is not generated from table
+	Indexes         []gorm.Index    // indexes read with columns, nil when they are not read
+	SchemaName      string          // schema the table is read from, empty for current one
 
 	Source         model.SourceCode
 	ImportPkgPaths []string
@@ -224,6 +228,22 @@ func (b *QueryStructMeta) Relations() (result []field.Relation) {
 	return result
 }
 
+// ModelType model struct type referenced in query code
+func (b *QueryStructMeta) ModelType() string {
+	if b.ModelPkgName == "" {
+		return b.StructInfo.Type
+	}
+	return b.ModelPkgName + "." + b.StructInfo.Type
+}
+
+// RelationType relation field type referenced in query code
+func (b *QueryStructMeta) RelationType(r *field.Relation) string {
+	if b.ModelPkgName == "" || strings.Contains(r.Type(), ".") {
+		return r.Type()
+	}
+	return b.ModelPkgName + "." + r.Type()
+}
+
 // StructComment struct comment
 func (b *QueryStructMeta) StructComment() string {
 	if b.TableName != "" {
@@ -269,7 +289,7 @@ func (b *QueryStructMeta) ReviseDIYMethod() error {
 
 func (b *QueryStructMeta) addMethodFromAddMethodOpt(methods ...interface{}) *QueryStructMeta {
 	for _, method := range methods {
-		modelMethods, err := parser.GetModelMethod(method, 4)
+		modelMethods, err := parser.GetModelMethod(method)
 		if err != nil {
 			panic("add diy method err:" + err.Error())
 		}
@@ -20,34 +20,41 @@ func getTableInfo(db *gorm.DB) ITableInfo {
 	return &tableInfo{db}
 }
 
-func getTableColumns(db *gorm.DB, schemaName string, tableName string, indexTag bool) (result []*model.Column, err error) {
+// getTableColumns read columns of table, indexes are read too when indexTag or readIndexes is set
+func getTableColumns(db *gorm.DB, schemaName string, tableName string, indexTag, readIndexes bool) (result []*model.Column, indexes []gorm.Index, err error) {
 	if db == nil {
-		return nil, errors.New("gorm db is nil")
+		return nil, nil, errors.New("gorm db is nil")
 	}
 
 	mt := getTableInfo(db)
 	result, err = mt.GetTableColumns(schemaName, tableName)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	if !indexTag || len(result) == 0 {
-		return result, nil
+	if !(indexTag || readIndexes) || len(result) == 0 {
+		return result, nil, nil
 	}
 
 	index, err := mt.GetTableIndex(schemaName, tableName)
 	if err != nil { //ignore find index err
 		db.Logger.Warn(context.Background(), "GetTableIndex for %s,err=%s", tableName, err.Error())
-		return result, nil
+		return result, nil, nil
 	}
-	if len(index) == 0 {
-		return result, nil
+	indexes = append([]gorm.Index{}, index...)
+	if !indexTag || len(index) == 0 {
+		return result, indexes, nil
 	}
 
 	im := model.GroupByColumn(index)
 	for _, c := range result {
 		c.Indexes = im[c.Name()]
 	}
-	return result, nil
+	return result, indexes, nil
+}
+
+// GetTableIndexes get indexes of table
+func GetTableIndexes(db *gorm.DB, schemaName string, tableName string) ([]gorm.Index, error) {
+	return getTableInfo(db).GetTableIndex(schemaName, tableName)
 }
 
 type tableInfo struct{ *gorm.DB }
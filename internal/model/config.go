@@ -42,6 +42,7 @@ type FieldConfig struct {
 	FieldSignable     bool // detect integer field's unsigned type, adjust generated data type
 	FieldWithIndexTag bool // generate with gorm index tag
 	FieldWithTypeTag  bool // generate with gorm column type tag
+	ReadIndexes       bool // read indexes of table even without index tag, e.g. for data dictionary
 
 	FieldJSONTagNS func(columnName string) string
 	FieldNewTagNS  func(columnName string) string
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -36,10 +37,20 @@ func Merge(tags ...Tag) Tag {
 	return tags[0]
 }
 
+// Keys return tag keys in sorted order
+func (t Tag) Keys() []string {
+	keys := make([]string, 0, len(t))
+	for k := range t {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func (t Tag) StructTag() reflect.StructTag {
 	var s string
-	for k, v := range t {
-		s += fmt.Sprintf(`%s:"%s" `, k, v)
+	for _, k := range t.Keys() {
+		s += fmt.Sprintf(`%s:"%s" `, k, t[k])
 	}
 	return reflect.StructTag(strings.TrimSpace(s))
 }
@@ -75,11 +75,12 @@ func (c *Column) ToField(nullable, coverable, signable bool) *Field {
 	if c.Field != nil {
 		DataType = c.Field.FieldType.String()
 		FieldType = c.Field.Tag.Get("type")
-		for k, v := range Parse(c.Field.Tag) {
+		tag := Parse(c.Field.Tag)
+		for _, k := range tag.Keys() {
 			if k == "json" || k == "gorm" {
 				continue
 			}
-			newTag += fmt.Sprintf(`%v:"%v" `, k, v)
+			newTag += fmt.Sprintf(`%v:"%v" `, k, tag[k])
 		}
 		if c.Field.Tag.Get("json") != "" {
 			jsonTag = c.Field.Tag.Get("json")
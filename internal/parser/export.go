@@ -18,8 +18,9 @@ type InterfacePath struct {
 	Package  string
 }
 
-// GetInterfacePath get interface's directory path and all files it contains
-func GetInterfacePath(v interface{}) (paths []*InterfacePath, err error) {
+// GetInterfacePath get interface's directory path and all files it contains,
+// interfaces of package main are read from directory of callerFile
+func GetInterfacePath(v interface{}, callerFile string) (paths []*InterfacePath, err error) {
 	value := reflect.ValueOf(v)
 	if value.Kind() != reflect.Func {
 		err = fmt.Errorf("model param is not function:%s", value.String())
@@ -40,8 +41,7 @@ func GetInterfacePath(v interface{}) (paths []*InterfacePath, err error) {
 		var p *build.Package
 
 		if strings.Split(arg.String(), ".")[0] == "main" {
-			_, file, _, _ := runtime.Caller(3)
-			p, err = ctx.ImportDir(filepath.Dir(file), build.ImportComment)
+			p, err = ctx.ImportDir(filepath.Dir(callerFile), build.ImportComment)
 		} else {
 			p, err = ctx.Import(arg.PkgPath(), "", build.ImportComment)
 		}
@@ -73,8 +73,8 @@ func fileExists(path string) bool {
 	return err == nil
 }
 
-// GetModelMethod get diy methods
-func GetModelMethod(v interface{}, skip int) (method *DIYMethods, err error) {
+// GetModelMethod get diy methods, methods of package main are read from directory of the first caller outside gen
+func GetModelMethod(v interface{}) (method *DIYMethods, err error) {
 	method = new(DIYMethods)
 
 	// get diy method info by input value, must input a function or a struct
@@ -98,8 +98,7 @@ func GetModelMethod(v interface{}, skip int) (method *DIYMethods, err error) {
 	// if struct in main file
 	ctx := build.Default
 	if method.pkgPath == "main" {
-		_, file, _, _ := runtime.Caller(skip)
-		p, err = ctx.ImportDir(filepath.Dir(file), build.ImportComment)
+		p, err = ctx.ImportDir(filepath.Dir(outerCallerFile()), build.ImportComment)
 	} else {
 		p, err = ctx.Import(method.pkgPath, "", build.ImportComment)
 	}
@@ -120,3 +119,18 @@ func GetModelMethod(v interface{}, skip int) (method *DIYMethods, err error) {
 	// read files got methods
 	return method, method.LoadMethods()
 }
+
+// outerCallerFile file of the first caller outside gen, which declares types of package main
+func outerCallerFile() string {
+	pcs := make([]uintptr, 64)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "gorm.io/gen.") && !strings.HasPrefix(frame.Function, "gorm.io/gen/") {
+			return frame.File
+		}
+		if !more {
+			return ""
+		}
+	}
+}
@@ -1,7 +1,9 @@
 package template
 
 const Field = `package {{.Package}}
-
+{{if .DaoPkgPath}}
+import {{.Dao}} "{{.DaoPkgPath}}"
+{{end}}
 var (
 	ALL = {{.Dao}}.Query{{.StructName}}.ALL
 {{range .Fields -}}
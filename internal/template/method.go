@@ -80,11 +80,11 @@ func ({{.S}} {{.QueryStructName}}Do) Key({{range $index, $element := .Field}}{{i
 	{{end}}
 	return {{.S}}.Where(keys...)
 }
-func ({{.S}} {{.QueryStructName}}Do) Get({{range $index, $element := .Field}}{{if $index}}, {{end}}{{.ColumnName}} {{.Type}}{{end}}) (*{{.StructInfo.Type}}, error) {
+func ({{.S}} {{.QueryStructName}}Do) Get({{range $index, $element := .Field}}{{if $index}}, {{end}}{{.ColumnName}} {{.Type}}{{end}}) (*{{.ModelType}}, error) {
 	return {{.S}}.Key({{range $index, $element := .Field}}{{if $index}}, {{end}}{{.ColumnName}}{{end}}).First()
 }
 
-func ({{.S}} {{.QueryStructName}}Do) MustGet({{range $index, $element := .Field}}{{if $index}}, {{end}}{{.ColumnName}} {{.Type}}{{end}}) (*{{.StructInfo.Type}}) {
+func ({{.S}} {{.QueryStructName}}Do) MustGet({{range $index, $element := .Field}}{{if $index}}, {{end}}{{.ColumnName}} {{.Type}}{{end}}) (*{{.ModelType}}) {
 	data,_ := {{.S}}.Key({{range $index, $element := .Field}}{{if $index}}, {{end}}{{.ColumnName}}{{end}}).First()
 	return data
 }
@@ -100,7 +100,7 @@ var uni_{{$.QueryStructName}}_{{.ColumnName}} = field.New{{.CustomGenType}}("{{$
 func ({{$.S}} {{$.QueryStructName}}Do) Set{{.Name}}({{.Name}} {{.Type}}) {{$.ReturnObject}}  {
 	return {{$.S}}.Where(uni_{{$.QueryStructName}}_{{.ColumnName}}.Eq({{.Name}}))
 }
-func ({{$.S}} {{$.QueryStructName}}Do) By{{.Name}}({{.Name}} {{.Type}}) (*{{$.StructInfo.Type}})  {
+func ({{$.S}} {{$.QueryStructName}}Do) By{{.Name}}({{.Name}} {{.Type}}) (*{{$.ModelType}})  {
 	data,_:= {{$.S}}.Where(uni_{{$.QueryStructName}}_{{.ColumnName}}.Eq({{.Name}})).First()
 	return data
 }
@@ -162,7 +162,7 @@ func ({{.S}} {{.QueryStructName}}Do) Unscoped() {{.ReturnObject}} {
 	return {{.S}}.withDO({{.S}}.DO.Unscoped())
 }
 
-func ({{.S}} {{.QueryStructName}}Do) Create(values ...*{{.StructInfo.Type}}) error {
+func ({{.S}} {{.QueryStructName}}Do) Create(values ...*{{.ModelType}}) error {
 	if len(values) == 0 {
 		return nil
 	}
@@ -173,57 +173,57 @@ func ({{.S}} {{.QueryStructName}}Do) CreateAny(values ...any) error {
 	if len(values) == 0 {
 		return nil
 	}
-	var data = make([]*{{.StructInfo.Type}},0)
+	var data = make([]*{{.ModelType}},0)
 	if err:=gconv.Scan(values,data);err != nil {
 		return err
 	}
 	return {{.S}}.DO.Create(data)
 }
 
-func ({{.S}} {{.QueryStructName}}Do) CreateInBatches(values []*{{.StructInfo.Type}}, batchSize int) error {
+func ({{.S}} {{.QueryStructName}}Do) CreateInBatches(values []*{{.ModelType}}, batchSize int) error {
 	return {{.S}}.DO.CreateInBatches(values, batchSize)
 }
 
 // Save : !!! underlying implementation is different with GORM
 // The method is equivalent to executing the statement: db.Clauses(clause.OnConflict{UpdateAll: true}).Create(values)
-func ({{.S}} {{.QueryStructName}}Do) Save(values ...*{{.StructInfo.Type}}) error {
+func ({{.S}} {{.QueryStructName}}Do) Save(values ...*{{.ModelType}}) error {
 	if len(values) == 0 {
 		return nil
 	}
 	return {{.S}}.DO.Save(values)
 }
 
-func ({{.S}} {{.QueryStructName}}Do) First() (*{{.StructInfo.Type}}, error) {
+func ({{.S}} {{.QueryStructName}}Do) First() (*{{.ModelType}}, error) {
 	if result, err := {{.S}}.DO.First(); err != nil {
 		return nil, err
 	} else {
-		return result.(*{{.StructInfo.Type}}), nil
+		return result.(*{{.ModelType}}), nil
 	}
 }
 
-func ({{.S}} {{.QueryStructName}}Do) Take() (*{{.StructInfo.Type}}, error) {
+func ({{.S}} {{.QueryStructName}}Do) Take() (*{{.ModelType}}, error) {
 	if result, err := {{.S}}.DO.Take(); err != nil {
 		return nil, err
 	} else {
-		return result.(*{{.StructInfo.Type}}), nil
+		return result.(*{{.ModelType}}), nil
 	}
 }
 
-func ({{.S}} {{.QueryStructName}}Do) Last() (*{{.StructInfo.Type}}, error) {
+func ({{.S}} {{.QueryStructName}}Do) Last() (*{{.ModelType}}, error) {
 	if result, err := {{.S}}.DO.Last(); err != nil {
 		return nil, err
 	} else {
-		return result.(*{{.StructInfo.Type}}), nil
+		return result.(*{{.ModelType}}), nil
 	}
 }
 
-func ({{.S}} {{.QueryStructName}}Do) Find() ([]*{{.StructInfo.Type}}, error) {
+func ({{.S}} {{.QueryStructName}}Do) Find() ([]*{{.ModelType}}, error) {
 	result, err := {{.S}}.DO.Find()
-	return result.([]*{{.StructInfo.Type}}), err
+	return result.([]*{{.ModelType}}), err
 }
 
-func ({{.S}} {{.QueryStructName}}Do) FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*{{.StructInfo.Type}}, err error) {
-	buf := make([]*{{.StructInfo.Type}}, 0, batchSize)
+func ({{.S}} {{.QueryStructName}}Do) FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*{{.ModelType}}, err error) {
+	buf := make([]*{{.ModelType}}, 0, batchSize)
 	err = {{.S}}.DO.FindInBatches(&buf, batchSize, func(tx gen.Dao, batch int) error {
 		defer func() { results = append(results, buf...) }()
 		return fc(tx, batch)
@@ -231,7 +231,7 @@ func ({{.S}} {{.QueryStructName}}Do) FindInBatch(batchSize int, fc func(tx gen.D
 	return results, err
 }
 
-func ({{.S}} {{.QueryStructName}}Do) FindInBatches(result *[]*{{.StructInfo.Type}}, batchSize int, fc func(tx gen.Dao, batch int) error) error {
+func ({{.S}} {{.QueryStructName}}Do) FindInBatches(result *[]*{{.ModelType}}, batchSize int, fc func(tx gen.Dao, batch int) error) error {
 	return {{.S}}.DO.FindInBatches(result, batchSize, fc)
 }
 
@@ -257,23 +257,23 @@ func ({{.S}} {{.QueryStructName}}Do) Preload(fields ...field.RelationField) {{.R
 	return &{{.S}}
 }
 
-func ({{.S}} {{.QueryStructName}}Do) FirstOrInit() (*{{.StructInfo.Type}}, error) {
+func ({{.S}} {{.QueryStructName}}Do) FirstOrInit() (*{{.ModelType}}, error) {
 	if result, err := {{.S}}.DO.FirstOrInit(); err != nil {
 		return nil, err
 	} else {
-		return result.(*{{.StructInfo.Type}}), nil
+		return result.(*{{.ModelType}}), nil
 	}
 }
 
-func ({{.S}} {{.QueryStructName}}Do) FirstOrCreate() (*{{.StructInfo.Type}}, error) {
+func ({{.S}} {{.QueryStructName}}Do) FirstOrCreate() (*{{.ModelType}}, error) {
 	if result, err := {{.S}}.DO.FirstOrCreate(); err != nil {
 		return nil, err
 	} else {
-		return result.(*{{.StructInfo.Type}}), nil
+		return result.(*{{.ModelType}}), nil
 	}
 }
 
-func ({{.S}} {{.QueryStructName}}Do) FindByPage(offset int, limit int) (result []*{{.StructInfo.Type}}, count int64, err error) {
+func ({{.S}} {{.QueryStructName}}Do) FindByPage(offset int, limit int) (result []*{{.ModelType}}, count int64, err error) {
 	result, err = {{.S}}.Offset(offset).Limit(limit).Find()
 	if err != nil{
 		return
@@ -302,7 +302,7 @@ func ({{.S}} {{.QueryStructName}}Do) Scan(result interface{}) (err error) {
 	return {{.S}}.DO.Scan(result)
 }
 
-func ({{.S}} {{.QueryStructName}}Do) Delete(models ...*{{.StructInfo.Type}}) (result gen.ResultInfo, err error) {
+func ({{.S}} {{.QueryStructName}}Do) Delete(models ...*{{.ModelType}}) (result gen.ResultInfo, err error) {
 	return {{.S}}.DO.Delete(models)
 }
 
@@ -317,9 +317,9 @@ func ({{.S}} *{{.QueryStructName}}Do) withDO(do gen.Dao) (*{{.QueryStructName}}D
 const CRUDMethodTest = `
 func init() {
 	InitializeDB()
-	err := db.AutoMigrate(&{{.ModelStructName}}{})
+	err := db.AutoMigrate(&{{.ModelType}}{})
 	if err != nil{
-		fmt.Printf("Error: AutoMigrate(&{{.ModelStructName}}{}) fail: %s", err)
+		fmt.Printf("Error: AutoMigrate(&{{.ModelType}}{}) fail: %s", err)
 	}
 }
 
@@ -340,17 +340,17 @@ func Test_{{.QueryStructName}}Query(t *testing.T) {
 		t.Error("GetFieldByName(\"\") from {{.QueryStructName}} success")
 	}
 
-	err = _do.Create(&{{.ModelStructName}}{})
+	err = _do.Create(&{{.ModelType}}{})
 	if err != nil {
 		t.Error("create item in table <{{.TableName}}> fail:", err)
 	}
 
-	err = _do.Save(&{{.ModelStructName}}{})
+	err = _do.Save(&{{.ModelType}}{})
 	if err != nil {
 		t.Error("create item in table <{{.TableName}}> fail:", err)
 	}
 
-	err = _do.CreateInBatches([]*{{.ModelStructName}}{ {}, {} }, 10)
+	err = _do.CreateInBatches([]*{{.ModelType}}{ {}, {} }, 10)
 	if err != nil {
 		t.Error("create item in table <{{.TableName}}> fail:", err)
 	}
@@ -375,7 +375,7 @@ func Test_{{.QueryStructName}}Query(t *testing.T) {
 		t.Error("FindInBatch() on table <{{.TableName}}> fail:", err)
 	}
 
-	err = _do.Where(primaryKey.IsNotNull()).FindInBatches(&[]*{{.ModelStructName}}{}, 10, func(tx gen.Dao, batch int) error { return nil })
+	err = _do.Where(primaryKey.IsNotNull()).FindInBatches(&[]*{{.ModelType}}{}, 10, func(tx gen.Dao, batch int) error { return nil })
 	if err != nil {
 		t.Error("FindInBatches() on table <{{.TableName}}> fail:", err)
 	}
@@ -410,7 +410,7 @@ func Test_{{.QueryStructName}}Query(t *testing.T) {
 		t.Error("FindByPage() on table <{{.TableName}}> fail:", err)
 	}
 	
-	_, err = _do.ScanByPage(&{{.ModelStructName}}{}, 0, 1)
+	_, err = _do.ScanByPage(&{{.ModelType}}{}, 0, 1)
 	if err != nil {
 		t.Error("ScanByPage() on table <{{.TableName}}> fail:", err)
 	}
@@ -42,7 +42,7 @@ type {{.ModelStructName}} struct {
 
 
 {{range .Fields -}}
-	{{if .IsRelation -}}
+	{{if and .IsRelation (not $.ModelPkgName) .Relation.Key -}}
 func(r *{{$.ModelStructName}}) Query{{.Relation.Name}}() I{{.Relation.Name}}Do {
 	return Query{{.Relation.Name}}.Key(r.{{.Relation.Key}})
 }
@@ -35,7 +35,7 @@ const (
 		_{{.QueryStructName}} := {{.QueryStructName}}{}
 	
 		_{{.QueryStructName}}.{{.QueryStructName}}Do.UseDB(db,opts...)
-		_{{.QueryStructName}}.{{.QueryStructName}}Do.UseModel(&{{.StructInfo.Type}}{})
+		_{{.QueryStructName}}.{{.QueryStructName}}Do.UseModel(&{{.ModelType}}{})
 	
 		tableName := _{{.QueryStructName}}.{{.QueryStructName}}Do.TableName()
 		_{{$.QueryStructName}}.ALL = field.NewAsterisk(tableName)
@@ -169,13 +169,13 @@ type I{{.ModelStructName}}Do interface {
 	Where(conds ...gen.Condition) I{{.ModelStructName}}Do
 	{{if .Field}}
 	Key({{range $index, $element := .Field}}{{if $index}}, {{end}}{{.ColumnName}} {{.Type}}{{end}})  {{.ReturnObject}}
-	Get({{range $index, $element := .Field}}{{if $index}}, {{end}}{{.ColumnName}} {{.Type}}{{end}}) (*{{.StructInfo.Type}}, error)
-    MustGet({{range $index, $element := .Field}}{{if $index}}, {{end}}{{.ColumnName}} {{.Type}}{{end}}) (*{{.StructInfo.Type}})
+	Get({{range $index, $element := .Field}}{{if $index}}, {{end}}{{.ColumnName}} {{.Type}}{{end}}) (*{{.ModelType}}, error)
+    MustGet({{range $index, $element := .Field}}{{if $index}}, {{end}}{{.ColumnName}} {{.Type}}{{end}}) (*{{.ModelType}})
 	MustDelete({{range $index, $element := .Field}}{{if $index}}, {{end}}{{.ColumnName}} {{.Type}}{{end}}) (err error)
 	{{end}}
     {{range .Uniques}}
 	Set{{.Name}}({{.Name}} {{.Type}}) {{$.ReturnObject}}
-	By{{.Name}}({{.Name}} {{.Type}}) (*{{$.StructInfo.Type}}){{end}}
+	By{{.Name}}({{.Name}} {{.Type}}) (*{{$.ModelType}}){{end}}
     WhereStruct(get field.GetField,data any) I{{.ModelStructName}}Do
 	Order(conds ...field.Expr) I{{.ModelStructName}}Do
 	Distinct(cols ...field.Expr) I{{.ModelStructName}}Do
@@ -190,18 +190,18 @@ type I{{.ModelStructName}}Do interface {
 	Count() (count int64, err error)
 	Scopes(funcs ...func(gen.Dao) gen.Dao) I{{.ModelStructName}}Do
 	Unscoped() I{{.ModelStructName}}Do
-	Create(values ...*{{.StructInfo.Type}}) error
+	Create(values ...*{{.ModelType}}) error
 	CreateAny(values ...any) error
-	CreateInBatches(values []*{{.StructInfo.Type}}, batchSize int) error
-	Save(values ...*{{.StructInfo.Type}}) error
-	First() (*{{.StructInfo.Type}}, error)
-	Take() (*{{.StructInfo.Type}}, error)
-	Last() (*{{.StructInfo.Type}}, error)
-	Find() ([]*{{.StructInfo.Type}}, error)
-	FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*{{.StructInfo.Type}}, err error)
-	FindInBatches(result *[]*{{.StructInfo.Type}}, batchSize int, fc func(tx gen.Dao, batch int) error) error
+	CreateInBatches(values []*{{.ModelType}}, batchSize int) error
+	Save(values ...*{{.ModelType}}) error
+	First() (*{{.ModelType}}, error)
+	Take() (*{{.ModelType}}, error)
+	Last() (*{{.ModelType}}, error)
+	Find() ([]*{{.ModelType}}, error)
+	FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*{{.ModelType}}, err error)
+	FindInBatches(result *[]*{{.ModelType}}, batchSize int, fc func(tx gen.Dao, batch int) error) error
 	Pluck(column field.Expr, dest interface{}) error
-	Delete(...*{{.StructInfo.Type}}) (info gen.ResultInfo, err error)
+	Delete(...*{{.ModelType}}) (info gen.ResultInfo, err error)
 	Update(column field.Expr, value interface{}) (info gen.ResultInfo, err error)
 	UpdateSimple(columns ...field.AssignExpr) (info gen.ResultInfo, err error)
 	Updates(value interface{}) (info gen.ResultInfo, err error)
@@ -213,9 +213,9 @@ type I{{.ModelStructName}}Do interface {
 	Assign(attrs ...field.AssignExpr) I{{.ModelStructName}}Do
 	Joins(fields ...field.RelationField) I{{.ModelStructName}}Do
 	Preload(fields ...field.RelationField) I{{.ModelStructName}}Do
-	FirstOrInit() (*{{.StructInfo.Type}}, error)
-	FirstOrCreate() (*{{.StructInfo.Type}}, error)
-	FindByPage(offset int, limit int) (result []*{{.StructInfo.Type}}, count int64, err error)
+	FirstOrInit() (*{{.ModelType}}, error)
+	FirstOrCreate() (*{{.ModelType}}, error)
+	FindByPage(offset int, limit int) (result []*{{.ModelType}}, count int64, err error)
 	ScanByPage(result interface{}, offset int, limit int) (count int64, err error)
 	Scan(result interface{}) (err error)
 	Returning(value interface{}, columns ...string) I{{.ModelStructName}}Do
@@ -257,7 +257,7 @@ func (a {{$.QueryStructName}}{{$relationship}}{{$relation.Name}}) WithContext(ct
 	return &a
 }
 
-func (a {{$.QueryStructName}}{{$relationship}}{{$relation.Name}}) Model(m *{{$.StructInfo.Type}}) *{{$.QueryStructName}}{{$relationship}}{{$relation.Name}}Tx {
+func (a {{$.QueryStructName}}{{$relationship}}{{$relation.Name}}) Model(m *{{$.ModelType}}) *{{$.QueryStructName}}{{$relationship}}{{$relation.Name}}Tx {
 	return &{{$.QueryStructName}}{{$relationship}}{{$relation.Name}}Tx{a.db.Model(m).Association(a.Name())}
 }
 
@@ -265,11 +265,11 @@ func (a {{$.QueryStructName}}{{$relationship}}{{$relation.Name}}) Model(m *{{$.S
 	relationTx = `
 type {{$.QueryStructName}}{{$relationship}}{{$relation.Name}}Tx struct{ tx *gorm.Association }
 
-func (a {{$.QueryStructName}}{{$relationship}}{{$relation.Name}}Tx) Find() (result {{if eq $relationship "HasMany" "ManyToMany"}}[]{{end}}*{{$relation.Type}}, err error) {
+func (a {{$.QueryStructName}}{{$relationship}}{{$relation.Name}}Tx) Find() (result {{if eq $relationship "HasMany" "ManyToMany"}}[]{{end}}*{{$.RelationType $relation}}, err error) {
 	return result, a.tx.Find(&result)
 }
 
-func (a {{$.QueryStructName}}{{$relationship}}{{$relation.Name}}Tx) Append(values ...*{{$relation.Type}}) (err error) {
+func (a {{$.QueryStructName}}{{$relationship}}{{$relation.Name}}Tx) Append(values ...*{{$.RelationType $relation}}) (err error) {
 	targetValues := make([]interface{}, len(values))
 	for i, v := range values {
 		targetValues[i] = v
@@ -277,7 +277,7 @@ func (a {{$.QueryStructName}}{{$relationship}}{{$relation.Name}}Tx) Append(value
 	return a.tx.Append(targetValues...)
 }
 
-func (a {{$.QueryStructName}}{{$relationship}}{{$relation.Name}}Tx) Replace(values ...*{{$relation.Type}}) (err error) {
+func (a {{$.QueryStructName}}{{$relationship}}{{$relation.Name}}Tx) Replace(values ...*{{$.RelationType $relation}}) (err error) {
 	targetValues := make([]interface{}, len(values))
 	for i, v := range values {
 		targetValues[i] = v
@@ -285,7 +285,7 @@ func (a {{$.QueryStructName}}{{$relationship}}{{$relation.Name}}Tx) Replace(valu
 	return a.tx.Replace(targetValues...)
 }
 
-func (a {{$.QueryStructName}}{{$relationship}}{{$relation.Name}}Tx) Delete(values ...*{{$relation.Type}}) (err error) {
+func (a {{$.QueryStructName}}{{$relationship}}{{$relation.Name}}Tx) Delete(values ...*{{$.RelationType $relation}}) (err error) {
 	targetValues := make([]interface{}, len(values))
 	for i, v := range values {
 		targetValues[i] = v
@@ -0,0 +1,42 @@
+package diff
+
+import "testing"
+
+func TestUnified(t *testing.T) {
+	testcases := []struct {
+		a, b   string
+		expect string
+	}{
+		{
+			a:      "a\nb\nc\n",
+			b:      "a\nb\nc\n",
+			expect: "",
+		},
+		{
+			a:      "a\nb\nc\n",
+			b:      "a\nB\nc\n",
+			expect: "--- a\n+++ b\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			a:      "",
+			b:      "a\n",
+			expect: "--- a\n+++ b\n@@ -0,0 +1,1 @@\n+a\n",
+		},
+		{
+			a:      "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			b:      "0\n1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n",
+			expect: "--- a\n+++ b\n@@ -1,3 +1,4 @@\n+0\n 1\n 2\n 3\n@@ -9,4 +10,3 @@\n 9\n 10\n 11\n-12\n",
+		},
+		{
+			a:      "a",
+			b:      "b",
+			expect: "--- a\n+++ b\n@@ -1,1 +1,1 @@\n-a\n\\ No newline at end of file\n+b\n\\ No newline at end of file\n",
+		},
+	}
+
+	for i, tt := range testcases {
+		if got := Unified("a", "b", tt.a, tt.b); got != tt.expect {
+			t.Errorf("case %d: expect\n%s\ngot\n%s", i, tt.expect, got)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package gen
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"gorm.io/gen/internal/utils/diff"
+)
+
+// ManifestFile name of the manifest written into OutPath and model directory outside it,
+// it lists every file generated into the directory by the last run
+const ManifestFile = ".gen-manifest.json"
+
+const manifestVersion = 1
+
+// manifest generated files and their content hash
+type manifest struct {
+	mu sync.Mutex
+
+	Version int               `json:"version"`
+	Files   map[string]string `json:"files"` // file path relative to output root of the manifest -> sha256 of content
+}
+
+func newManifest() *manifest {
+	return &manifest{Version: manifestVersion, Files: make(map[string]string)}
+}
+
+// loadManifest load manifest from path, return empty manifest if not exists
+func loadManifest(out Output, path string) (*manifest, error) {
+	content, err := out.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return newManifest(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	m := newManifest()
+	if err = json.Unmarshal(content, m); err != nil {
+		return nil, fmt.Errorf("parse manifest %s fail: %w", path, err)
+	}
+	if m.Files == nil {
+		m.Files = make(map[string]string)
+	}
+	return m, nil
+}
+
+// add record generated file
+func (m *manifest) add(rel string, content []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Files[filepath.ToSlash(rel)] = hashContent(content)
+}
+
+// save write manifest to path
+func (m *manifest) save(out Output, path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	content, err := json.MarshalIndent(m, "", "\t")
+	if err != nil {
+		return err
+	}
+	return out.WriteFile(path, append(content, '\n'))
+}
+
+// sortedFiles return recorded files in sorted order
+func (m *manifest) sortedFiles() []string {
+	files := make([]string, 0, len(m.Files))
+	for f := range m.Files {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+	return files
+}
+
+func hashContent(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// manifestRoots output roots owning a manifest: OutPath, and model directory when it is outside OutPath
+func (g *Generator) manifestRoots() []string {
+	roots := []string{filepath.Clean(g.OutPath)}
+	modelOutPath, err := g.getModelOutputPath()
+	if err != nil {
+		return roots
+	}
+	if _, _, ok := manifestEntry(roots, modelOutPath); !ok {
+		roots = append(roots, filepath.Clean(modelOutPath))
+	}
+	return roots
+}
+
+// manifestEntry the innermost root containing file and path of file relative to it
+func manifestEntry(roots []string, fileName string) (root, rel string, ok bool) {
+	absFile, err := filepath.Abs(fileName)
+	if err != nil {
+		return "", "", false
+	}
+	depth := -1
+	for _, r := range roots {
+		absRoot, err := filepath.Abs(r)
+		if err != nil {
+			continue
+		}
+		p, err := filepath.Rel(absRoot, absFile)
+		if err != nil || !localPath(filepath.ToSlash(p)) {
+			continue
+		}
+		if len(absRoot) > depth {
+			root, rel, ok, depth = r, filepath.ToSlash(p), true, len(absRoot)
+		}
+	}
+	return root, rel, ok
+}
+
+// localPath report whether slash separated path stays inside the directory it is relative to
+func localPath(rel string) bool {
+	if rel == "" || path.IsAbs(rel) || filepath.IsAbs(filepath.FromSlash(rel)) || filepath.VolumeName(filepath.FromSlash(rel)) != "" {
+		return false
+	}
+	clean := path.Clean(rel)
+	return clean != "." && clean != ".." && !strings.HasPrefix(clean, "../")
+}
+
+// loadManifests load manifest of every output root
+func (g *Generator) loadManifests(roots []string) (map[string]*manifest, error) {
+	manifests := make(map[string]*manifest, len(roots))
+	for _, root := range roots {
+		m, err := loadManifest(g.out, filepath.Join(root, ManifestFile))
+		if err != nil {
+			return nil, err
+		}
+		manifests[root] = m
+	}
+	return manifests, nil
+}
+
+// recordFile add file to manifest of its output root, files outside output roots are not tracked
+func (g *Generator) recordFile(fileName string, content []byte) {
+	if g.manifests == nil {
+		return
+	}
+	if root, rel, ok := manifestEntry(g.manifestRoots(), fileName); ok {
+		g.manifests[root].add(rel, content)
+	}
+}
+
+// cleanStaleFiles remove files generated by last run but not by this one, then save the manifests.
+// Files never generated by gen, generated files edited by hand and entries outside their output root are kept.
+func (g *Generator) cleanStaleFiles() error {
+	roots := g.manifestRoots()
+	prevs, err := g.loadManifests(roots)
+	if err != nil {
+		return err
+	}
+
+	for _, root := range roots {
+		prev := prevs[root]
+		for _, rel := range prev.sortedFiles() {
+			if !localPath(rel) {
+				g.report(Event{Kind: EventWarning, Message: fmt.Sprintf("ignore manifest entry outside %s: %s", root, rel), File: rel})
+				continue
+			}
+			fileName := filepath.Join(root, filepath.FromSlash(rel))
+			if g.generated(roots, fileName) {
+				continue
+			}
+			content, err := g.out.ReadFile(fileName)
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if hashContent(content) != prev.Files[rel] {
+				g.report(Event{Kind: EventWarning, Message: fmt.Sprintf("keep stale file modified by hand: %s", fileName), File: fileName})
+				continue
+			}
+
+			if g.checker != nil {
+				g.checker.remove(fileName, content)
+				continue
+			}
+			if err = g.out.Remove(fileName); err != nil {
+				return fmt.Errorf("remove stale file fail: %w", err)
+			}
+			g.report(Event{Kind: EventFileRemoved, Message: fmt.Sprintf("remove stale file: %s", fileName), File: fileName})
+			g.removeEmptyDir(root, filepath.Dir(fileName))
+		}
+	}
+
+	if g.checker != nil {
+		return nil
+	}
+	for _, root := range roots {
+		if len(g.manifests[root].Files) == 0 && len(prevs[root].Files) == 0 && root != filepath.Clean(g.OutPath) {
+			continue // model directory never written
+		}
+		if err = g.manifests[root].save(g.out, filepath.Join(root, ManifestFile)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// generated report whether file is generated by current run
+func (g *Generator) generated(roots []string, fileName string) bool {
+	root, rel, ok := manifestEntry(roots, fileName)
+	if !ok {
+		return false
+	}
+	_, ok = g.manifests[root].Files[rel]
+	return ok
+}
+
+// removeEmptyDir remove dir and its empty parents inside root, only when output can list directories
+func (g *Generator) removeEmptyDir(root, dir string) {
+	out, ok := g.out.(interface {
+		ReadDir(dir string) ([]os.DirEntry, error)
+	})
+	if !ok {
+		return
+	}
+	for dir != root && len(dir) > len(root) {
+		if entries, err := out.ReadDir(dir); err != nil || len(entries) > 0 {
+			return
+		}
+		if g.out.Remove(dir) != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// remove record file which will be removed as stale
+func (c *checker) remove(fileName string, content []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.diffs = append(c.diffs, &FileDiff{
+		File:    fileName,
+		Removed: true,
+		Diff:    diff.Unified(fileName, "/dev/null", string(content), ""),
+	})
+}
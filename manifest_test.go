@@ -0,0 +1,114 @@
+package gen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gorm.io/gen/helper"
+)
+
+var testCompanyObject = &testObject{
+	tableName:  "companies",
+	structName: "Company",
+	fields: []helper.Field{
+		&testObjectField{name: "ID", typ: "int64", columnName: "id", gormTag: "column:id;primaryKey", jsonTag: "id"},
+		&testObjectField{name: "Name", typ: "string", columnName: "name", gormTag: "column:name", jsonTag: "name"},
+	},
+}
+
+func TestGenerator_CleanStaleFiles(t *testing.T) {
+	outPath := filepath.Join(newModuleDir(t), "query")
+	execute := func(objs ...helper.Object) {
+		g := NewGenerator(Config{OutPath: outPath, ModelPkgPath: "query", Mode: WithDefaultQuery | WithoutContext | WithQueryInterface})
+		for _, obj := range objs {
+			g.ApplyBasic(g.GenerateModelFrom(obj))
+		}
+		if err := g.ExecuteE(); err != nil {
+			t.Fatalf("execute fail: %s", err)
+		}
+	}
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(outPath, name))
+		return err == nil
+	}
+
+	execute(testUserObject, testCompanyObject)
+	for _, name := range []string{ManifestFile, "companies.gen.go", "companies.model.gen.go", "companies/companies.gen.go"} {
+		if !exists(name) {
+			t.Fatalf("expect %s generated", name)
+		}
+	}
+
+	handWritten := filepath.Join(outPath, "companies_ext.go")
+	_ = os.WriteFile(handWritten, []byte("package query\n"), 0640)
+	_ = os.WriteFile(filepath.Join(outPath, "companies.gen.go"), []byte("package query\n// edited\n"), 0640)
+
+	execute(testUserObject)
+	for name, expect := range map[string]bool{
+		"users.gen.go":           true,
+		"companies_ext.go":       true,  // not generated by gen
+		"companies.gen.go":       true,  // edited by hand
+		"companies.model.gen.go": false, // stale
+		"companies":              false, // empty field package dir
+	} {
+		if got := exists(name); got != expect {
+			t.Errorf("expect %s exists=%t, got %t", name, expect, got)
+		}
+	}
+}
+
+func TestGenerator_CleanStaleFiles_ModelDir(t *testing.T) {
+	root := newModuleDir(t)
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/app\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	outPath := filepath.Join(root, "query")
+	var warnings int
+	execute := func(objs ...helper.Object) {
+		g := NewGenerator(Config{OutPath: outPath, ModelPkgPath: "model", Mode: WithDefaultQuery})
+		g.WithReporter(ReporterFunc(func(e Event) {
+			if e.Kind == EventWarning {
+				warnings++
+			}
+		}))
+		for _, obj := range objs {
+			g.ApplyBasic(g.GenerateModelFrom(obj))
+		}
+		if err := g.ExecuteE(); err != nil {
+			t.Fatalf("execute fail: %s", err)
+		}
+	}
+
+	execute(testUserObject, testCompanyObject)
+	companyModel := filepath.Join(root, "model", "companies.model.gen.go")
+	if _, err := os.Stat(companyModel); err != nil {
+		t.Fatalf("expect model generated into model dir: %s", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "model", ManifestFile)); err != nil {
+		t.Fatalf("expect manifest of model dir: %s", err)
+	}
+
+	outside := filepath.Join(root, "outside.txt")
+	_ = os.WriteFile(outside, []byte("x"), 0600)
+	m, err := loadManifest(NewFileOutput(), filepath.Join(outPath, ManifestFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Files["../outside.txt"] = hashContent([]byte("x"))
+	m.Files[filepath.ToSlash(outside)] = hashContent([]byte("x"))
+	if err = m.save(NewFileOutput(), filepath.Join(outPath, ManifestFile)); err != nil {
+		t.Fatal(err)
+	}
+
+	execute(testUserObject)
+	if _, err = os.Stat(companyModel); !os.IsNotExist(err) {
+		t.Errorf("expect stale model in model dir removed")
+	}
+	if _, err = os.Stat(outside); err != nil {
+		t.Errorf("expect file outside OutPath kept: %s", err)
+	}
+	if warnings != 2 {
+		t.Errorf("expect 2 warnings of escaping entries, got %d", warnings)
+	}
+}
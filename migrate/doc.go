@@ -0,0 +1,3 @@
+// Package migrate apply versioned sql or go migrations in order, record applied migrations with checksums
+// in a history table, and hold a lock table so only one instance migrates a database at a time
+package migrate
@@ -0,0 +1,85 @@
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// ErrLocked database is being migrated by another instance
+var ErrLocked = errors.New("migration lock is held by another instance")
+
+// lockRecord the only row of lock table while it is held
+type lockRecord struct {
+	ID       int    `gorm:"primaryKey;autoIncrement:false"`
+	Owner    string `gorm:"size:255"`
+	LockedAt time.Time
+}
+
+// lockTable name of lock table
+func (m *Migrator) lockTable() string { return m.table + "_lock" }
+
+// lock insert the lock row, waiting for the other holder until lock timeout.
+// The primary key makes the insert fail while the row exists, which works the same on every database.
+func (m *Migrator) lock() error {
+	deadline := time.Now().Add(m.lockTimeout)
+	for released := false; ; {
+		err := m.quiet.Table(m.lockTable()).Create(&lockRecord{ID: 1, Owner: m.owner, LockedAt: time.Now()}).Error
+		if err == nil {
+			return nil
+		}
+
+		var holder lockRecord
+		if e := m.quiet.Table(m.lockTable()).Where("id = ?", 1).Limit(1).Find(&holder).Error; e != nil {
+			return fmt.Errorf("acquire migration lock fail: %w", err)
+		}
+		if holder.ID == 0 {
+			// released just now, otherwise insert fails for another reason, e.g. lock table of wrong schema
+			if released {
+				return fmt.Errorf("acquire migration lock fail: %w", err)
+			}
+			released = true
+			continue
+		}
+		released = false
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%w: %s since %s, remove it with Unlock if the holder is gone", ErrLocked, holder.Owner, holder.LockedAt.Format(time.RFC3339))
+		}
+		time.Sleep(m.lockRetry)
+	}
+}
+
+// unlock delete the lock row held by m
+func (m *Migrator) unlock() error {
+	return m.db.Table(m.lockTable()).Where("id = ? AND owner = ?", 1, m.owner).Delete(&lockRecord{}).Error
+}
+
+// Unlock release lock held by any instance, for recovering from an instance exiting while migrating
+func (m *Migrator) Unlock() error {
+	if err := m.prepare(); err != nil {
+		return err
+	}
+	return m.db.Table(m.lockTable()).Where("id = ?", 1).Delete(&lockRecord{}).Error
+}
+
+// withLock run fn holding the lock
+func (m *Migrator) withLock(fn func() error) (err error) {
+	if err = m.prepare(); err != nil {
+		return err
+	}
+	if err = m.lock(); err != nil {
+		return err
+	}
+	defer func() {
+		if e := m.unlock(); e != nil && err == nil {
+			err = fmt.Errorf("release migration lock fail: %w", e)
+		}
+	}()
+	return fn()
+}
+
+func lockOwner() string {
+	host, _ := os.Hostname()
+	return fmt.Sprintf("%s:%d:%d", host, os.Getpid(), time.Now().UnixNano())
+}
@@ -0,0 +1,332 @@
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// DefaultTable default name of history table
+const DefaultTable = "gen_migrations"
+
+// ErrChecksumMismatch sql of applied migration is changed since it was applied
+var ErrChecksumMismatch = errors.New("migration is changed after it was applied")
+
+// Config options of Migrator
+type Config struct {
+	Dir         string        // directory of <version>_<name>.up.sql and optional <version>_<name>.down.sql files, empty for go migrations only
+	FS          fs.FS         // file system Dir is read from, default: os file system
+	Table       string        // history table, default: gen_migrations, lock table is <Table>_lock
+	LockTimeout time.Duration // how long to wait for lock held by another instance, default: 1 minute
+
+	Logf func(format string, args ...interface{}) // print progress, nil to be quiet
+}
+
+// Record applied migration in history table
+type Record struct {
+	Version   int64  `gorm:"primaryKey;autoIncrement:false"`
+	Name      string `gorm:"size:255"`
+	Checksum  string `gorm:"size:64"`
+	AppliedAt time.Time
+}
+
+// Status migration with its state in history table
+type Status struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+	Changed   bool // sql is changed since applied
+	Missing   bool // applied but neither file nor go migration exists
+}
+
+// Migrator apply migrations of Config.Dir and registered go migrations
+type Migrator struct {
+	db          *gorm.DB
+	quiet       *gorm.DB // session without error log, failing lock insert is expected
+	fsys        fs.FS
+	dir         string
+	table       string
+	lockTimeout time.Duration
+	lockRetry   time.Duration
+	owner       string
+	logf        func(format string, args ...interface{})
+	registered  []*Migration
+}
+
+// New create migrator of db
+func New(db *gorm.DB, cfg Config) *Migrator {
+	m := &Migrator{
+		db:          db,
+		quiet:       db.Session(&gorm.Session{Logger: db.Logger.LogMode(logger.Silent)}),
+		fsys:        cfg.FS,
+		dir:         cfg.Dir,
+		table:       cfg.Table,
+		lockTimeout: cfg.LockTimeout,
+		lockRetry:   500 * time.Millisecond,
+		owner:       lockOwner(),
+		logf:        cfg.Logf,
+	}
+	if m.fsys == nil && m.dir != "" {
+		m.fsys, m.dir = os.DirFS(m.dir), "."
+	}
+	if m.table == "" {
+		m.table = DefaultTable
+	}
+	if m.lockTimeout <= 0 {
+		m.lockTimeout = time.Minute
+	}
+	if m.lockRetry > m.lockTimeout {
+		m.lockRetry = m.lockTimeout
+	}
+	if m.logf == nil {
+		m.logf = func(string, ...interface{}) {}
+	}
+	return m
+}
+
+// Register add go migrations, their versions share the sequence of sql migrations
+func (m *Migrator) Register(migrations ...*Migration) {
+	m.registered = append(m.registered, migrations...)
+}
+
+// Migrations return sql and go migrations sorted by version
+func (m *Migrator) Migrations() ([]*Migration, error) {
+	var migrations []*Migration
+	if m.fsys != nil {
+		files, err := loadFiles(m.fsys, m.dir)
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, files...)
+	}
+	migrations = append(migrations, m.registered...)
+	sortMigrations(migrations)
+
+	for i, mg := range migrations {
+		if mg.Up == nil && mg.UpSQL == "" {
+			return nil, fmt.Errorf("migration %s has no up", mg)
+		}
+		if i > 0 && migrations[i-1].Version == mg.Version {
+			return nil, fmt.Errorf("migration version %d is used by both %s and %s", mg.Version, migrations[i-1], mg)
+		}
+	}
+	return migrations, nil
+}
+
+// Status return state of every migration, including applied ones which no longer exist
+func (m *Migrator) Status() ([]Status, error) {
+	if err := m.prepare(); err != nil {
+		return nil, err
+	}
+	migrations, applied, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Status, 0, len(migrations))
+	known := make(map[int64]bool, len(migrations))
+	for _, mg := range migrations {
+		known[mg.Version] = true
+		s := Status{Version: mg.Version, Name: mg.Name}
+		if r, ok := applied[mg.Version]; ok {
+			s.Applied, s.AppliedAt = true, r.AppliedAt
+			s.Changed = changed(mg, r)
+		}
+		result = append(result, s)
+	}
+	for _, r := range applied {
+		if !known[r.Version] {
+			result = append(result, Status{Version: r.Version, Name: r.Name, Applied: true, AppliedAt: r.AppliedAt, Missing: true})
+		}
+	}
+	sortStatus(result)
+	return result, nil
+}
+
+// Up apply every pending migration in order of version
+func (m *Migrator) Up() error {
+	return m.withLock(func() error {
+		migrations, applied, err := m.verified()
+		if err != nil {
+			return err
+		}
+		count := 0
+		for _, mg := range migrations {
+			if _, ok := applied[mg.Version]; ok {
+				continue
+			}
+			if err = m.apply(mg); err != nil {
+				return err
+			}
+			count++
+		}
+		if count == 0 {
+			m.logf("no pending migration")
+		}
+		return nil
+	})
+}
+
+// Down revert the last applied migration
+func (m *Migrator) Down() error {
+	return m.withLock(func() error {
+		migrations, applied, err := m.verified()
+		if err != nil {
+			return err
+		}
+		last := latest(applied)
+		if last == nil {
+			m.logf("no applied migration")
+			return nil
+		}
+		return m.revert(find(migrations, last.Version), *last)
+	})
+}
+
+// Goto apply or revert migrations until version is the last applied one, version 0 reverts all migrations
+func (m *Migrator) Goto(version int64) error {
+	return m.withLock(func() error {
+		migrations, applied, err := m.verified()
+		if err != nil {
+			return err
+		}
+		if version != 0 && find(migrations, version) == nil {
+			return fmt.Errorf("migration version %d not found", version)
+		}
+
+		for last := latest(applied); last != nil && last.Version > version; last = latest(applied) {
+			if err = m.revert(find(migrations, last.Version), *last); err != nil {
+				return err
+			}
+			delete(applied, last.Version)
+		}
+		for _, mg := range migrations {
+			if _, ok := applied[mg.Version]; ok || mg.Version > version {
+				continue
+			}
+			if err = m.apply(mg); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// prepare create history and lock tables if they don't exist
+func (m *Migrator) prepare() error {
+	for table, model := range map[string]interface{}{m.table: &Record{}, m.lockTable(): &lockRecord{}} {
+		if m.quiet.Migrator().HasTable(table) {
+			continue
+		}
+		// another instance may be creating it at the same time
+		if err := m.quiet.Table(table).Migrator().CreateTable(model); err != nil && !m.quiet.Migrator().HasTable(table) {
+			return fmt.Errorf("create table %s fail: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// load return migrations and applied records by version
+func (m *Migrator) load() ([]*Migration, map[int64]Record, error) {
+	migrations, err := m.Migrations()
+	if err != nil {
+		return nil, nil, err
+	}
+	var records []Record
+	if err = m.db.Table(m.table).Order("version").Find(&records).Error; err != nil {
+		return nil, nil, fmt.Errorf("read migration history fail: %w", err)
+	}
+	applied := make(map[int64]Record, len(records))
+	for _, r := range records {
+		applied[r.Version] = r
+	}
+	return migrations, applied, nil
+}
+
+// verified load migrations, refusing to migrate when applied ones are changed
+func (m *Migrator) verified() ([]*Migration, map[int64]Record, error) {
+	migrations, applied, err := m.load()
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, mg := range migrations {
+		if r, ok := applied[mg.Version]; ok && changed(mg, r) {
+			return nil, nil, fmt.Errorf("%w: %s", ErrChecksumMismatch, mg)
+		}
+	}
+	return migrations, applied, nil
+}
+
+// apply run up of migration and record it in one transaction
+func (m *Migrator) apply(mg *Migration) error {
+	start := time.Now()
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		if err := mg.run(tx, true); err != nil {
+			return err
+		}
+		return tx.Table(m.table).Create(&Record{Version: mg.Version, Name: mg.Name, Checksum: mg.Checksum(), AppliedAt: time.Now()}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("apply migration %s fail: %w", mg, err)
+	}
+	m.logf("applied %s (%s)", mg, time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+// revert run down of migration and remove its record in one transaction
+func (m *Migrator) revert(mg *Migration, r Record) error {
+	if mg == nil {
+		return fmt.Errorf("migration %d_%s is applied but not found, can not revert it", r.Version, r.Name)
+	}
+	if !mg.HasDown() {
+		return fmt.Errorf("migration %s has no down, can not revert it", mg)
+	}
+	start := time.Now()
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		if err := mg.run(tx, false); err != nil {
+			return err
+		}
+		return tx.Table(m.table).Where("version = ?", mg.Version).Delete(&Record{}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("revert migration %s fail: %w", mg, err)
+	}
+	m.logf("reverted %s (%s)", mg, time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+func changed(mg *Migration, r Record) bool {
+	sum := mg.Checksum()
+	return sum != "" && r.Checksum != "" && sum != r.Checksum
+}
+
+func find(migrations []*Migration, version int64) *Migration {
+	for _, mg := range migrations {
+		if mg.Version == version {
+			return mg
+		}
+	}
+	return nil
+}
+
+func latest(applied map[int64]Record) *Record {
+	var last *Record
+	for _, r := range applied {
+		r := r
+		if last == nil || r.Version > last.Version {
+			last = &r
+		}
+	}
+	return last
+}
+
+func sortStatus(s []Status) {
+	sort.Slice(s, func(i, j int) bool { return s[i].Version < s[j].Version })
+}
@@ -0,0 +1,203 @@
+package migrate
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newTestMigrator(t *testing.T, files map[string]string) (*Migrator, *gorm.DB, string) {
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "migrate.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite fail: %s", err)
+	}
+	return New(db, Config{Dir: dir, LockTimeout: 100 * time.Millisecond}), db, dir
+}
+
+var testFiles = map[string]string{
+	"1_users.up.sql":        "CREATE TABLE users (id integer primary key, name text);\n-- seed\nINSERT INTO users (name) VALUES ('a;b');",
+	"1_users.down.sql":      "DROP TABLE users;",
+	"3_orders.up.sql":       "CREATE TABLE orders (id integer primary key, user_id integer);",
+	"3_orders.down.sql":     "DROP TABLE orders;",
+	"4_index.up.sql":        "CREATE INDEX idx_orders_user_id ON orders (user_id);",
+	"readme.md":             "not a migration",
+	"4_index.down.sql":      "DROP INDEX idx_orders_user_id;",
+	"5_irreversible.up.sql": "ALTER TABLE users ADD email text;",
+}
+
+func appliedVersions(t *testing.T, m *Migrator) (versions []int64) {
+	status, err := m.Status()
+	if err != nil {
+		t.Fatalf("status fail: %s", err)
+	}
+	for _, s := range status {
+		if s.Applied {
+			versions = append(versions, s.Version)
+		}
+	}
+	return versions
+}
+
+func TestMigrator(t *testing.T) {
+	m, db, _ := newTestMigrator(t, testFiles)
+	m.Register(&Migration{
+		Version: 2,
+		Name:    "seed",
+		Up:      func(tx *gorm.DB) error { return tx.Exec("INSERT INTO users (name) VALUES ('go')").Error },
+		Down:    func(tx *gorm.DB) error { return tx.Exec("DELETE FROM users WHERE name = 'go'").Error },
+	})
+
+	if err := m.Up(); err != nil {
+		t.Fatalf("up fail: %s", err)
+	}
+	if got := appliedVersions(t, m); len(got) != 5 {
+		t.Fatalf("expect 5 applied migrations, got %v", got)
+	}
+	var names []string
+	db.Table("users").Order("id").Pluck("name", &names)
+	if len(names) != 2 || names[0] != "a;b" || names[1] != "go" {
+		t.Errorf("expect rows of sql and go migration, got %v", names)
+	}
+	if !db.Migrator().HasColumn("users", "email") {
+		t.Errorf("expect column users.email added")
+	}
+
+	// 5 has no down file
+	if err := m.Down(); err == nil {
+		t.Errorf("expect down of migration without down file fail")
+	}
+	if err := m.Goto(2); err == nil {
+		t.Errorf("expect goto reverting migration without down file fail")
+	}
+
+	m, _, _ = newTestMigrator(t, nil)
+	m.Register(&Migration{Version: 1, Name: "ok", Up: func(*gorm.DB) error { return nil }})
+	if err := m.Up(); err != nil {
+		t.Fatalf("up fail: %s", err)
+	}
+}
+
+func TestMigrator_Goto(t *testing.T) {
+	files := make(map[string]string)
+	for name, content := range testFiles {
+		if name != "5_irreversible.up.sql" {
+			files[name] = content
+		}
+	}
+	m, db, _ := newTestMigrator(t, files)
+
+	if err := m.Goto(3); err != nil {
+		t.Fatalf("goto 3 fail: %s", err)
+	}
+	if got := appliedVersions(t, m); len(got) != 2 || got[1] != 3 {
+		t.Fatalf("expect 1 and 3 applied, got %v", got)
+	}
+	if err := m.Up(); err != nil {
+		t.Fatalf("up fail: %s", err)
+	}
+	if err := m.Down(); err != nil {
+		t.Fatalf("down fail: %s", err)
+	}
+	if got := appliedVersions(t, m); len(got) != 2 {
+		t.Fatalf("expect 1 and 3 applied after down, got %v", got)
+	}
+	if err := m.Goto(0); err != nil {
+		t.Fatalf("goto 0 fail: %s", err)
+	}
+	if got := appliedVersions(t, m); len(got) != 0 || db.Migrator().HasTable("users") {
+		t.Fatalf("expect every migration reverted, got %v", got)
+	}
+	if err := m.Goto(2); err == nil {
+		t.Errorf("expect goto unknown version fail")
+	}
+}
+
+func TestMigrator_Checksum(t *testing.T) {
+	m, _, dir := newTestMigrator(t, map[string]string{"1_users.up.sql": "CREATE TABLE users (id integer primary key);"})
+	if err := m.Up(); err != nil {
+		t.Fatalf("up fail: %s", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "1_users.up.sql"), []byte("CREATE TABLE users (id integer primary key, name text);"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Up(); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("expect ErrChecksumMismatch, got %v", err)
+	}
+	status, err := m.Status()
+	if err != nil || len(status) != 1 || !status[0].Changed {
+		t.Errorf("expect status of changed migration, got %+v %v", status, err)
+	}
+
+	if err = os.Remove(filepath.Join(dir, "1_users.up.sql")); err != nil {
+		t.Fatal(err)
+	}
+	status, err = m.Status()
+	if err != nil || len(status) != 1 || !status[0].Missing {
+		t.Errorf("expect status of missing migration, got %+v %v", status, err)
+	}
+}
+
+func TestMigrator_Lock(t *testing.T) {
+	m, db, dir := newTestMigrator(t, map[string]string{"1_users.up.sql": "CREATE TABLE users (id integer primary key);"})
+	other := New(db, Config{Dir: dir, LockTimeout: 100 * time.Millisecond})
+	if err := other.prepare(); err != nil {
+		t.Fatal(err)
+	}
+	if err := other.lock(); err != nil {
+		t.Fatalf("lock fail: %s", err)
+	}
+
+	if err := m.Up(); !errors.Is(err, ErrLocked) {
+		t.Fatalf("expect ErrLocked while other instance holds lock, got %v", err)
+	}
+	if err := m.Unlock(); err != nil {
+		t.Fatalf("unlock fail: %s", err)
+	}
+	if err := m.Up(); err != nil {
+		t.Fatalf("up fail after unlock: %s", err)
+	}
+}
+
+func TestMigrator_LockFail(t *testing.T) {
+	m, db, _ := newTestMigrator(t, map[string]string{"1_users.up.sql": "CREATE TABLE users (id integer primary key);"})
+	if err := db.Exec("CREATE TABLE gen_migrations_lock (id integer primary key, locked_at datetime)").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- m.Up() }()
+	select {
+	case err := <-done:
+		if err == nil || errors.Is(err, ErrLocked) {
+			t.Fatalf("expect insert error of lock table without owner, got %v", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("up keeps retrying lock which can not be inserted")
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	sql := `-- comment only;
+CREATE TABLE t (a text DEFAULT 'x;y', "b;" int); /* c; */
+CREATE FUNCTION f() RETURNS trigger AS $body$ BEGIN RETURN NULL; END; $body$ LANGUAGE plpgsql;
+SELECT $1;
+-- trailing comment`
+	stmts := splitStatements(sql)
+	if len(stmts) != 3 {
+		t.Fatalf("expect 3 statements, got %d: %q", len(stmts), stmts)
+	}
+	if stmts[1] != "/* c; */\nCREATE FUNCTION f() RETURNS trigger AS $body$ BEGIN RETURN NULL; END; $body$ LANGUAGE plpgsql" {
+		t.Errorf("unexpected function statement %q", stmts[1])
+	}
+}
@@ -0,0 +1,99 @@
+package migrate
+
+import (
+	"strings"
+)
+
+// splitStatements split sql script into statements on semicolons outside of quotes, comments and
+// dollar quoted bodies of postgres functions, statements with only comments are dropped
+func splitStatements(sql string) (stmts []string) {
+	var (
+		b       strings.Builder
+		hasCode bool // statement has text other than comments
+	)
+	flush := func() {
+		if stmt := strings.TrimSpace(b.String()); hasCode && stmt != "" {
+			stmts = append(stmts, stmt)
+		}
+		b.Reset()
+		hasCode = false
+	}
+
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case c == '-' && strings.HasPrefix(sql[i:], "--"):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				end = len(sql) - i
+			}
+			b.WriteString(sql[i : i+end])
+			i += end - 1
+		case c == '/' && strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				end = len(sql) - i - 4
+			}
+			b.WriteString(sql[i : i+end+4])
+			i += end + 3
+		case c == '\'' || c == '"' || c == '`':
+			end := i + 1
+			for end < len(sql) {
+				if sql[end] == '\\' && c != '`' {
+					end += 2
+					continue
+				}
+				if sql[end] == c {
+					if end+1 < len(sql) && sql[end+1] == c {
+						end += 2
+						continue
+					}
+					break
+				}
+				end++
+			}
+			if end >= len(sql) {
+				end = len(sql) - 1
+			}
+			b.WriteString(sql[i : end+1])
+			hasCode = true
+			i = end
+		case c == '$':
+			if tag := dollarTag(sql[i:]); tag != "" {
+				end := strings.Index(sql[i+len(tag):], tag)
+				if end < 0 {
+					end = len(sql) - i - 2*len(tag)
+				}
+				b.WriteString(sql[i : i+2*len(tag)+end])
+				hasCode = true
+				i += 2*len(tag) + end - 1
+				continue
+			}
+			b.WriteByte(c)
+			hasCode = true
+		case c == ';':
+			flush()
+		default:
+			b.WriteByte(c)
+			hasCode = hasCode || !isSpace(c)
+		}
+	}
+	flush()
+	return stmts
+}
+
+// dollarTag return $tag$ opening s, empty if s does not start with one
+func dollarTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '$':
+			return s[:i+1]
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || (i > 1 && c >= '0' && c <= '9'):
+		default:
+			return ""
+		}
+	}
+	return ""
+}
+
+func isSpace(c byte) bool { return c == ' ' || c == '\t' || c == '\n' || c == '\r' }
@@ -0,0 +1,686 @@
+package gen
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+// ChangeKind kind of schema change in MigrationPlan
+type ChangeKind string
+
+const (
+	ChangeCreateTable    ChangeKind = "create_table"
+	ChangeAddColumn      ChangeKind = "add_column"
+	ChangeDropColumn     ChangeKind = "drop_column"
+	ChangeAlterColumn    ChangeKind = "alter_column"
+	ChangeCreateIndex    ChangeKind = "create_index"
+	ChangeDropIndex      ChangeKind = "drop_index"
+	ChangeAddConstraint  ChangeKind = "add_constraint"
+	ChangeDropConstraint ChangeKind = "drop_constraint"
+)
+
+// SchemaChange difference between a model and live schema, with statements applying and reverting it
+type SchemaChange struct {
+	Kind        ChangeKind
+	Table       string
+	Name        string   // column, index or constraint name, empty for table
+	Detail      string   // human readable description of the difference
+	Up          []string // statements applying the change
+	Down        []string // statements reverting the change
+	Destructive bool     // change may lose data, e.g. drop column or narrow column type
+}
+
+// MigrationPlan changes syncing live schema with models, see PlanMigration
+type MigrationPlan struct {
+	Dialect  string
+	Changes  []SchemaChange
+	Warnings []string // parts of schema not compared, e.g. indexes unsupported by migrator
+}
+
+// WithMigrationPlan write up/down sql migration files into dir when linking models, instead of auto migrating database
+func (cfg *Config) WithMigrationPlan(dir string) {
+	cfg.migrationDir = dir
+}
+
+// Empty report whether plan has no change
+func (p *MigrationPlan) Empty() bool { return len(p.Changes) == 0 }
+
+// Destructive return changes which may lose data
+func (p *MigrationPlan) Destructive() (changes []SchemaChange) {
+	for _, c := range p.Changes {
+		if c.Destructive {
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
+
+// UpSQL return statements applying plan
+func (p *MigrationPlan) UpSQL() string {
+	var b strings.Builder
+	p.writeHeader(&b, "up")
+	for _, c := range p.Changes {
+		writeChange(&b, c, c.Up)
+	}
+	return b.String()
+}
+
+// DownSQL return statements reverting plan, changes are reverted in reverse order
+func (p *MigrationPlan) DownSQL() string {
+	var b strings.Builder
+	p.writeHeader(&b, "down")
+	for i := len(p.Changes) - 1; i >= 0; i-- {
+		writeChange(&b, p.Changes[i], p.Changes[i].Down)
+	}
+	return b.String()
+}
+
+// WriteFiles write <version>_<name>.up.sql and <version>_<name>.down.sql into dir, version is UTC time of writing
+func (p *MigrationPlan) WriteFiles(out Output, dir, name string) (up, down string, err error) {
+	if err = out.MkdirAll(dir); err != nil {
+		return "", "", fmt.Errorf("create migration dir fail: %w", err)
+	}
+	base := filepath.Join(dir, time.Now().UTC().Format("20060102150405")+"_"+name)
+	up, down = base+".up.sql", base+".down.sql"
+	if err = out.WriteFile(up, []byte(p.UpSQL())); err != nil {
+		return "", "", err
+	}
+	if err = out.WriteFile(down, []byte(p.DownSQL())); err != nil {
+		return "", "", err
+	}
+	return up, down, nil
+}
+
+func (p *MigrationPlan) writeHeader(b *strings.Builder, direction string) {
+	fmt.Fprintf(b, "-- migration %s, dialect: %s, planned by gorm.io/gen, review before applying\n", direction, p.Dialect)
+	for _, w := range p.Warnings {
+		fmt.Fprintf(b, "-- WARNING: %s\n", w)
+	}
+	if n := len(p.Destructive()); n > 0 {
+		fmt.Fprintf(b, "-- WARNING: %d destructive changes, marked DESTRUCTIVE below\n", n)
+	}
+}
+
+func writeChange(b *strings.Builder, c SchemaChange, stmts []string) {
+	b.WriteString("\n-- " + string(c.Kind) + " " + c.Table)
+	if c.Name != "" {
+		b.WriteString("." + c.Name)
+	}
+	if c.Detail != "" {
+		b.WriteString(": " + c.Detail)
+	}
+	b.WriteString("\n")
+	if c.Destructive {
+		b.WriteString("-- DESTRUCTIVE: may lose data\n")
+	}
+	for _, stmt := range stmts {
+		if strings.HasPrefix(stmt, "--") {
+			b.WriteString(stmt + "\n")
+		} else {
+			b.WriteString(stmt + ";\n")
+		}
+	}
+}
+
+// PlanMigration compare models with live schema of db and plan changes syncing schema with models, db is not changed
+func PlanMigration(db *gorm.DB, models ...interface{}) (*MigrationPlan, error) {
+	rec := &sqlRecorder{Interface: logger.Discard}
+	p := &planner{
+		db:   db,
+		dry:  db.Session(&gorm.Session{DryRun: true, Logger: rec, NewDB: true}),
+		rec:  rec,
+		plan: &MigrationPlan{Dialect: db.Dialector.Name()},
+	}
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, fmt.Errorf("parse model %T fail: %w", model, err)
+		}
+		if err := p.planTable(model, stmt.Schema); err != nil {
+			return nil, fmt.Errorf("table %s: %w", stmt.Schema.Table, err)
+		}
+	}
+	return p.plan, nil
+}
+
+// planner build statements of MigrationPlan
+type planner struct {
+	db   *gorm.DB
+	dry  *gorm.DB // dry run session rendering statements
+	rec  *sqlRecorder
+	plan *MigrationPlan
+}
+
+// sql render statement of dialect without executing it
+func (p *planner) sql(query string, vars ...interface{}) string {
+	p.rec.stmts = p.rec.stmts[:0]
+	p.dry.Exec(query, vars...)
+	if len(p.rec.stmts) == 0 {
+		return ""
+	}
+	return p.rec.stmts[0]
+}
+
+func (p *planner) add(c SchemaChange) { p.plan.Changes = append(p.plan.Changes, c) }
+
+func (p *planner) unsupported(op, table string) string {
+	return fmt.Sprintf("-- %s is not supported by %s, change table %s manually", op, p.plan.Dialect, table)
+}
+
+func (p *planner) planTable(model interface{}, s *schema.Schema) error {
+	m := p.db.Migrator()
+	if !m.HasTable(model) {
+		p.createTable(s)
+		return nil
+	}
+
+	columnTypes, err := m.ColumnTypes(model)
+	if err != nil {
+		return fmt.Errorf("get columns fail: %w", err)
+	}
+	existing := make(map[string]gorm.ColumnType, len(columnTypes))
+	for _, col := range columnTypes {
+		existing[col.Name()] = col
+	}
+	for _, name := range s.DBNames {
+		f := s.FieldsByDBName[name]
+		if f.IgnoreMigration {
+			continue
+		}
+		col, ok := existing[name]
+		if !ok {
+			p.addColumn(s, f)
+		} else if detail, destructive := p.columnDiff(f, col); detail != "" {
+			p.alterColumn(s, f, col, detail, destructive)
+		}
+	}
+	for _, col := range columnTypes {
+		if _, ok := s.FieldsByDBName[col.Name()]; !ok {
+			p.dropColumn(s, col)
+		}
+	}
+
+	p.planIndexes(model, s)
+	return p.planConstraints(model, s)
+}
+
+func (p *planner) createTable(s *schema.Schema) {
+	table := clause.Table{Name: s.Table}
+	query := "CREATE TABLE ? ("
+	vars := []interface{}{table}
+	hasPrimaryKeyInDataType := false
+	for _, name := range s.DBNames {
+		f := s.FieldsByDBName[name]
+		if f.IgnoreMigration {
+			continue
+		}
+		query += "? ?,"
+		hasPrimaryKeyInDataType = hasPrimaryKeyInDataType || strings.Contains(strings.ToUpper(string(f.DataType)), "PRIMARY KEY")
+		vars = append(vars, clause.Column{Name: name}, p.db.Migrator().FullDataTypeOf(f))
+	}
+	if !hasPrimaryKeyInDataType && len(s.PrimaryFields) > 0 {
+		query += "PRIMARY KEY ?,"
+		vars = append(vars, fieldColumns(s.PrimaryFields))
+	}
+	if !p.db.DisableForeignKeyConstraintWhenMigrating {
+		for _, c := range modelConstraints(s) {
+			query += "CONSTRAINT ? FOREIGN KEY ? REFERENCES ??" + constraintActions(c) + ","
+			vars = append(vars, clause.Column{Name: c.Name}, fieldColumns(c.ForeignKeys), clause.Table{Name: c.ReferenceSchema.Table}, fieldColumns(c.References))
+		}
+	}
+	for _, chk := range modelChecks(s) {
+		query += "CONSTRAINT ? CHECK (?),"
+		vars = append(vars, clause.Column{Name: chk.Name}, clause.Expr{SQL: chk.Constraint})
+	}
+	up := []string{p.sql(strings.TrimSuffix(query, ",")+")", vars...)}
+	for _, idx := range modelIndexes(s) {
+		up = append(up, p.createIndexSQL(s.Table, idx))
+	}
+	p.add(SchemaChange{
+		Kind:  ChangeCreateTable,
+		Table: s.Table,
+		Up:    up,
+		Down:  []string{p.sql("DROP TABLE ?", table)},
+	})
+}
+
+func (p *planner) addColumn(s *schema.Schema, f *schema.Field) {
+	table, column := clause.Table{Name: s.Table}, clause.Column{Name: f.DBName}
+	p.add(SchemaChange{
+		Kind:   ChangeAddColumn,
+		Table:  s.Table,
+		Name:   f.DBName,
+		Detail: strings.TrimSpace(p.db.Migrator().FullDataTypeOf(f).SQL),
+		Up:     []string{p.sql("ALTER TABLE ? ADD ? ?", table, column, p.db.Migrator().FullDataTypeOf(f))},
+		Down:   []string{p.sql("ALTER TABLE ? DROP COLUMN ?", table, column)},
+	})
+}
+
+func (p *planner) dropColumn(s *schema.Schema, col gorm.ColumnType) {
+	table, column := clause.Table{Name: s.Table}, clause.Column{Name: col.Name()}
+	p.add(SchemaChange{
+		Kind:        ChangeDropColumn,
+		Table:       s.Table,
+		Name:        col.Name(),
+		Detail:      "column not in model",
+		Up:          []string{p.sql("ALTER TABLE ? DROP COLUMN ?", table, column)},
+		Down:        []string{p.sql("ALTER TABLE ? ADD ? ?", table, column, clause.Expr{SQL: p.columnDefinition(col)})},
+		Destructive: true,
+	})
+}
+
+// columnDiff describe difference between field and column the way gorm AutoMigrate compares them
+func (p *planner) columnDiff(f *schema.Field, col gorm.ColumnType) (detail string, destructive bool) {
+	var details []string
+	fullDataType := strings.TrimSpace(strings.ToLower(p.db.Migrator().FullDataTypeOf(f).SQL))
+	realDataType := strings.ToLower(col.DatabaseTypeName())
+
+	sameType := strings.HasPrefix(fullDataType, realDataType)
+	for _, alias := range p.db.Migrator().GetTypeAliases(realDataType) {
+		sameType = sameType || strings.HasPrefix(fullDataType, alias)
+	}
+	switch {
+	case !sameType:
+		details = append(details, fmt.Sprintf("type %s -> %s", columnTypeOf(col), p.db.Dialector.DataTypeOf(f)))
+		destructive = true
+	case f.Size > 0:
+		if length, ok := col.Length(); ok && length > 0 && length != int64(f.Size) {
+			details = append(details, fmt.Sprintf("size %d -> %d", length, f.Size))
+			destructive = int64(f.Size) < length
+		}
+	}
+	if nullable, ok := p.nullable(col); ok && !f.PrimaryKey && nullable == f.NotNull {
+		if f.NotNull {
+			details = append(details, "null -> not null")
+		} else {
+			details = append(details, "not null -> null")
+		}
+	}
+	return strings.Join(details, ", "), destructive
+}
+
+func (p *planner) alterColumn(s *schema.Schema, f *schema.Field, col gorm.ColumnType, detail string, destructive bool) {
+	table, column := clause.Table{Name: s.Table}, clause.Column{Name: f.DBName}
+	dataType := clause.Expr{SQL: p.db.Dialector.DataTypeOf(f)}
+	oldType := clause.Expr{SQL: columnTypeOf(col)}
+	oldNullable, _ := p.nullable(col)
+
+	c := SchemaChange{Kind: ChangeAlterColumn, Table: s.Table, Name: f.DBName, Detail: detail, Destructive: destructive}
+	switch p.plan.Dialect {
+	case "mysql":
+		c.Up = []string{p.sql("ALTER TABLE ? MODIFY COLUMN ? ?", table, column, p.db.Migrator().FullDataTypeOf(f))}
+		c.Down = []string{p.sql("ALTER TABLE ? MODIFY COLUMN ? ?", table, column, clause.Expr{SQL: p.columnDefinition(col)})}
+	case "postgres":
+		c.Up = []string{p.sql("ALTER TABLE ? ALTER COLUMN ? TYPE ? USING ?::?", table, column, dataType, column, dataType)}
+		c.Down = []string{p.sql("ALTER TABLE ? ALTER COLUMN ? TYPE ? USING ?::?", table, column, oldType, column, oldType)}
+		if oldNullable == f.NotNull {
+			c.Up = append(c.Up, p.sql("ALTER TABLE ? ALTER COLUMN ? "+nullClause(!f.NotNull), table, column))
+			c.Down = append(c.Down, p.sql("ALTER TABLE ? ALTER COLUMN ? "+nullClause(oldNullable), table, column))
+		}
+	case "sqlserver":
+		c.Up = []string{p.sql("ALTER TABLE ? ALTER COLUMN ? ?"+notNull(f.NotNull), table, column, dataType)}
+		c.Down = []string{p.sql("ALTER TABLE ? ALTER COLUMN ? ?"+notNull(!oldNullable), table, column, oldType)}
+	default:
+		c.Up = []string{p.unsupported("ALTER COLUMN", s.Table)}
+		c.Down = []string{p.unsupported("ALTER COLUMN", s.Table)}
+	}
+	p.add(c)
+}
+
+func (p *planner) planIndexes(model interface{}, s *schema.Schema) {
+	indexes, err := p.db.Migrator().GetIndexes(model)
+	if err != nil {
+		p.plan.Warnings = append(p.plan.Warnings, fmt.Sprintf("indexes of table %s are not compared: %s", s.Table, err))
+		return
+	}
+	existing := make(map[string]gorm.Index, len(indexes))
+	for _, idx := range indexes {
+		existing[idx.Name()] = idx
+	}
+
+	for _, idx := range modelIndexes(s) {
+		if _, ok := existing[idx.Name]; ok {
+			continue
+		}
+		p.add(SchemaChange{
+			Kind:  ChangeCreateIndex,
+			Table: s.Table,
+			Name:  idx.Name,
+			Up:    []string{p.createIndexSQL(s.Table, idx)},
+			Down:  []string{p.dropIndexSQL(s.Table, idx.Name)},
+		})
+	}
+
+	constraints := make(map[string]bool)
+	for _, c := range modelConstraints(s) {
+		constraints[c.Name] = true
+	}
+	for _, idx := range indexes {
+		if s.LookIndex(idx.Name()) != nil || constraints[idx.Name()] || !p.droppableIndex(s, idx) {
+			continue
+		}
+		unique, _ := idx.Unique()
+		query := "CREATE INDEX ? ON ??"
+		if unique {
+			query = "CREATE UNIQUE INDEX ? ON ??"
+		}
+		var columns []interface{}
+		for _, name := range idx.Columns() {
+			columns = append(columns, clause.Column{Name: name})
+		}
+		p.add(SchemaChange{
+			Kind:   ChangeDropIndex,
+			Table:  s.Table,
+			Name:   idx.Name(),
+			Detail: "index not in model",
+			Up:     []string{p.dropIndexSQL(s.Table, idx.Name())},
+			Down:   []string{p.sql(query, clause.Column{Name: idx.Name()}, clause.Table{Name: s.Table}, columns)},
+		})
+	}
+}
+
+// droppableIndex report whether index of database not declared in model is dropped,
+// indexes created for primary key or unique fields are kept
+func (p *planner) droppableIndex(s *schema.Schema, idx gorm.Index) bool {
+	if pk, ok := idx.PrimaryKey(); (ok && pk) || strings.HasPrefix(idx.Name(), "sqlite_autoindex_") {
+		return false
+	}
+	if columns := idx.Columns(); len(columns) == 1 {
+		if f := s.FieldsByDBName[columns[0]]; f != nil && (f.Unique || f.PrimaryKey) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *planner) createIndexSQL(table string, idx schema.Index) string {
+	query := "CREATE "
+	if idx.Class != "" {
+		query += idx.Class + " "
+	}
+	query += "INDEX ? ON ?"
+	if idx.Type != "" && p.plan.Dialect == "postgres" {
+		query += " USING " + idx.Type
+	}
+	query += " ?"
+
+	var columns []interface{}
+	for _, opt := range idx.Fields {
+		str := p.dry.Statement.Quote(opt.DBName)
+		if opt.Expression != "" {
+			str = opt.Expression
+		} else if opt.Length > 0 {
+			str += fmt.Sprintf("(%d)", opt.Length)
+		}
+		if opt.Collate != "" {
+			str += " COLLATE " + opt.Collate
+		}
+		if opt.Sort != "" {
+			str += " " + opt.Sort
+		}
+		columns = append(columns, clause.Expr{SQL: str})
+	}
+	stmt := p.sql(query, clause.Column{Name: idx.Name}, clause.Table{Name: table}, columns)
+
+	// appended after rendering, ? in comment or options is not a placeholder
+	if idx.Type != "" && p.plan.Dialect == "mysql" {
+		stmt += " USING " + idx.Type
+	}
+	if idx.Comment != "" && p.plan.Dialect == "mysql" {
+		stmt += " COMMENT " + commentLiteral(idx.Comment)
+	}
+	if idx.Option != "" {
+		stmt += " " + idx.Option
+	}
+	if idx.Where != "" {
+		stmt += " WHERE " + idx.Where
+	}
+	return stmt
+}
+
+// commentLiteral quote comment as mysql string literal, quotes are doubled and backslashes escaped
+func commentLiteral(comment string) string {
+	return "'" + strings.NewReplacer("\\", "\\\\", "'", "''").Replace(comment) + "'"
+}
+
+func (p *planner) dropIndexSQL(table, name string) string {
+	switch p.plan.Dialect {
+	case "mysql", "sqlserver":
+		return p.sql("DROP INDEX ? ON ?", clause.Column{Name: name}, clause.Table{Name: table})
+	}
+	return p.sql("DROP INDEX ?", clause.Column{Name: name})
+}
+
+func (p *planner) planConstraints(model interface{}, s *schema.Schema) error {
+	table := clause.Table{Name: s.Table}
+	declared := make(map[string]bool)
+	if !p.db.DisableForeignKeyConstraintWhenMigrating {
+		for _, c := range modelConstraints(s) {
+			declared[c.Name] = true
+			if p.db.Migrator().HasConstraint(model, c.Name) {
+				continue
+			}
+			p.add(SchemaChange{
+				Kind:  ChangeAddConstraint,
+				Table: s.Table,
+				Name:  c.Name,
+				Detail: fmt.Sprintf("foreign key (%s) references %s (%s)",
+					strings.Join(fieldDBNames(c.ForeignKeys), ", "), c.ReferenceSchema.Table, strings.Join(fieldDBNames(c.References), ", ")),
+				Up: []string{p.addConstraintSQL(s.Table, "FOREIGN KEY ? REFERENCES ??"+constraintActions(c),
+					clause.Column{Name: c.Name}, fieldColumns(c.ForeignKeys), clause.Table{Name: c.ReferenceSchema.Table}, fieldColumns(c.References))},
+				Down: []string{p.dropConstraintSQL(s.Table, c.Name, "FOREIGN KEY")},
+			})
+		}
+	}
+	for _, chk := range modelChecks(s) {
+		if p.db.Migrator().HasConstraint(model, chk.Name) {
+			continue
+		}
+		p.add(SchemaChange{
+			Kind:   ChangeAddConstraint,
+			Table:  s.Table,
+			Name:   chk.Name,
+			Detail: "check " + chk.Constraint,
+			Up:     []string{p.addConstraintSQL(s.Table, "CHECK (?)", clause.Column{Name: chk.Name}, clause.Expr{SQL: chk.Constraint})},
+			Down:   []string{p.dropConstraintSQL(s.Table, chk.Name, "CHECK")},
+		})
+	}
+
+	// foreign keys of database not declared by model, sqlite foreign keys are unnamed and can not be dropped
+	dialect := LookupDialect(p.plan.Dialect)
+	if dialect == nil || dialect.ForeignKeys == nil || p.plan.Dialect == "sqlite" {
+		return nil
+	}
+	fks, err := dialect.ForeignKeys(p.db, "", s.Table)
+	if err != nil {
+		return fmt.Errorf("get foreign keys fail: %w", err)
+	}
+	for _, fk := range fks {
+		if declared[fk.Name] {
+			continue
+		}
+		p.add(SchemaChange{
+			Kind:   ChangeDropConstraint,
+			Table:  s.Table,
+			Name:   fk.Name,
+			Detail: "foreign key not in model",
+			Up:     []string{p.dropConstraintSQL(s.Table, fk.Name, "FOREIGN KEY")},
+			Down: []string{p.sql("ALTER TABLE ? ADD CONSTRAINT ? FOREIGN KEY ? REFERENCES ??", table, clause.Column{Name: fk.Name},
+				nameColumns(fk.Columns), clause.Table{Name: fk.RefTable}, nameColumns(fk.RefColumns))},
+		})
+	}
+	return nil
+}
+
+func (p *planner) addConstraintSQL(table, definition string, vars ...interface{}) string {
+	if p.plan.Dialect == "sqlite" {
+		return p.unsupported("ADD CONSTRAINT", table)
+	}
+	return p.sql("ALTER TABLE ? ADD CONSTRAINT ? "+definition, append([]interface{}{clause.Table{Name: table}}, vars...)...)
+}
+
+// dropConstraintSQL drop constraint of kind FOREIGN KEY or CHECK
+func (p *planner) dropConstraintSQL(table, name, kind string) string {
+	switch p.plan.Dialect {
+	case "sqlite":
+		return p.unsupported("DROP CONSTRAINT", table)
+	case "mysql":
+		return p.sql("ALTER TABLE ? DROP "+kind+" ?", clause.Table{Name: table}, clause.Column{Name: name})
+	}
+	return p.sql("ALTER TABLE ? DROP CONSTRAINT ?", clause.Table{Name: table}, clause.Column{Name: name})
+}
+
+// modelIndexes return indexes of model sorted by name
+func modelIndexes(s *schema.Schema) []schema.Index {
+	indexes := s.ParseIndexes()
+	result := make([]schema.Index, 0, len(indexes))
+	for _, idx := range indexes {
+		result = append(result, idx)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// modelConstraints return foreign key constraints owned by model sorted by name
+func modelConstraints(s *schema.Schema) []*schema.Constraint {
+	var result []*schema.Constraint
+	for _, rel := range s.Relationships.Relations {
+		if c := rel.ParseConstraint(); c != nil && c.Schema == s {
+			result = append(result, c)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// modelChecks return check constraints of model sorted by name
+func modelChecks(s *schema.Schema) []schema.Check {
+	checks := s.ParseCheckConstraints()
+	result := make([]schema.Check, 0, len(checks))
+	for _, chk := range checks {
+		result = append(result, chk)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+func constraintActions(c *schema.Constraint) (sql string) {
+	if c.OnDelete != "" {
+		sql += " ON DELETE " + c.OnDelete
+	}
+	if c.OnUpdate != "" {
+		sql += " ON UPDATE " + c.OnUpdate
+	}
+	return sql
+}
+
+func fieldDBNames(fields []*schema.Field) []string {
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.DBName
+	}
+	return names
+}
+
+// fieldColumns return columns of fields, rendered as (a,b)
+func fieldColumns(fields []*schema.Field) []interface{} {
+	return nameColumns(fieldDBNames(fields))
+}
+
+func nameColumns(names []string) []interface{} {
+	columns := make([]interface{}, len(names))
+	for i, name := range names {
+		columns[i] = clause.Column{Name: name}
+	}
+	return columns
+}
+
+// columnTypeOf return full type of database column, e.g. varchar(64)
+func columnTypeOf(col gorm.ColumnType) string {
+	if colType, ok := col.ColumnType(); ok && colType != "" {
+		return colType
+	}
+	return col.DatabaseTypeName()
+}
+
+// nullable return nullability of database column, sqlite driver does not report it
+func (p *planner) nullable(col gorm.ColumnType) (nullable bool, ok bool) {
+	if p.plan.Dialect == "sqlite" {
+		return false, false
+	}
+	return col.Nullable()
+}
+
+// columnDefinition return type, nullability and default of database column
+func (p *planner) columnDefinition(col gorm.ColumnType) string {
+	def := columnTypeOf(col)
+	if nullable, ok := p.nullable(col); ok && !nullable {
+		def += " NOT NULL"
+	}
+	if value, ok := col.DefaultValue(); ok && value != "" {
+		def += " DEFAULT " + defaultLiteral(value)
+	}
+	return def
+}
+
+// defaultLiteral quote default value read from database unless it is a number, keyword, expression or quoted already
+func defaultLiteral(value string) string {
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return value
+	}
+	switch {
+	case strings.HasPrefix(value, "'"), strings.ContainsAny(value, "()"), strings.ToUpper(value) == value:
+		return value
+	}
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+func nullClause(nullable bool) string {
+	if nullable {
+		return "DROP NOT NULL"
+	}
+	return "SET NOT NULL"
+}
+
+func notNull(notNull bool) string {
+	if notNull {
+		return " NOT NULL"
+	}
+	return " NULL"
+}
+
+// migrate sync database schema with linked models, migration files are written instead when migration plan is enabled
+func (r *Schema) migrate(dst ...any) error {
+	if r.migrationDir == "" {
+		return r.db.AutoMigrate(dst...)
+	}
+	plan, err := PlanMigration(r.db, dst...)
+	if err != nil {
+		return fmt.Errorf("plan migration fail: %w", err)
+	}
+	for _, w := range plan.Warnings {
+		r.report(Event{Kind: EventWarning, Message: "migration plan: " + w})
+	}
+	if plan.Empty() {
+		r.info("migration plan: schema is up to date with linked models")
+		return nil
+	}
+	up, down, err := plan.WriteFiles(r.out, r.migrationDir, "link_models")
+	if err != nil {
+		return fmt.Errorf("write migration fail: %w", err)
+	}
+	for _, c := range plan.Destructive() {
+		r.report(Event{Kind: EventWarning, Table: c.Table, Message: fmt.Sprintf("migration plan: destructive %s %s.%s: %s", c.Kind, c.Table, c.Name, c.Detail)})
+	}
+	r.info(fmt.Sprintf("migration plan: write %d changes into %s and %s, database is not changed", len(plan.Changes), up, down))
+	return nil
+}
@@ -0,0 +1,158 @@
+package gen
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type planAccount struct {
+	ID    uint `gorm:"primaryKey"`
+	Name  string
+	Email string `gorm:"index"`
+}
+
+func (planAccount) TableName() string { return "accounts" }
+
+type planTeam struct {
+	ID        uint   `gorm:"primaryKey"`
+	Name      string `gorm:"uniqueIndex"`
+	AccountID uint
+	Account   planAccount
+}
+
+func (planTeam) TableName() string { return "teams" }
+
+func openPlanDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "plan.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite fail: %s", err)
+	}
+	if err = db.Exec("CREATE TABLE accounts (id integer primary key, name text, legacy text)").Error; err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestPlanMigration(t *testing.T) {
+	plan, err := PlanMigration(openPlanDB(t), &planAccount{}, &planTeam{})
+	if err != nil {
+		t.Fatalf("plan migration fail: %s", err)
+	}
+
+	var changes []string
+	for _, c := range plan.Changes {
+		changes = append(changes, string(c.Kind)+" "+c.Table+"."+c.Name)
+	}
+	expect := []string{"add_column accounts.email", "drop_column accounts.legacy", "create_table teams."}
+	if strings.Join(changes, "\n") != strings.Join(expect, "\n") {
+		t.Errorf("expect changes %q, got %q", expect, changes)
+	}
+	if destructive := plan.Destructive(); len(destructive) != 1 || destructive[0].Name != "legacy" {
+		t.Errorf("expect drop column legacy destructive, got %+v", destructive)
+	}
+
+	up := plan.UpSQL()
+	for _, stmt := range []string{
+		"ALTER TABLE `accounts` ADD `email` text;",
+		"-- DESTRUCTIVE: may lose data\nALTER TABLE `accounts` DROP COLUMN `legacy`;",
+		"CONSTRAINT `fk_teams_account` FOREIGN KEY (`account_id`) REFERENCES `accounts`(`id`));",
+		"CREATE UNIQUE INDEX `idx_teams_name` ON `teams` (`name`);",
+	} {
+		if !strings.Contains(up, stmt) {
+			t.Errorf("expect up sql contains %q, got:\n%s", stmt, up)
+		}
+	}
+
+	down := plan.DownSQL()
+	if i, j := strings.Index(down, "DROP TABLE `teams`"), strings.Index(down, "ADD `legacy` text;"); i < 0 || j < 0 || i > j {
+		t.Errorf("expect down sql revert changes in reverse order, got:\n%s", down)
+	}
+}
+
+func TestSchema_LinkModel_MigrationPlan(t *testing.T) {
+	db := openPlanDB(t)
+	out := NewMemoryOutput()
+	dir := t.TempDir()
+	g := NewGenerator(Config{OutPath: filepath.Join(dir, "query")})
+	g.WithOutput(out)
+	g.WithMigrationPlan(filepath.Join(dir, "migrations"))
+	g.UseDB(db)
+
+	if err := g.LinkModel(&planAccount{}, &planTeam{}); err != nil {
+		t.Fatalf("link model fail: %s", err)
+	}
+	if db.Migrator().HasTable("teams") || db.Migrator().HasColumn("accounts", "email") {
+		t.Errorf("expect database unchanged when migration plan is enabled")
+	}
+	if g.GetSchema("teams") == nil {
+		t.Errorf("expect model teams linked")
+	}
+
+	var files []string
+	for _, name := range out.Names() {
+		if strings.HasPrefix(name, filepath.Join(dir, "migrations")) {
+			files = append(files, name)
+		}
+	}
+	if len(files) != 2 {
+		t.Fatalf("expect up and down migration files, got %v", files)
+	}
+	for _, name := range files {
+		if !strings.HasSuffix(name, "_link_models.up.sql") && !strings.HasSuffix(name, "_link_models.down.sql") {
+			t.Errorf("unexpected migration file %s", name)
+		}
+	}
+}
+
+type planNote struct {
+	ID    uint   `gorm:"primaryKey"`
+	Title string `gorm:"size:64;index:idx_notes_title,comment:it's a \\ title?"`
+}
+
+func (planNote) TableName() string { return "notes" }
+
+func TestPlanMigration_IndexComment(t *testing.T) {
+	ddl := filepath.Join(t.TempDir(), "schema.sql")
+	if err := os.WriteFile(ddl, []byte("CREATE TABLE `accounts` (`id` bigint NOT NULL, PRIMARY KEY (`id`));\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	db, err := OpenDDL(DbMySQL, ddl)
+	if err != nil {
+		t.Fatalf("open ddl fail: %s", err)
+	}
+	plan, err := PlanMigration(db, &planNote{})
+	if err != nil {
+		t.Fatalf("plan migration fail: %s", err)
+	}
+	if up, expect := plan.UpSQL(), "CREATE INDEX `idx_notes_title` ON `notes` (`title`) COMMENT 'it''s a \\\\ title?';"; !strings.Contains(up, expect) {
+		t.Errorf("expect up sql contains %q, got:\n%s", expect, up)
+	}
+}
+
+// a linked relation whose table can not be generated is returned as error of the model instead of panic
+func TestSchema_LinkModel_RelationError(t *testing.T) {
+	db := openPlanDB(t)
+	g := NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query"), ModelPkgPath: "query"})
+	g.WithModelNameStrategy(func(tableName string) string {
+		if tableName == "accounts" {
+			return "bad-name"
+		}
+		return db.NamingStrategy.SchemaName(tableName)
+	})
+	g.UseDB(db)
+	if err := g.LinkModel(&planAccount{}, &planTeam{}); err != nil {
+		t.Fatalf("link model fail: %s", err)
+	}
+
+	_, err := g.GenerateModelE("teams")
+	var genErr *GenerateError
+	if !errors.As(err, &genErr) || genErr.Table != "teams" || !strings.Contains(err.Error(), "relation Account") {
+		t.Errorf("expect error of relation Account of teams, got %v", err)
+	}
+}
@@ -0,0 +1,131 @@
+package gen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gorm.io/gen/field"
+)
+
+// newModuleDir create a temp dir holding go.mod of module example.com/app
+func newModuleDir(t *testing.T) string {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/app\n\ngo 1.18\n"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func TestGenerator_ModelPkgPath(t *testing.T) {
+	root := newModuleDir(t)
+	outPath := filepath.Join(root, "dal", "query")
+	g := NewGenerator(Config{OutPath: outPath, ModelPkgPath: "model", Mode: WithDefaultQuery | WithoutContext | WithQueryInterface})
+	g.ApplyBasic(g.GenerateModelFrom(testUserObject))
+	if err := g.ExecuteE(); err != nil {
+		t.Fatalf("execute fail: %s", err)
+	}
+
+	for file, expects := range map[string][]string{
+		"model/users.model.gen.go": {"package model\n", "type User struct"},
+		"query/users.gen.go":       {`"example.com/app/dal/model"`, "UseModel(&model.User{})", "Create(values ...*model.User) error"},
+		"query/users/users.gen.go": {`query "example.com/app/dal/query"`, "= query.QueryUser.ALL"},
+	} {
+		content, err := os.ReadFile(filepath.Join(root, "dal", file))
+		if err != nil {
+			t.Errorf("read %s fail: %s", file, err)
+			continue
+		}
+		for _, expect := range expects {
+			if !strings.Contains(string(content), expect) {
+				t.Errorf("expect %s contains %q, got:\n%s", file, expect, content)
+			}
+		}
+	}
+	if _, err := os.Stat(filepath.Join(outPath, "users.model.gen.go")); !os.IsNotExist(err) {
+		t.Errorf("expect no model file in query package, got %v", err)
+	}
+}
+
+// models stay in OutPath when ModelPkgPath is not set
+func TestGenerator_ModelPkgPath_Unset(t *testing.T) {
+	outPath := filepath.Join(newModuleDir(t), "query")
+	g := NewGenerator(Config{OutPath: outPath, Mode: WithDefaultQuery})
+	g.ApplyBasic(g.GenerateModelFrom(testUserObject))
+	if err := g.ExecuteE(); err != nil {
+		t.Fatalf("execute fail: %s", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(outPath, "users.model.gen.go")); err != nil {
+		t.Errorf("expect model file in OutPath: %s", err)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(outPath), "model")); !os.IsNotExist(err) {
+		t.Errorf("expect no model dir beside OutPath, got %v", err)
+	}
+}
+
+func TestGenerator_QueryPkgPath_Unresolved(t *testing.T) {
+	g := NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query"), Mode: WithDefaultQuery})
+	g.WithOutput(NewMemoryOutput())
+	g.ApplyBasic(g.GenerateModelFrom(testUserObject))
+	if err := g.ExecuteE(); err == nil || !strings.Contains(err.Error(), "resolve query pkg path") {
+		t.Errorf("expect resolve query pkg path error, got %v", err)
+	}
+}
+
+// model package named like query package is still imported and referenced by package name
+func TestGenerator_ModelPkgPath_SameName(t *testing.T) {
+	root := newModuleDir(t)
+	ddl := "CREATE TABLE users (id bigint NOT NULL PRIMARY KEY);\n" +
+		"CREATE TABLE orders (id bigint NOT NULL PRIMARY KEY, user_id bigint NOT NULL);\n"
+	if err := os.WriteFile(filepath.Join(root, "schema.sql"), []byte(ddl), 0600); err != nil {
+		t.Fatal(err)
+	}
+	db, err := OpenDDL(DbMySQL, filepath.Join(root, "schema.sql"))
+	if err != nil {
+		t.Fatalf("open ddl fail: %s", err)
+	}
+
+	out := NewMemoryOutput()
+	g := NewGenerator(Config{OutPath: filepath.Join(root, "dal", "query"), ModelPkgPath: filepath.Join(root, "other", "query"), Mode: WithDefaultQuery})
+	g.WithOutput(out)
+	var warnings []string
+	g.WithReporter(ReporterFunc(func(e Event) {
+		if e.Kind == EventWarning {
+			warnings = append(warnings, e.Message)
+		}
+	}))
+	g.UseDB(db)
+	order := g.GenerateModel("orders")
+	g.ApplyBasic(order, g.GenerateModel("users", FieldRelate(field.HasMany, "Orders", order, &field.RelateConfig{RelateSlicePointer: true, Key: "ID"})))
+	if err = g.ExecuteE(); err != nil {
+		t.Fatalf("execute fail: %s", err)
+	}
+
+	content, err := out.ReadFile(filepath.Join(root, "dal", "query", "users.gen.go"))
+	if err != nil {
+		t.Fatalf("read query file fail: %s", err)
+	}
+	for _, expect := range []string{`"example.com/app/other/query"`, "UseModel(&query.User{})", "Create(values ...*query.User) error"} {
+		if !strings.Contains(string(content), expect) {
+			t.Errorf("expect query file contains %q, got:\n%s", expect, content)
+		}
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "relation helpers of User are not generated") {
+		t.Errorf("expect warning of skipped relation helpers, got %v", warnings)
+	}
+}
+
+func TestResolvePkgPath(t *testing.T) {
+	root := t.TempDir()
+	_ = os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/app\n"), 0640)
+	for dir, expect := range map[string]string{
+		root:                             "example.com/app",
+		filepath.Join(root, "dal/model"): "example.com/app/dal/model",
+	} {
+		if pkgPath, err := resolvePkgPath(dir); err != nil || pkgPath != expect {
+			t.Errorf("expect %s, got %s, %v", expect, pkgPath, err)
+		}
+	}
+}
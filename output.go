@@ -0,0 +1,179 @@
+package gen
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Output destination of generated files, names are file paths built from OutPath and ModelPkgPath.
+// Implementations must be safe for concurrent use, ReadFile must return an error matching
+// fs.ErrNotExist for files that do not exist.
+type Output interface {
+	MkdirAll(dir string) error
+	ReadFile(name string) ([]byte, error)
+	WriteFile(name string, content []byte) error
+	Remove(name string) error
+}
+
+// WithOutput specify where generated files are written, default: file system
+func (cfg *Config) WithOutput(out Output) {
+	cfg.out = out
+}
+
+// NewFileOutput output writing files to file system
+func NewFileOutput() Output { return fileOutput{} }
+
+type fileOutput struct{}
+
+func (fileOutput) MkdirAll(dir string) error            { return os.MkdirAll(dir, os.ModePerm) }
+func (fileOutput) ReadFile(name string) ([]byte, error) { return os.ReadFile(name) }
+func (fileOutput) Remove(name string) error             { return os.Remove(name) }
+func (fileOutput) ReadDir(dir string) ([]os.DirEntry, error) {
+	return os.ReadDir(dir)
+}
+func (fileOutput) WriteFile(name string, content []byte) error {
+	return os.WriteFile(name, content, 0640)
+}
+
+// MemoryOutput output keeping generated files in memory
+type MemoryOutput struct {
+	mu    sync.RWMutex
+	files map[string][]byte
+}
+
+// NewMemoryOutput create empty MemoryOutput
+func NewMemoryOutput() *MemoryOutput {
+	return &MemoryOutput{files: make(map[string][]byte)}
+}
+
+// MkdirAll do nothing, directories are implied by file names
+func (m *MemoryOutput) MkdirAll(string) error { return nil }
+
+// ReadFile return content of file
+func (m *MemoryOutput) ReadFile(name string) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	content, ok := m.files[filepath.Clean(name)]
+	if !ok {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	}
+	return append([]byte(nil), content...), nil
+}
+
+// WriteFile save content of file
+func (m *MemoryOutput) WriteFile(name string, content []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files[filepath.Clean(name)] = append([]byte(nil), content...)
+	return nil
+}
+
+// Remove delete file
+func (m *MemoryOutput) Remove(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.files[filepath.Clean(name)]; !ok {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+	delete(m.files, filepath.Clean(name))
+	return nil
+}
+
+// Names return names of all files in sorted order
+func (m *MemoryOutput) Names() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	names := make([]string, 0, len(m.files))
+	for name := range m.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ArchiveOutput output streaming generated files into a tar or zip archive.
+// Entry names are file names relative to root, Close must be called to finish the archive.
+// Nothing can be read back, so every run generates all files.
+type ArchiveOutput struct {
+	mu   sync.Mutex
+	root string
+
+	add   func(name string, content []byte) error
+	close func() error
+}
+
+// NewTarOutput create ArchiveOutput writing tar archive to w
+func NewTarOutput(w io.Writer, root string) *ArchiveOutput {
+	tw := tar.NewWriter(w)
+	return &ArchiveOutput{
+		root: root,
+		add: func(name string, content []byte) error {
+			err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0640, Size: int64(len(content)), ModTime: time.Now()})
+			if err != nil {
+				return err
+			}
+			_, err = tw.Write(content)
+			return err
+		},
+		close: tw.Close,
+	}
+}
+
+// NewZipOutput create ArchiveOutput writing zip archive to w
+func NewZipOutput(w io.Writer, root string) *ArchiveOutput {
+	zw := zip.NewWriter(w)
+	return &ArchiveOutput{
+		root: root,
+		add: func(name string, content []byte) error {
+			f, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+			if err != nil {
+				return err
+			}
+			_, err = f.Write(content)
+			return err
+		},
+		close: zw.Close,
+	}
+}
+
+// MkdirAll do nothing, directories are implied by entry names
+func (a *ArchiveOutput) MkdirAll(string) error { return nil }
+
+// ReadFile always report not exist
+func (a *ArchiveOutput) ReadFile(name string) ([]byte, error) {
+	return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+}
+
+// Remove do nothing, entries written can not be removed
+func (a *ArchiveOutput) Remove(string) error { return nil }
+
+// WriteFile add entry into archive, name must be under root
+func (a *ArchiveOutput) WriteFile(name string, content []byte) error {
+	rel, err := filepath.Rel(a.root, name)
+	if err != nil {
+		return fmt.Errorf("archive entry %s fail: %w", name, err)
+	}
+	if rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("archive entry %s fail: outside of root %s", name, a.root)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.add(filepath.ToSlash(rel), content)
+}
+
+// Close finish archive, the underlying writer is not closed
+func (a *ArchiveOutput) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.close()
+}
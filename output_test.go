@@ -0,0 +1,87 @@
+package gen
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGenerator_MemoryOutput(t *testing.T) {
+	outPath := filepath.Join(newModuleDir(t), "query")
+	out := NewMemoryOutput()
+	g := newTestGenerator(t, outPath)
+	g.WithOutput(out)
+	if err := g.ExecuteE(); err != nil {
+		t.Fatalf("execute fail: %s", err)
+	}
+
+	if _, err := os.Stat(outPath); !os.IsNotExist(err) {
+		t.Errorf("expect nothing written to disk, got %v", err)
+	}
+	var names []string
+	for _, name := range out.Names() {
+		rel, _ := filepath.Rel(outPath, name)
+		names = append(names, filepath.ToSlash(rel))
+	}
+	expect := []string{ManifestFile, "gen.go", "users.gen.go", "users.model.gen.go", "users/users.gen.go"}
+	if !reflect.DeepEqual(names, expect) {
+		t.Errorf("expect files %v, got %v", expect, names)
+	}
+
+	if diffs, err := g.Check(); err != nil || len(diffs) != 0 {
+		t.Errorf("expect output up to date, got %v %v", diffs, err)
+	}
+}
+
+func TestGenerator_TarOutput(t *testing.T) {
+	outPath := filepath.Join(newModuleDir(t), "query")
+	var buf bytes.Buffer
+	out := NewTarOutput(&buf, filepath.Dir(outPath))
+	g := newTestGenerator(t, outPath)
+	g.WithOutput(out)
+	if err := g.ExecuteE(); err != nil {
+		t.Fatalf("execute fail: %s", err)
+	}
+	if err := out.Close(); err != nil {
+		t.Fatalf("close archive fail: %s", err)
+	}
+
+	entries := make(map[string]string)
+	tr := tar.NewReader(&buf)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("read archive fail: %s", err)
+		}
+		content, _ := io.ReadAll(tr)
+		entries[hdr.Name] = string(content)
+	}
+	if !strings.Contains(entries["query/users.model.gen.go"], "type User struct") {
+		t.Errorf("expect model file in archive, got entries %v", reflect.ValueOf(entries).MapKeys())
+	}
+	if _, ok := entries["query/"+ManifestFile]; !ok {
+		t.Errorf("expect manifest in archive")
+	}
+}
+
+func TestArchiveOutput_OutsideRoot(t *testing.T) {
+	root := t.TempDir()
+	var buf bytes.Buffer
+	out := NewZipOutput(&buf, filepath.Join(root, "dal"))
+	for _, name := range []string{filepath.Join(root, "other", "a.go"), filepath.Join(root, "dal"), root} {
+		if err := out.WriteFile(name, []byte("package a\n")); err == nil {
+			t.Errorf("expect %s outside of root rejected", name)
+		}
+	}
+	if err := out.WriteFile(filepath.Join(root, "dal", "..a.go"), []byte("package a\n")); err != nil {
+		t.Errorf("expect file under root written: %s", err)
+	}
+}
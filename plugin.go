@@ -0,0 +1,171 @@
+package gen
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gorm.io/gen/internal/generate"
+	"gorm.io/gen/internal/model"
+	"gorm.io/gen/internal/parser"
+)
+
+type (
+	// QueryStructMeta struct info in generated code, exposed for plugins
+	QueryStructMeta = generate.QueryStructMeta
+	// ModelField field of generated model struct
+	ModelField = model.Field
+	// ModelMethod method bind to generated model struct
+	ModelMethod = parser.Method
+	// MethodParam param or result of ModelMethod
+	MethodParam = parser.Param
+)
+
+// FileKind kind of generated file
+type FileKind string
+
+const (
+	// ModelFileKind model struct file: <file>.model.gen.go
+	ModelFileKind FileKind = "model"
+	// QueryFileKind query struct file: <file>.gen.go
+	QueryFileKind FileKind = "query"
+	// QueryTestFileKind query struct unit test file: <file>.gen_test.go
+	QueryTestFileKind FileKind = "query_test"
+	// FieldFileKind field package file: <file>/<file>.gen.go
+	FieldFileKind FileKind = "field"
+	// EntryFileKind query entry file, default: gen.go
+	EntryFileKind FileKind = "entry"
+	// EntryTestFileKind query entry unit test file, default: gen_test.go
+	EntryTestFileKind FileKind = "entry_test"
+	// ExtraFileKind file rendered from user extra template
+	ExtraFileKind FileKind = "extra"
+)
+
+// RenderFile file being rendered
+type RenderFile struct {
+	Name    string           // file path
+	Kind    FileKind         // file kind
+	Meta    *QueryStructMeta // model the file is rendered for, nil for package level files
+	Content []byte           // rendered content before formatting, available in AfterRender
+}
+
+// Plugin generator plugin, implement any of the hook interfaces below to be called by Generator:
+// MetaHook, RenderHook and ExecuteHook
+type Plugin interface {
+	Name() string
+}
+
+// MetaHook called after a QueryStructMeta is built from table, object or struct,
+// plugin can mutate fields or add model methods here
+type MetaHook interface {
+	AfterMeta(meta *QueryStructMeta) error
+}
+
+// RenderHook called before and after every file is rendered, may be called concurrently.
+// BeforeRender can mutate file.Meta, AfterRender can modify file.Content before formatting.
+type RenderHook interface {
+	BeforeRender(file *RenderFile) error
+	AfterRender(file *RenderFile) error
+}
+
+// ExecuteHook called at the end of Execute with all metas ordered by model struct name,
+// use Generator.Emit to write extra files
+type ExecuteHook interface {
+	AfterExecute(g *Generator, metas []*QueryStructMeta) error
+}
+
+// Use register plugins
+func (g *Generator) Use(plugins ...Plugin) {
+	g.plugins = append(g.plugins, plugins...)
+}
+
+// afterMeta call MetaHook of plugins
+func (g *Generator) afterMeta(meta *QueryStructMeta) error {
+	for _, p := range g.plugins {
+		if h, ok := p.(MetaHook); ok {
+			if err := h.AfterMeta(meta); err != nil {
+				return fmt.Errorf("plugin %s: %w", p.Name(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// beforeRender call BeforeRender of plugins
+func (g *Generator) beforeRender(file *RenderFile) error {
+	for _, p := range g.plugins {
+		if h, ok := p.(RenderHook); ok {
+			if err := h.BeforeRender(file); err != nil {
+				return fmt.Errorf("plugin %s: %w", p.Name(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// outputRendered call AfterRender of plugins then format and output file
+func (g *Generator) outputRendered(file *RenderFile, content []byte) error {
+	file.Content = content
+	for _, p := range g.plugins {
+		if h, ok := p.(RenderHook); ok {
+			if err := h.AfterRender(file); err != nil {
+				return fmt.Errorf("plugin %s: %w", p.Name(), err)
+			}
+		}
+	}
+	if err := g.output(file.Name, file.Content); err != nil {
+		return err
+	}
+	event := Event{Kind: EventFileGenerated, Message: fmt.Sprintf("generate %s file: %s", file.Kind, file.Name), File: file.Name, FileKind: file.Kind}
+	if file.Meta != nil {
+		event.Table, event.Model = file.Meta.TableName, file.Meta.ModelStructName
+	}
+	g.report(event)
+	return nil
+}
+
+// afterExecute call ExecuteHook of plugins
+func (g *Generator) afterExecute() error {
+	var errs errCollector
+	metas := g.allMetas()
+	for _, p := range g.plugins {
+		if h, ok := p.(ExecuteHook); ok {
+			if err := h.AfterExecute(g, metas); err != nil {
+				errs.Add("", "", fmt.Errorf("plugin %s: %w", p.Name(), err))
+			}
+		}
+	}
+	return errs.Err()
+}
+
+// Emit write an extra file into OutPath, name is relative to OutPath.
+// Go source is formatted like generated code, emitted files inside OutPath are tracked in the manifest.
+func (g *Generator) Emit(name string, content []byte) error {
+	fileName := name
+	if !filepath.IsAbs(fileName) {
+		fileName = filepath.Join(g.OutPath, name)
+	}
+	if err := g.mkdir(filepath.Dir(fileName)); err != nil {
+		return err
+	}
+	var err error
+	if strings.HasSuffix(fileName, ".go") {
+		err = g.output(fileName, content)
+	} else {
+		err = g.outputRaw(fileName, content)
+	}
+	if err != nil {
+		return err
+	}
+	g.report(Event{Kind: EventFileGenerated, Message: fmt.Sprintf("emit file: %s", fileName), File: fileName})
+	return nil
+}
+
+// outputRaw output content as it is
+func (g *Generator) outputRaw(fileName string, content []byte) error {
+	g.recordFile(fileName, content)
+	if g.checker != nil {
+		return g.checker.compare(fileName, content)
+	}
+	return g.out.WriteFile(fileName, content)
+}
@@ -0,0 +1,103 @@
+package gen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type testPlugin struct {
+	mu    sync.Mutex
+	kinds map[FileKind]int
+}
+
+func (p *testPlugin) Name() string { return "test" }
+
+func (p *testPlugin) AfterMeta(meta *QueryStructMeta) error {
+	for _, f := range meta.Fields {
+		if f.Name == "Name" {
+			f.ColumnComment = "set by plugin"
+		}
+	}
+	return nil
+}
+
+func (p *testPlugin) BeforeRender(file *RenderFile) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.kinds[file.Kind]++
+	return nil
+}
+
+func (p *testPlugin) AfterRender(file *RenderFile) error {
+	if file.Kind == ModelFileKind {
+		file.Content = append(file.Content, fmt.Sprintf("\nconst %sTable = %q\n", file.Meta.ModelStructName, file.Meta.TableName)...)
+	}
+	return nil
+}
+
+func (p *testPlugin) AfterExecute(g *Generator, metas []*QueryStructMeta) error {
+	var doc, code bytes.Buffer
+	code.WriteString("package query\n\nvar Models = []string{")
+	for _, meta := range metas {
+		fmt.Fprintf(&doc, "- %s\n", meta.TableName)
+		fmt.Fprintf(&code, "%q,", meta.ModelStructName)
+	}
+	code.WriteString("}\n")
+	if err := g.Emit("docs/tables.md", doc.Bytes()); err != nil {
+		return err
+	}
+	return g.Emit("models.gen.go", code.Bytes())
+}
+
+func TestGenerator_Plugin(t *testing.T) {
+	outPath := filepath.Join(newModuleDir(t), "query")
+	p := &testPlugin{kinds: make(map[FileKind]int)}
+	g := NewGenerator(Config{OutPath: outPath, ModelPkgPath: "query", Mode: WithDefaultQuery | WithoutContext | WithQueryInterface})
+	g.Use(p)
+	g.ApplyBasic(g.GenerateModelFrom(testUserObject), g.GenerateModelFrom(testCompanyObject))
+	if err := g.ExecuteE(); err != nil {
+		t.Fatalf("execute fail: %s", err)
+	}
+
+	for file, expect := range map[string]string{
+		"users.model.gen.go": "// set by plugin",
+		"users.gen.go":       "func newUser(",
+		"docs/tables.md":     "- companies\n- users\n",
+		"models.gen.go":      `var Models = []string{"Company", "User"}`,
+	} {
+		content, err := os.ReadFile(filepath.Join(outPath, file))
+		if err != nil {
+			t.Errorf("read %s fail: %s", file, err)
+			continue
+		}
+		if !strings.Contains(string(content), expect) {
+			t.Errorf("expect %s contains %q, got:\n%s", file, expect, content)
+		}
+	}
+	if content, _ := os.ReadFile(filepath.Join(outPath, "users.model.gen.go")); !strings.Contains(string(content), `const UserTable = "users"`) {
+		t.Errorf("expect AfterRender content in model file, got:\n%s", content)
+	}
+	if p.kinds[ModelFileKind] != 2 || p.kinds[QueryFileKind] != 2 || p.kinds[EntryFileKind] != 1 {
+		t.Errorf("unexpected rendered file kinds: %v", p.kinds)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outPath, ManifestFile))
+	if err != nil {
+		t.Fatalf("read manifest fail: %s", err)
+	}
+	var m struct{ Files map[string]string }
+	if err = json.Unmarshal(content, &m); err != nil {
+		t.Fatalf("parse manifest fail: %s", err)
+	}
+	for _, file := range []string{"docs/tables.md", "models.gen.go"} {
+		if m.Files[file] == "" {
+			t.Errorf("expect emitted file %s in manifest", file)
+		}
+	}
+}
@@ -0,0 +1,482 @@
+package gen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jinzhu/inflection"
+	"gorm.io/gorm/schema"
+
+	"gorm.io/gen/field"
+	"gorm.io/gen/internal/generate"
+	"gorm.io/gen/internal/model"
+)
+
+// RelationInference sources relations between models generated from tables are inferred from
+type RelationInference struct {
+	ForeignKeys      bool // foreign key constraints of database
+	NamingConvention bool // columns named <table>_id referencing single column primary key of table, e.g. orders.user_id -> users.id
+	ManyToMany       bool // tables of exactly two foreign keys and no other columns than timestamps, or id if foreign keys are unique together, are join tables
+}
+
+// WithRelationInference infer BelongsTo, HasOne, HasMany and ManyToMany relations between models generated from tables,
+// relation fields are added to both models once both of them are generated
+func (cfg *Config) WithRelationInference(conf RelationInference) {
+	cfg.relationInference = &conf
+}
+
+// relationTable table info read for relation inference
+type relationTable struct {
+	name        string
+	columns     []string
+	primaryKey  []string
+	uniques     [][]string // columns of unique indexes
+	foreignKeys []ForeignKey
+}
+
+// isJoinTable report whether table only joins two tables
+func (t *relationTable) isJoinTable() bool {
+	if len(t.foreignKeys) != 2 {
+		return false
+	}
+	unique := t.isUnique(append(append([]string(nil), t.foreignKeys[0].Columns...), t.foreignKeys[1].Columns...))
+	for _, col := range t.columns {
+		switch {
+		case col == "created_at", col == "updated_at", col == "deleted_at", col == "id" && unique:
+			continue
+		}
+		if !containsString(t.foreignKeys[0].Columns, col) && !containsString(t.foreignKeys[1].Columns, col) {
+			return false
+		}
+	}
+	return true
+}
+
+// isUnique report whether values of columns are unique in table
+func (t *relationTable) isUnique(columns []string) bool {
+	for _, unique := range append([][]string{t.primaryKey}, t.uniques...) {
+		if len(unique) == len(columns) && sameStrings(unique, columns) {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldNS name strategy of generated field names
+var fieldNS = schema.NamingStrategy{SingularTable: true}
+
+// inferRelations add relations between meta and generated models
+func (g *Generator) inferRelations(meta *generate.QueryStructMeta) error {
+	if g.relationInference == nil || meta.Source != model.Table {
+		return nil
+	}
+	if g.relationTables == nil {
+		if err := g.loadRelationTables(); err != nil {
+			return fmt.Errorf("read tables for relation inference fail: %w", err)
+		}
+	}
+	t := g.relationTables[meta.TableName]
+	if t == nil {
+		return nil
+	}
+
+	// meta as child
+	for _, fk := range t.foreignKeys {
+		if parent := g.tableModel(fk.RefTable); parent != nil {
+			g.relateForeignKey(meta, parent, fk)
+		}
+	}
+	// meta as parent
+	for _, name := range g.relationTableNames() {
+		if name == meta.TableName {
+			continue
+		}
+		for _, fk := range g.relationTables[name].foreignKeys {
+			if fk.RefTable != meta.TableName {
+				continue
+			}
+			if child := g.tableModel(name); child != nil {
+				g.relateForeignKey(child, meta, fk)
+			}
+		}
+	}
+	if !g.relationInference.ManyToMany {
+		return nil
+	}
+	for _, name := range g.relationTableNames() {
+		join := g.relationTables[name]
+		if !join.isJoinTable() {
+			continue
+		}
+		a, b := join.foreignKeys[0], join.foreignKeys[1]
+		if a.RefTable != meta.TableName && b.RefTable != meta.TableName {
+			continue
+		}
+		left, right := g.tableModel(a.RefTable), g.tableModel(b.RefTable)
+		if left == nil || right == nil {
+			continue
+		}
+		g.relateJoinTable(left, right, join.name, a, b)
+		if left != right {
+			g.relateJoinTable(right, left, join.name, b, a)
+		}
+	}
+	return nil
+}
+
+// loadRelationTables read columns, unique indexes and foreign keys of tables selected by table filter or
+// generated as models, and of tables they reference. With ManyToMany, other tables are read to find join tables
+// between them, only join tables are kept
+func (g *Generator) loadRelationTables() error {
+	tables, err := g.db.Migrator().GetTables()
+	if err != nil {
+		return err
+	}
+	selected, err := g.tableFilter.Filter(tables, nil)
+	if err != nil {
+		return err
+	}
+	for _, meta := range g.models {
+		if meta.Source == model.Table {
+			selected = append(selected, meta.TableName)
+		}
+	}
+	exists := make(map[string]bool, len(tables))
+	for _, name := range tables {
+		exists[name] = true
+	}
+
+	g.relationSchema = (&model.Config{NameStrategy: model.NameStrategy{SchemaNameOpts: g.schemaNameOpts()}}).GetSchemaName(g.db)
+	g.relationTables = make(map[string]*relationTable, len(selected))
+	for _, name := range selected {
+		if err = g.loadRelationTable(name); err != nil {
+			return err
+		}
+	}
+	for _, name := range g.relationTableNames() {
+		for _, ref := range g.referencedTables(g.relationTables[name]) {
+			if !exists[ref] {
+				continue
+			}
+			if err = g.loadRelationTable(ref); err != nil {
+				return err
+			}
+		}
+	}
+	if g.relationInference.ManyToMany {
+		for _, name := range tables {
+			if g.relationTables[name] != nil {
+				continue
+			}
+			t, err := g.readRelationTable(name)
+			if err != nil {
+				return err
+			}
+			if g.joinsLoadedTables(t) {
+				g.relationTables[name] = t
+			}
+		}
+	}
+
+	for _, name := range g.relationTableNames() {
+		t := g.relationTables[name]
+		if g.relationInference.NamingConvention {
+			t.foreignKeys = append(t.foreignKeys, g.conventionForeignKeys(t)...)
+		}
+		for i, fk := range t.foreignKeys {
+			if ref := g.relationTables[fk.RefTable]; ref != nil && len(fk.RefColumns) == 0 {
+				t.foreignKeys[i].RefColumns = ref.primaryKey
+			}
+		}
+	}
+	return nil
+}
+
+// loadRelationTable read table into relationTables unless read already
+func (g *Generator) loadRelationTable(name string) error {
+	if g.relationTables[name] != nil {
+		return nil
+	}
+	t, err := g.readRelationTable(name)
+	if err != nil {
+		return err
+	}
+	g.relationTables[name] = t
+	return nil
+}
+
+// readRelationTable read columns, unique indexes and foreign keys of table
+func (g *Generator) readRelationTable(name string) (*relationTable, error) {
+	t := &relationTable{name: name}
+	columnTypes, err := g.db.Migrator().ColumnTypes(name)
+	if err != nil {
+		return nil, fmt.Errorf("get columns of %s fail: %w", name, err)
+	}
+	for _, col := range columnTypes {
+		t.columns = append(t.columns, col.Name())
+		if pk, ok := col.PrimaryKey(); ok && pk {
+			t.primaryKey = append(t.primaryKey, col.Name())
+		}
+		if unique, ok := col.Unique(); ok && unique {
+			t.uniques = append(t.uniques, []string{col.Name()})
+		}
+	}
+	if indexes, err := g.db.Migrator().GetIndexes(name); err == nil {
+		for _, idx := range indexes {
+			if unique, _ := idx.Unique(); unique {
+				t.uniques = append(t.uniques, idx.Columns())
+			}
+		}
+	}
+	if g.relationInference.ForeignKeys {
+		if t.foreignKeys, err = g.foreignKeys(name); err != nil {
+			return nil, fmt.Errorf("get foreign keys of %s fail: %w", name, err)
+		}
+	}
+	return t, nil
+}
+
+// referencedTables tables referenced by foreign keys of t, and by columns named <table>_id with NamingConvention
+func (g *Generator) referencedTables(t *relationTable) (refs []string) {
+	for _, fk := range t.foreignKeys {
+		refs = append(refs, fk.RefTable)
+	}
+	if !g.relationInference.NamingConvention {
+		return refs
+	}
+	for _, col := range t.columns {
+		if base := strings.TrimSuffix(col, "_id"); base != col && base != "" {
+			refs = append(refs, g.db.NamingStrategy.TableName(base), base)
+		}
+	}
+	return refs
+}
+
+// joinsLoadedTables report whether t is a join table between tables in relationTables
+func (g *Generator) joinsLoadedTables(t *relationTable) bool {
+	join := *t
+	if g.relationInference.NamingConvention {
+		join.foreignKeys = append(join.foreignKeys[:len(join.foreignKeys):len(join.foreignKeys)], g.conventionForeignKeys(&join)...)
+	}
+	return join.isJoinTable() && g.relationTables[join.foreignKeys[0].RefTable] != nil && g.relationTables[join.foreignKeys[1].RefTable] != nil
+}
+
+// foreignKeys foreign key constraints of table, empty if dialect can not read them
+func (g *Generator) foreignKeys(table string) ([]ForeignKey, error) {
+	if d, ok := g.db.Dialector.(*DDLDialector); ok {
+		return d.ForeignKeys(table), nil
+	}
+	if d := g.getDialect(); d != nil && d.ForeignKeys != nil {
+		return d.ForeignKeys(g.db, g.relationSchema, table)
+	}
+	return nil, nil
+}
+
+// conventionForeignKeys foreign keys of columns named <table>_id which are not constrained
+func (g *Generator) conventionForeignKeys(t *relationTable) (fks []ForeignKey) {
+	for _, col := range t.columns {
+		base := strings.TrimSuffix(col, "_id")
+		if base == col || base == "" || g.constrained(t, col) {
+			continue
+		}
+		for _, refTable := range []string{g.db.NamingStrategy.TableName(base), base} {
+			if ref := g.relationTables[refTable]; ref != nil && len(ref.primaryKey) == 1 {
+				fks = append(fks, ForeignKey{
+					Name:       fmt.Sprintf("convention_%s_%s", t.name, col),
+					Table:      t.name,
+					Columns:    []string{col},
+					RefTable:   refTable,
+					RefColumns: ref.primaryKey,
+				})
+				break
+			}
+		}
+	}
+	return fks
+}
+
+// constrained report whether column is referencing column of foreign key constraint
+func (g *Generator) constrained(t *relationTable, column string) bool {
+	for _, fk := range t.foreignKeys {
+		if containsString(fk.Columns, column) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Generator) relationTableNames() []string {
+	names := make([]string, 0, len(g.relationTables))
+	for name := range g.relationTables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// tableModel return model generated from table, nil if not generated
+func (g *Generator) tableModel(table string) *generate.QueryStructMeta {
+	var found *generate.QueryStructMeta
+	for _, meta := range g.models {
+		if meta.Source == model.Table && meta.TableName == table && (found == nil || meta.ModelStructName < found.ModelStructName) {
+			found = meta
+		}
+	}
+	return found
+}
+
+// relateForeignKey add BelongsTo relation to child, and HasOne or HasMany relation to parent
+func (g *Generator) relateForeignKey(child, parent *generate.QueryStructMeta, fk ForeignKey) {
+	childFields, parentFields := columnFields(child, fk.Columns), columnFields(parent, fk.RefColumns)
+	if childFields == nil || parentFields == nil || len(childFields) != len(parentFields) {
+		g.report(Event{Kind: EventWarning, Message: fmt.Sprintf("skip relation of foreign key %s: columns are not generated as fields", fk.Name), Table: child.TableName})
+		return
+	}
+	keys := fmt.Sprintf("foreignKey:%s;references:%s", fieldNames(childFields), fieldNames(parentFields))
+
+	// name of relation field from column, e.g. author_id -> Author
+	prefix := ""
+	if len(fk.Columns) == 1 && strings.HasSuffix(fk.Columns[0], "_id") {
+		prefix = fieldNS.SchemaName(strings.TrimSuffix(fk.Columns[0], "_id"))
+	}
+	belongsTo := prefix
+	if belongsTo == "" {
+		belongsTo = parent.ModelStructName
+	}
+	g.addRelation(child, newRelationField(field.BelongsTo, belongsTo, parent, keys))
+
+	if g.relationInference.ManyToMany && g.relationTables[child.TableName].isJoinTable() {
+		return // join tables are related as ManyToMany
+	}
+	rel, name := field.HasMany, inflection.Plural(child.ModelStructName)
+	if t := g.relationTables[child.TableName]; t != nil && t.isUnique(fk.Columns) {
+		rel, name = field.HasOne, child.ModelStructName
+	}
+	if child == parent || g.countForeignKeys(child.TableName, parent.TableName) > 1 {
+		if prefix == "" {
+			prefix = fieldNS.SchemaName(fk.Name)
+		}
+		name = prefix + name
+	}
+	g.addRelation(parent, newRelationField(rel, name, child, keys))
+}
+
+// relateJoinTable add ManyToMany relation to left model through join table
+func (g *Generator) relateJoinTable(left, right *generate.QueryStructMeta, join string, leftFK, rightFK ForeignKey) {
+	leftFields, rightFields := columnFields(left, leftFK.RefColumns), columnFields(right, rightFK.RefColumns)
+	if leftFields == nil || rightFields == nil {
+		g.report(Event{Kind: EventWarning, Message: fmt.Sprintf("skip relation of join table %s: columns are not generated as fields", join), Table: left.TableName})
+		return
+	}
+	name := inflection.Plural(right.ModelStructName)
+	if left == right && len(rightFK.Columns) == 1 {
+		name = inflection.Plural(fieldNS.SchemaName(strings.TrimSuffix(rightFK.Columns[0], "_id")))
+	}
+	tag := fmt.Sprintf("many2many:%s;foreignKey:%s;joinForeignKey:%s;references:%s;joinReferences:%s",
+		join, fieldNames(leftFields), columnNames(leftFK.Columns), fieldNames(rightFields), columnNames(rightFK.Columns))
+	g.addRelation(left, newRelationField(field.Many2Many, name, right, tag))
+}
+
+// countForeignKeys count foreign keys from child table to parent table
+func (g *Generator) countForeignKeys(child, parent string) (count int) {
+	for _, fk := range g.relationTables[child].foreignKeys {
+		if fk.RefTable == parent {
+			count++
+		}
+	}
+	return count
+}
+
+// addRelation add relation field to meta unless field name is taken
+func (g *Generator) addRelation(meta *generate.QueryStructMeta, f *model.Field) {
+	for _, exist := range meta.Fields {
+		if exist.Name != f.Name {
+			continue
+		}
+		if !exist.IsRelation() || exist.Type != f.Type {
+			g.report(Event{
+				Kind:    EventWarning,
+				Message: fmt.Sprintf("skip inferred relation %s.%s: field exists", meta.ModelStructName, f.Name),
+				Table:   meta.TableName,
+				Model:   meta.ModelStructName,
+			})
+		}
+		return
+	}
+	meta.Fields = append(meta.Fields, f)
+	g.info(fmt.Sprintf("infer relation %s.%s %s %s", meta.ModelStructName, f.Name, f.Relation.Relationship(), f.Relation.Type()))
+}
+
+// newRelationField relation field as FieldRelate creates, without relations of target
+func newRelationField(rel field.RelationshipType, name string, target *generate.QueryStructMeta, gormTag string) *model.Field {
+	config := &field.RelateConfig{RelatePointer: rel == field.HasOne || rel == field.BelongsTo}
+	return &model.Field{
+		Name:     name,
+		Type:     config.RelateFieldPrefix(rel) + target.StructInfo.Type,
+		JSONTag:  ns.ColumnName("", name),
+		GORMTag:  gormTag,
+		Relation: field.NewRelationWithType(rel, name, target.StructInfo.Type),
+	}
+}
+
+// columnFields fields of columns in order, nil if any column is not generated
+func columnFields(meta *generate.QueryStructMeta, columns []string) []*model.Field {
+	if len(columns) == 0 {
+		return nil
+	}
+	fields := make([]*model.Field, 0, len(columns))
+	for _, col := range columns {
+		var found *model.Field
+		for _, f := range meta.Fields {
+			if !f.IsRelation() && f.ColumnName == col {
+				found = f
+				break
+			}
+		}
+		if found == nil {
+			return nil
+		}
+		fields = append(fields, found)
+	}
+	return fields
+}
+
+func fieldNames(fields []*model.Field) string {
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.Name
+	}
+	return strings.Join(names, ",")
+}
+
+// columnNames field names of join table columns, gorm maps them back to columns by name strategy
+func columnNames(columns []string) string {
+	names := make([]string, len(columns))
+	for i, col := range columns {
+		names[i] = fieldNS.SchemaName(col)
+	}
+	return strings.Join(names, ",")
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// sameStrings report whether a and b contain same strings regardless of order
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, s := range a {
+		if !containsString(b, s) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,173 @@
+package gen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"gorm.io/gen/internal/generate"
+)
+
+const testRelationSchema = `
+CREATE TABLE users (id integer primary key, name text);
+CREATE TABLE profiles (id integer primary key, user_id integer unique references users(id), bio text);
+CREATE TABLE orders (id integer primary key, user_id integer references users(id), reviewer_id integer references users(id));
+CREATE TABLE roles (id integer primary key, name text);
+CREATE TABLE user_roles (user_id integer references users(id), role_id integer references roles(id), primary key (user_id, role_id));
+CREATE TABLE comments (id integer primary key, order_id integer, body text);
+`
+
+func TestGenerator_WithRelationInference(t *testing.T) {
+	dir := newModuleDir(t)
+	db, err := gorm.Open(sqlite.Open(filepath.Join(dir, "relation.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite fail: %s", err)
+	}
+	for _, stmt := range strings.Split(strings.TrimSpace(testRelationSchema), ";\n") {
+		if err = db.Exec(stmt).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out := NewMemoryOutput()
+	g := NewGenerator(Config{OutPath: filepath.Join(dir, "query"), ModelPkgPath: "query", Mode: WithDefaultQuery})
+	g.WithOutput(out)
+	g.WithRelationInference(RelationInference{ForeignKeys: true, NamingConvention: true, ManyToMany: true})
+	g.UseDB(db)
+
+	// models are related whatever order they are generated in
+	models := make(map[string]*generate.QueryStructMeta)
+	for _, table := range []string{"orders", "comments", "user_roles", "roles", "profiles", "users"} {
+		meta, err := g.GenerateModelE(table)
+		if err != nil {
+			t.Fatalf("generate model %s fail: %s", table, err)
+		}
+		models[meta.ModelStructName] = meta
+	}
+
+	expects := map[string][]string{
+		"User": {
+			"UserOrders []*Order has_many foreignKey:UserID;references:ID",
+			"ReviewerOrders []*Order has_many foreignKey:ReviewerID;references:ID",
+			"Profile *Profile has_one foreignKey:UserID;references:ID",
+			"Roles []*Role many_to_many many2many:user_roles;foreignKey:ID;joinForeignKey:UserID;references:ID;joinReferences:RoleID",
+		},
+		"Order": {
+			"User *User belongs_to foreignKey:UserID;references:ID",
+			"Reviewer *User belongs_to foreignKey:ReviewerID;references:ID",
+			"Comments []*Comment has_many foreignKey:OrderID;references:ID",
+		},
+		"Comment":  {"Order *Order belongs_to foreignKey:OrderID;references:ID"},
+		"Profile":  {"User *User belongs_to foreignKey:UserID;references:ID"},
+		"Role":     {"Users []*User many_to_many many2many:user_roles;foreignKey:ID;joinForeignKey:RoleID;references:ID;joinReferences:UserID"},
+		"UserRole": {"User *User belongs_to foreignKey:UserID;references:ID", "Role *Role belongs_to foreignKey:RoleID;references:ID"},
+	}
+	for name, relations := range expects {
+		var got []string
+		for _, f := range models[name].Fields {
+			if f.IsRelation() {
+				got = append(got, strings.Join([]string{f.Name, f.Type, string(f.Relation.Relationship()), f.GORMTag}, " "))
+			}
+		}
+		if len(got) != len(relations) {
+			t.Errorf("expect relations of %s: %v, got %v", name, relations, got)
+			continue
+		}
+		for _, r := range relations {
+			if !strings.Contains(strings.Join(got, "\n"), r) {
+				t.Errorf("relation %q of %s not inferred, got %v", r, name, got)
+			}
+		}
+	}
+
+	if err = g.ExecuteE(); err != nil {
+		t.Fatalf("execute fail: %s", err)
+	}
+	var found bool
+	for _, name := range out.Names() {
+		content, _ := out.ReadFile(name)
+		if strings.Contains(string(content), "*Order `gorm:\"foreignKey:OrderID;references:ID\" json:\"order\"`") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("relation field Order of Comment not generated")
+	}
+}
+
+// only tables selected by table filter and tables they reference are read, in schema of db name opts
+func TestGenerator_WithRelationInference_TableFilter(t *testing.T) {
+	var reads []string
+	RegisterDialector("fksqlite", sqlite.Open, WithDialectForeignKeys(func(db *gorm.DB, schema, table string) ([]ForeignKey, error) {
+		reads = append(reads, schema+"."+table)
+		return sqliteForeignKeys(db, schema, table)
+	}))
+	defer func() {
+		dialects.Lock()
+		delete(dialects.m, "fksqlite")
+		dialects.Unlock()
+	}()
+
+	dir := newModuleDir(t)
+	db, err := gorm.Open(sqlite.Open(filepath.Join(dir, "relation.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite fail: %s", err)
+	}
+	for _, stmt := range strings.Split(strings.TrimSpace(testRelationSchema), ";\n") {
+		if err = db.Exec(stmt).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	g := NewGenerator(Config{OutPath: filepath.Join(dir, "query")})
+	g.WithDialect("fksqlite")
+	g.WithDbNameOpts(func(*gorm.DB) string { return "main" })
+	g.WithTableFilter([]string{"orders"}, nil)
+	g.WithRelationInference(RelationInference{ForeignKeys: true})
+	g.UseDB(db)
+	if _, err = g.GenerateModelE("orders"); err != nil {
+		t.Fatalf("generate model fail: %s", err)
+	}
+	user, err := g.GenerateModelE("users")
+	if err != nil {
+		t.Fatalf("generate model fail: %s", err)
+	}
+
+	if expect := []string{"main.orders", "main.users"}; strings.Join(reads, ",") != strings.Join(expect, ",") {
+		t.Errorf("expect foreign keys read of %v, got %v", expect, reads)
+	}
+	if f := user.Fields[len(user.Fields)-1]; !f.IsRelation() || f.Type != "[]*Order" {
+		t.Errorf("expect relation to orders, got %+v", f)
+	}
+}
+
+func TestGenerator_WithRelationInference_DDL(t *testing.T) {
+	dir := newModuleDir(t)
+	sql := "CREATE TABLE users (id bigint NOT NULL PRIMARY KEY);\n" +
+		"CREATE TABLE orders (id bigint NOT NULL PRIMARY KEY, buyer_id bigint NOT NULL,\n" +
+		"  CONSTRAINT fk_orders_buyer FOREIGN KEY (buyer_id) REFERENCES users (id));\n"
+	if err := os.WriteFile(filepath.Join(dir, "schema.sql"), []byte(sql), 0600); err != nil {
+		t.Fatal(err)
+	}
+	db, err := OpenDDL(DbMySQL, filepath.Join(dir, "schema.sql"))
+	if err != nil {
+		t.Fatalf("open ddl fail: %s", err)
+	}
+
+	g := NewGenerator(Config{OutPath: filepath.Join(dir, "query")})
+	g.WithRelationInference(RelationInference{ForeignKeys: true})
+	g.UseDB(db)
+	user, _ := g.GenerateModelE("users")
+	order, _ := g.GenerateModelE("orders")
+
+	if f := user.Fields[len(user.Fields)-1]; f.Name != "Orders" || f.GORMTag != "foreignKey:BuyerID;references:ID" {
+		t.Errorf("unexpected relation of User: %+v", f)
+	}
+	if f := order.Fields[len(order.Fields)-1]; f.Name != "Buyer" || f.Type != "*User" {
+		t.Errorf("unexpected relation of Order: %+v", f)
+	}
+}
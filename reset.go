@@ -0,0 +1,131 @@
+package gen
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gogf/gf/v2/util/gconv"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// ResetConf scope and safety options of resetting database, see Conf.Reset
+type ResetConf struct {
+	Include    []string `yaml:"include,omitempty"`    // rules of tables to drop, empty for all tables, see TableFilter
+	Exclude    []string `yaml:"exclude,omitempty"`    // rules of tables never dropped
+	Disposable []string `yaml:"disposable,omitempty"` // rules of disposable database names, reset refuses to drop tables of other databases
+	DryRun     bool     `yaml:"dryRun,omitempty"`     // report drop statements without executing them
+	Dump       string   `yaml:"dump,omitempty"`       // file to dump schema and data of affected tables into before dropping
+}
+
+// Delete drop tables in scope of ResetConf when Reset is set
+func (r *Conf) Delete() error {
+	if !r.Reset {
+		return nil
+	}
+	opt := r.ResetConf
+	if !opt.DryRun {
+		if err := r.checkDisposable(); err != nil {
+			return err
+		}
+	}
+
+	tables, err := r.resetTables()
+	if err != nil {
+		return err
+	}
+	if len(tables) == 0 {
+		r.info("reset: no table to drop")
+		return nil
+	}
+
+	if opt.Dump != "" && opt.DryRun {
+		r.info(fmt.Sprintf("reset dry run: dump %d tables into %s", len(tables), opt.Dump))
+	} else if opt.Dump != "" {
+		if err = dumpTables(r.db, opt.Dump, tables); err != nil {
+			return fmt.Errorf("dump tables fail: %w", err)
+		}
+		r.info(fmt.Sprintf("reset: dump %d tables into %s", len(tables), opt.Dump))
+	}
+
+	if opt.DryRun {
+		stmts, err := dropStatements(r.db, tables)
+		if err != nil {
+			return fmt.Errorf("build drop statements fail: %w", err)
+		}
+		for _, stmt := range stmts {
+			r.info("reset dry run: " + stmt)
+		}
+		return nil
+	}
+
+	if err = r.db.Migrator().DropTable(gconv.SliceAny(tables)...); err != nil {
+		return fmt.Errorf("drop tables fail: %w", err)
+	}
+	r.info(fmt.Sprintf("reset: drop tables %s", strings.Join(tables, ", ")))
+	return nil
+}
+
+// checkDisposable return error unless name of connected database matches rules of ResetConf.Disposable
+func (r *Conf) checkDisposable() error {
+	name := r.db.Migrator().CurrentDatabase()
+	if r.db.Dialector.Name() == DbSQLite { // database of sqlite is named main, use its file name instead
+		var file string
+		if err := r.db.Raw("SELECT file FROM pragma_database_list WHERE name = 'main'").Scan(&file).Error; err != nil {
+			return fmt.Errorf("get database file fail: %w", err)
+		}
+		name = filepath.Base(file)
+	}
+	rules, err := compileTableRules(r.ResetConf.Disposable)
+	if err != nil {
+		return fmt.Errorf("invalid resetConf.disposable: %w", err)
+	}
+	if _, ok := matchTableRule(rules, name); !ok || name == "" {
+		return fmt.Errorf("refuse to reset database %q: it is not listed as disposable (add its name to resetConf.disposable)", name)
+	}
+	return nil
+}
+
+// resetTables return tables in scope of ResetConf
+func (r *Conf) resetTables() ([]string, error) {
+	all, err := r.db.Migrator().GetTables()
+	if err != nil {
+		return nil, fmt.Errorf("GORM migrator get all tables fail: %w", err)
+	}
+	if r.db.Dialector.Name() == DbSQLite { // internal tables, e.g. sqlite_sequence, can not be dropped
+		tables := all[:0]
+		for _, table := range all {
+			if !strings.HasPrefix(table, "sqlite_") {
+				tables = append(tables, table)
+			}
+		}
+		all = tables
+	}
+	return TableFilter{Include: r.ResetConf.Include, Exclude: r.ResetConf.Exclude}.Filter(all, nil)
+}
+
+// sqlRecorder logger recording traced statements
+type sqlRecorder struct {
+	logger.Interface
+	stmts []string
+}
+
+func (l *sqlRecorder) LogMode(logger.LogLevel) logger.Interface { return l }
+
+func (l *sqlRecorder) Trace(_ context.Context, _ time.Time, fc func() (string, int64), _ error) {
+	sql, _ := fc()
+	l.stmts = append(l.stmts, sql)
+}
+
+// dropStatements return statements executed by dropping tables, without executing them
+func dropStatements(db *gorm.DB, tables []string) ([]string, error) {
+	rec := &sqlRecorder{Interface: logger.Discard}
+	tx := db.Session(&gorm.Session{DryRun: true, Logger: rec})
+	if err := tx.Migrator().DropTable(gconv.SliceAny(tables)...); err != nil {
+		return nil, err
+	}
+	return rec.stmts, nil
+}
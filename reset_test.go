@@ -0,0 +1,184 @@
+package gen
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newResetConf(t *testing.T, opt ResetConf) (*Conf, *[]string) {
+	conf := &Conf{DB: DbSQLite, DSN: filepath.Join(t.TempDir(), "reset.db"), Reset: true, ResetConf: opt}
+	if err := conf.build(); err != nil {
+		t.Fatalf("build conf fail: %s", err)
+	}
+	if err := conf.Connect(); err != nil {
+		t.Fatalf("connect fail: %s", err)
+	}
+	var msgs []string
+	conf.WithReporter(ReporterFunc(func(e Event) { msgs = append(msgs, e.Message) }))
+	for _, sql := range []string{
+		"CREATE TABLE users (id integer PRIMARY KEY, name text NOT NULL)",
+		"CREATE TABLE orders (id integer PRIMARY KEY, amount integer)",
+		"CREATE TABLE audits (id integer PRIMARY KEY)",
+		"INSERT INTO users (id, name) VALUES (1, 'it''s me')",
+	} {
+		if err := conf.db.Exec(sql).Error; err != nil {
+			t.Fatalf("exec %s fail: %s", sql, err)
+		}
+	}
+	return conf, &msgs
+}
+
+func remainTables(t *testing.T, conf *Conf) []string {
+	var tables []string
+	if err := conf.db.Raw("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'").Scan(&tables).Error; err != nil {
+		t.Fatalf("get tables fail: %s", err)
+	}
+	sort.Strings(tables)
+	return tables
+}
+
+func TestConf_Delete(t *testing.T) {
+	t.Run("not disposable", func(t *testing.T) {
+		for _, disposable := range [][]string{nil, {"dev.db", "/^test_/"}} {
+			conf, _ := newResetConf(t, ResetConf{Disposable: disposable})
+			if err := conf.Delete(); err == nil || !strings.Contains(err.Error(), "disposable") {
+				t.Fatalf("expect disposable error, got %v", err)
+			}
+			if tables := remainTables(t, conf); len(tables) != 3 {
+				t.Errorf("no table should be dropped, got %v", tables)
+			}
+		}
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		dump := filepath.Join(t.TempDir(), "dump.sql")
+		conf, msgs := newResetConf(t, ResetConf{Include: []string{"users", "orders"}, DryRun: true, Dump: dump})
+		if err := conf.Delete(); err != nil {
+			t.Fatalf("reset fail: %s", err)
+		}
+		if tables := remainTables(t, conf); len(tables) != 3 {
+			t.Errorf("no table should be dropped, got %v", tables)
+		}
+		if _, err := os.Stat(dump); !os.IsNotExist(err) {
+			t.Errorf("dump should not be written in dry run, got %v", err)
+		}
+		report := strings.Join(*msgs, "\n")
+		if !strings.Contains(report, "reset dry run: dump 2 tables into "+dump) {
+			t.Errorf("dump plan not reported:\n%s", report)
+		}
+		for _, table := range []string{"users", "orders"} {
+			if !strings.Contains(report, "DROP TABLE IF EXISTS `"+table+"`") {
+				t.Errorf("drop statement of %s not reported:\n%s", table, report)
+			}
+		}
+		if strings.Contains(report, "audits") {
+			t.Errorf("table out of scope reported:\n%s", report)
+		}
+	})
+
+	t.Run("scoped with dump", func(t *testing.T) {
+		dump := filepath.Join(t.TempDir(), "dump.sql")
+		conf, _ := newResetConf(t, ResetConf{Exclude: []string{"audits"}, Disposable: []string{"reset.db"}, Dump: dump})
+		for _, sql := range []string{
+			"CREATE TABLE accounts (id integer PRIMARY KEY AUTOINCREMENT, user_id integer NOT NULL REFERENCES users (id) ON DELETE CASCADE, " +
+				"note text DEFAULT 'none', avatar blob, active boolean DEFAULT 1, created_at datetime)",
+			"CREATE INDEX idx_accounts_user ON accounts (user_id)",
+			"INSERT INTO accounts (user_id, note, avatar, active, created_at) VALUES (1, 'back\\slash '' \"quoted\"\nline', X'00FF27', 1, '2024-02-03 04:05:06.789+08:00')",
+			"INSERT INTO accounts (user_id) VALUES (1)",
+		} {
+			if err := conf.db.Exec(sql).Error; err != nil {
+				t.Fatalf("exec %s fail: %s", sql, err)
+			}
+		}
+		snapshot := func() (schema, data []map[string]interface{}) {
+			if err := conf.db.Raw("SELECT type, name, sql FROM sqlite_master WHERE name NOT LIKE 'sqlite_%' ORDER BY name").Scan(&schema).Error; err != nil {
+				t.Fatal(err)
+			}
+			for _, table := range []string{"accounts", "audits", "orders", "users"} {
+				var rows []map[string]interface{}
+				if err := conf.db.Table(table).Order("id").Find(&rows).Error; err != nil {
+					t.Fatal(err)
+				}
+				data = append(data, rows...)
+			}
+			return schema, data
+		}
+		schema, data := snapshot()
+
+		if err := conf.Delete(); err != nil {
+			t.Fatalf("reset fail: %s", err)
+		}
+		if tables := remainTables(t, conf); !reflect.DeepEqual(tables, []string{"audits"}) {
+			t.Errorf("expect [audits] kept, got %v", tables)
+		}
+
+		info, err := os.Stat(dump)
+		if err != nil {
+			t.Fatalf("stat dump fail: %s", err)
+		}
+		if perm := info.Mode().Perm(); perm != 0600 {
+			t.Errorf("dump should be readable by owner only, got %v", perm)
+		}
+		content, err := os.ReadFile(dump)
+		if err != nil {
+			t.Fatalf("read dump fail: %s", err)
+		}
+		if strings.Contains(string(content), "audits") {
+			t.Errorf("excluded table dumped:\n%s", content)
+		}
+		if strings.Index(string(content), "-- table users") > strings.Index(string(content), "-- table accounts") {
+			t.Errorf("referenced table should be restored first:\n%s", content)
+		}
+
+		sqlDB, err := conf.db.DB()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = sqlDB.Exec(string(content)); err != nil {
+			t.Fatalf("restore dump fail: %s\n%s", err, content)
+		}
+		restoredSchema, restoredData := snapshot()
+		if !reflect.DeepEqual(restoredSchema, schema) {
+			t.Errorf("restored schema differs\nexpect: %v\ngot:    %v", schema, restoredSchema)
+		}
+		if !reflect.DeepEqual(restoredData, data) {
+			t.Errorf("restored data differs\nexpect: %v\ngot:    %v", data, restoredData)
+		}
+	})
+}
+
+func TestSQLLiteral(t *testing.T) {
+	at := time.Date(2024, 2, 3, 4, 5, 6, 789000000, time.FixedZone("", 8*3600))
+	for _, tt := range []struct {
+		dialect string
+		value   interface{}
+		binary  bool
+		dbType  string
+		want    string
+	}{
+		{DbMySQL, nil, false, "VARCHAR", "NULL"},
+		{DbMySQL, []byte("it's"), false, "VARCHAR", "'it''s'"},
+		{DbMySQL, []byte(`a\b`), false, "TEXT", "X'615c62'"},
+		{DbMySQL, []byte{0, 0xff}, true, "VARBINARY", "X'00ff'"},
+		{DbMySQL, at, false, "DATETIME", "'2024-02-03 04:05:06.789'"},
+		{DbMySQL, true, false, "TINYINT", "TRUE"},
+		{DbPostgres, `a\'b`, false, "TEXT", `E'a\\''b'`},
+		{DbPostgres, []byte{1, 2}, true, "BYTEA", "decode('0102', 'hex')"},
+		{DbPostgres, at, false, "TIMESTAMPTZ", "'2024-02-03 04:05:06.789+08:00'"},
+		{DbPostgres, 1.5, false, "NUMERIC", "1.5"},
+		{DbSQLServer, "it's", false, "NVARCHAR", "N'it''s'"},
+		{DbSQLServer, []byte{0xab}, true, "VARBINARY", "0xab"},
+		{DbSQLServer, at, false, "DATETIME", "N'2024-02-03T04:05:06.789'"},
+		{DbSQLServer, false, false, "BIT", "0"},
+		{DbSQLite, int64(-3), false, "INTEGER", "-3"},
+	} {
+		if got := sqlLiteral(tt.dialect, tt.value, tt.binary, tt.dbType); got != tt.want {
+			t.Errorf("%s literal of %#v: expect %s, got %s", tt.dialect, tt.value, tt.want, got)
+		}
+	}
+}
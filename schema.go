@@ -1,9 +1,13 @@
 package gen
 
 import (
-	"gorm.io/gen/field"
-	"gorm.io/gorm/schema"
+	"fmt"
+	"sort"
 	"sync"
+
+	"gorm.io/gorm/schema"
+
+	"gorm.io/gen/field"
 )
 
 type Schema struct {
@@ -30,7 +34,7 @@ func (r *Schema) LinkModel(dst ...any) (err error) {
 	var (
 		parse *schema.Schema
 	)
-	if err = r.db.AutoMigrate(dst...); err != nil {
+	if err = r.migrate(dst...); err != nil {
 		return
 	}
 	for _, v := range dst {
@@ -43,7 +47,7 @@ func (r *Schema) LinkModel(dst ...any) (err error) {
 	}
 	return nil
 }
-func (r *Schema) GetModelOpt(table string) (opt []ModelOpt) {
+func (r *Schema) GetModelOpt(table string) (opt []ModelOpt, err error) {
 	var (
 		schema1 = r.GetSchema(table)
 		schema2 *schema.Schema
@@ -51,9 +55,19 @@ func (r *Schema) GetModelOpt(table string) (opt []ModelOpt) {
 	if schema1 == nil {
 		return
 	}
-	for _, item := range schema1.Relationships.Relations {
+	names := make([]string, 0, len(schema1.Relationships.Relations))
+	for name := range schema1.Relationships.Relations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		item := schema1.Relationships.Relations[name]
 		if schema2 = r.GetSchema(item.FieldSchema.Table); schema2 != nil {
-			opt = append(opt, FieldRelate(field.RelationshipType(item.Type), item.Name, r.GenerateModel(schema2.Table), &field.RelateConfig{
+			related, err := r.GenerateModelE(schema2.Table)
+			if err != nil {
+				return nil, fmt.Errorf("relation %s: %w", item.Name, err)
+			}
+			opt = append(opt, FieldRelate(field.RelationshipType(item.Type), item.Name, related, &field.RelateConfig{
 				OverwriteTag: string(item.Field.Tag),
 				Key: func() string {
 					if rr, ok := item.Field.TagSettings["FOREIGNKEY"]; ok {
@@ -70,5 +84,5 @@ func (r *Schema) GetModelOpt(table string) (opt []ModelOpt) {
 			opt = append(opt, FieldNew(item.Name, item.FieldType.String(), string(item.Tag)))
 		}
 	}
-	return opt
+	return opt, nil
 }
@@ -0,0 +1,113 @@
+package gen
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// TableFilter select tables by include and exclude rules.
+// A rule is a glob pattern in path.Match syntax, e.g. order_*, or a regular expression
+// wrapped in slashes, e.g. /^log_\d{6}$/. Empty Include selects every table,
+// Exclude wins over Include.
+type TableFilter struct {
+	Include []string `yaml:"include,omitempty"`
+	Exclude []string `yaml:"exclude,omitempty"`
+}
+
+// WithTableFilter specify include and exclude rules of tables read from db, see TableFilter
+func (cfg *Config) WithTableFilter(include, exclude []string) {
+	cfg.tableFilter = TableFilter{Include: include, Exclude: exclude}
+}
+
+type tableRule struct {
+	rule    string
+	literal bool
+	match   func(table string) bool
+}
+
+func compileTableRules(rules []string) ([]tableRule, error) {
+	compiled := make([]tableRule, 0, len(rules))
+	for _, rule := range rules {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		if len(rule) > 2 && strings.HasPrefix(rule, "/") && strings.HasSuffix(rule, "/") {
+			re, err := regexp.Compile(rule[1 : len(rule)-1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid table rule %q: %w", rule, err)
+			}
+			compiled = append(compiled, tableRule{rule: rule, match: re.MatchString})
+			continue
+		}
+		if _, err := path.Match(rule, ""); err != nil {
+			return nil, fmt.Errorf("invalid table rule %q: %w", rule, err)
+		}
+		pattern := rule
+		compiled = append(compiled, tableRule{
+			rule:    rule,
+			literal: !strings.ContainsAny(rule, `*?[\`),
+			match:   func(table string) bool { ok, _ := path.Match(pattern, table); return ok },
+		})
+	}
+	return compiled, nil
+}
+
+func matchTableRule(rules []tableRule, table string) (tableRule, bool) {
+	for _, r := range rules {
+		if r.match(table) {
+			return r, true
+		}
+	}
+	return tableRule{}, false
+}
+
+// Filter return selected tables in order, skip is called with the reason of every skipped table.
+// Tables named literally by Include but not in tables are skipped as not found.
+func (f TableFilter) Filter(tables []string, skip func(table, reason string)) ([]string, error) {
+	include, err := compileTableRules(f.Include)
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := compileTableRules(f.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	if skip == nil {
+		skip = func(string, string) {}
+	}
+
+	listed := make(map[string]bool, len(tables))
+	selected := make([]string, 0, len(tables))
+	pick := func(table string) {
+		if r, ok := matchTableRule(exclude, table); ok {
+			skip(table, fmt.Sprintf("excluded by rule %q", r.rule))
+			return
+		}
+		selected = append(selected, table)
+	}
+	for _, table := range tables {
+		listed[table] = true
+		if _, ok := matchTableRule(include, table); len(include) > 0 && !ok {
+			skip(table, "not matched by include rules")
+			continue
+		}
+		pick(table)
+	}
+	for _, r := range include {
+		if r.literal && !listed[r.rule] {
+			listed[r.rule] = true
+			skip(r.rule, "not found")
+		}
+	}
+	return selected, nil
+}
+
+// filterTables apply table filter of config, every skipped table is reported
+func (g *Generator) filterTables(tables []string) ([]string, error) {
+	return g.tableFilter.Filter(tables, func(table, reason string) {
+		g.report(Event{Kind: EventInfo, Message: fmt.Sprintf("skip table %s: %s", table, reason), Table: table})
+	})
+}
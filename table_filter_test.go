@@ -0,0 +1,109 @@
+package gen
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTableFilter_Filter(t *testing.T) {
+	tables := []string{"orders", "order_items", "order_bak", "users", "tmp_users", "log_202401", "log_x"}
+
+	testcases := []struct {
+		name   string
+		filter TableFilter
+		expect []string
+		skips  map[string]string
+	}{
+		{
+			name:   "all",
+			expect: tables,
+		},
+		{
+			name:   "glob and regexp",
+			filter: TableFilter{Include: []string{"order*", `/^log_\d{6}$/`}, Exclude: []string{"*_bak", "tmp_*"}},
+			expect: []string{"orders", "order_items", "log_202401"},
+			skips: map[string]string{
+				"order_bak": `excluded by rule "*_bak"`,
+				"users":     "not matched by include rules",
+				"tmp_users": "not matched by include rules",
+				"log_x":     "not matched by include rules",
+			},
+		},
+		{
+			name:   "exclude only",
+			filter: TableFilter{Exclude: []string{"*_bak", "tmp_*"}},
+			expect: []string{"orders", "order_items", "users", "log_202401", "log_x"},
+			skips: map[string]string{
+				"order_bak": `excluded by rule "*_bak"`,
+				"tmp_users": `excluded by rule "tmp_*"`,
+			},
+		},
+		{
+			name:   "literal not listed",
+			filter: TableFilter{Include: []string{"users", "user_missing"}},
+			expect: []string{"users"},
+			skips: map[string]string{
+				"orders":       "not matched by include rules",
+				"order_items":  "not matched by include rules",
+				"order_bak":    "not matched by include rules",
+				"tmp_users":    "not matched by include rules",
+				"log_202401":   "not matched by include rules",
+				"log_x":        "not matched by include rules",
+				"user_missing": "not found",
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			skips := make(map[string]string)
+			got, err := tc.filter.Filter(tables, func(table, reason string) { skips[table] = reason })
+			if err != nil {
+				t.Fatalf("filter fail: %s", err)
+			}
+			if !reflect.DeepEqual(got, tc.expect) {
+				t.Errorf("expect %v, got %v", tc.expect, got)
+			}
+			if tc.skips != nil && !reflect.DeepEqual(skips, tc.skips) {
+				t.Errorf("expect skips %v, got %v", tc.skips, skips)
+			}
+		})
+	}
+
+	if _, err := (TableFilter{Exclude: []string{"/(/"}}).Filter(tables, nil); err == nil {
+		t.Errorf("expect error of invalid regexp")
+	}
+}
+
+func TestConf_GenModelsFilter(t *testing.T) {
+	conf := &Conf{DB: DbSQLite, DSN: filepath.Join(t.TempDir(), "filter.db"), OutPath: t.TempDir(),
+		Tables: []string{"order*", "customers"}, Exclude: []string{"*_bak"}}
+	if err := conf.build(); err != nil {
+		t.Fatalf("build conf fail: %s", err)
+	}
+	if err := conf.Connect(); err != nil {
+		t.Fatalf("connect fail: %s", err)
+	}
+	var skipped []string
+	conf.WithReporter(ReporterFunc(func(e Event) {
+		if e.Table != "" && e.Kind == EventInfo {
+			skipped = append(skipped, e.Table)
+		}
+	}))
+	for _, table := range []string{"orders", "order_bak", "users"} {
+		if err := conf.db.Exec("CREATE TABLE " + table + " (id integer primary key)").Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	models, err := conf.GenModels()
+	if err != nil {
+		t.Fatalf("generate models fail: %s", err)
+	}
+	if len(models) != 1 || models[0].(*QueryStructMeta).TableName != "orders" {
+		t.Errorf("expect only orders generated, got %d models", len(models))
+	}
+	if !reflect.DeepEqual(skipped, []string{"order_bak", "users", "customers"}) {
+		t.Errorf("expect order_bak, users and missing customers skipped, got %v", skipped)
+	}
+}
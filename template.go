@@ -0,0 +1,235 @@
+package gen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+
+	"gorm.io/gen/internal/generate"
+	tmpl "gorm.io/gen/internal/template"
+)
+
+// templateExt extension of template files in template fs
+const templateExt = ".tmpl"
+
+// builtinTemplates compiled-in templates, can be overridden by name
+var builtinTemplates = map[string]string{
+	"Header":                      tmpl.Header,
+	"Model":                       tmpl.Model,
+	"ModelMethod":                 tmpl.ModelMethod,
+	"Field":                       tmpl.Field,
+	"DefaultQuery":                tmpl.DefaultQuery,
+	"QueryMethod":                 tmpl.QueryMethod,
+	"QueryMethodTest":             tmpl.QueryMethodTest,
+	"TableQueryStruct":            tmpl.TableQueryStruct,
+	"TableQueryStructWithContext": tmpl.TableQueryStructWithContext,
+	"TableQueryIface":             tmpl.TableQueryIface,
+	"DIYMethod":                   tmpl.DIYMethod,
+	"DIYMethodTest":               tmpl.DIYMethodTest,
+	"DIYMethodTestBasic":          tmpl.DIYMethodTestBasic,
+	"CRUDMethod":                  tmpl.CRUDMethod,
+	"CRUDMethodTest":              tmpl.CRUDMethodTest,
+}
+
+// TemplateNames return names of compiled-in templates which can be overridden
+func TemplateNames() []string {
+	names := make([]string, 0, len(builtinTemplates))
+	for name := range builtinTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// extraTemplate user template rendered into an extra file
+type extraTemplate struct {
+	name string // model templates: output <file>.<name>.gen.go, package templates: output <name>.gen.go
+	text string
+}
+
+// WithTemplateFS specify templates fs, layout:
+//
+//	<Name>.tmpl          override compiled-in template <Name>, see TemplateNames
+//	model/<name>.tmpl    rendered once per model with QueryStructMeta as data into <OutPath>/<file>.<name>.gen.go
+//	package/<name>.tmpl  rendered once with Generator as data into <OutPath>/<name>.gen.go
+func (cfg *Config) WithTemplateFS(fsys fs.FS) {
+	cfg.templateFS = fsys
+}
+
+// WithTemplateDir specify templates directory, see WithTemplateFS for layout
+func (cfg *Config) WithTemplateDir(dir string) {
+	cfg.WithTemplateFS(os.DirFS(dir))
+}
+
+// WithModelTemplate add template rendered once per model with QueryStructMeta as data into <OutPath>/<file>.<name>.gen.go
+func (cfg *Config) WithModelTemplate(name, text string) {
+	cfg.modelTemplates = append(cfg.modelTemplates, extraTemplate{name: name, text: text})
+}
+
+// WithPackageTemplate add template rendered once with Generator as data into <OutPath>/<name>.gen.go
+func (cfg *Config) WithPackageTemplate(name, text string) {
+	cfg.packageTemplates = append(cfg.packageTemplates, extraTemplate{name: name, text: text})
+}
+
+// loadTemplates read overrides and extra templates from template fs, check all templates can be parsed
+func (g *Generator) loadTemplates() error {
+	g.templates = make(map[string]string, len(builtinTemplates))
+	for name, text := range builtinTemplates {
+		g.templates[name] = text
+	}
+	g.extraModelTemplates = append([]extraTemplate(nil), g.modelTemplates...)
+	g.extraPackageTemplates = append([]extraTemplate(nil), g.packageTemplates...)
+
+	if g.templateFS != nil {
+		entries, err := fs.ReadDir(g.templateFS, ".")
+		if err != nil {
+			return fmt.Errorf("read template fs fail: %w", err)
+		}
+		for _, entry := range entries {
+			name := strings.TrimSuffix(entry.Name(), templateExt)
+			switch {
+			case entry.IsDir() && (entry.Name() == "model" || entry.Name() == "package"):
+				continue
+			case entry.IsDir() || !strings.HasSuffix(entry.Name(), templateExt):
+				continue
+			case builtinTemplates[name] == "":
+				return fmt.Errorf("unknown template %q, can only override: %s", entry.Name(), strings.Join(TemplateNames(), ", "))
+			}
+			text, err := fs.ReadFile(g.templateFS, entry.Name())
+			if err != nil {
+				return fmt.Errorf("read template %s fail: %w", entry.Name(), err)
+			}
+			g.templates[name] = string(text)
+		}
+
+		if g.extraModelTemplates, err = readExtraTemplates(g.templateFS, "model", g.extraModelTemplates); err != nil {
+			return err
+		}
+		if g.extraPackageTemplates, err = readExtraTemplates(g.templateFS, "package", g.extraPackageTemplates); err != nil {
+			return err
+		}
+	}
+
+	for name, text := range g.templates {
+		if _, err := template.New(name).Parse(text); err != nil {
+			return fmt.Errorf("parse template %s fail: %w", name, err)
+		}
+	}
+	for _, t := range append(append([]extraTemplate(nil), g.extraModelTemplates...), g.extraPackageTemplates...) {
+		if _, err := template.New(t.name).Parse(t.text); err != nil {
+			return fmt.Errorf("parse template %s fail: %w", t.name, err)
+		}
+	}
+	return nil
+}
+
+func readExtraTemplates(fsys fs.FS, dir string, tmpls []extraTemplate) ([]extraTemplate, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return tmpls, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read template dir %s fail: %w", dir, err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), templateExt) {
+			continue
+		}
+		text, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("read template %s/%s fail: %w", dir, entry.Name(), err)
+		}
+		tmpls = append(tmpls, extraTemplate{name: strings.TrimSuffix(entry.Name(), templateExt), text: string(text)})
+	}
+	return tmpls, nil
+}
+
+// template return template content by name, overrides first
+func (g *Generator) template(name string) string {
+	if text, ok := g.templates[name]; ok {
+		return text
+	}
+	return builtinTemplates[name]
+}
+
+// render render template by name
+func (g *Generator) render(name string, wr io.Writer, data interface{}) error {
+	if err := render(g.template(name), wr, data); err != nil {
+		return &TemplateError{Template: name, Err: err}
+	}
+	return nil
+}
+
+// extraModelFile return output file of model extra template
+func (g *Generator) extraModelFile(meta *generate.QueryStructMeta, t extraTemplate) string {
+	return fmt.Sprintf("%s/%s.%s.gen.go", g.OutPath, meta.FileName, t.name)
+}
+
+// generateExtraFiles render user extra templates per model and per package
+func (g *Generator) generateExtraFiles() error {
+	var errs errCollector
+	if len(g.extraModelTemplates) > 0 {
+		for _, meta := range g.allMetas() {
+			files := make([]string, len(g.extraModelTemplates))
+			for i, t := range g.extraModelTemplates {
+				files[i] = g.extraModelFile(meta, t)
+			}
+			if g.skipUnchanged(meta.ModelStructName, files...) {
+				continue
+			}
+			meta.Do()
+			for i, t := range g.extraModelTemplates {
+				errs.Add(meta.TableName, files[i], g.generateExtraFile(&RenderFile{Name: files[i], Kind: ExtraFileKind, Meta: meta}, t, meta))
+			}
+		}
+	}
+
+	for _, t := range g.extraPackageTemplates {
+		fileName := fmt.Sprintf("%s/%s.gen.go", g.OutPath, t.name)
+		errs.Add("", fileName, g.generateExtraFile(&RenderFile{Name: fileName, Kind: ExtraFileKind}, t, g))
+	}
+	return errs.Err()
+}
+
+// generateExtraFile render user extra template and save to file
+func (g *Generator) generateExtraFile(file *RenderFile, t extraTemplate, data interface{}) error {
+	if err := g.beforeRender(file); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := render(t.text, &buf, data); err != nil {
+		return &TemplateError{Template: t.name, Err: err}
+	}
+	return g.outputRendered(file, buf.Bytes())
+}
+
+// allMetas return metas of all query structs and models, ordered by model struct name
+func (g *Generator) allMetas() []*generate.QueryStructMeta {
+	metas := make(map[string]*generate.QueryStructMeta, len(g.models)+len(g.Data))
+	for name, meta := range g.models {
+		if meta != nil {
+			metas[name] = meta
+		}
+	}
+	for name, info := range g.Data {
+		metas[name] = info.QueryStructMeta
+	}
+
+	names := make([]string, 0, len(metas))
+	for name := range metas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	result := make([]*generate.QueryStructMeta, len(names))
+	for i, name := range names {
+		result[i] = metas[name]
+	}
+	return result
+}
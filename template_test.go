@@ -0,0 +1,56 @@
+package gen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestGenerator_Templates(t *testing.T) {
+	outPath := filepath.Join(newModuleDir(t), "query")
+	g := NewGenerator(Config{OutPath: outPath, ModelPkgPath: "query", Mode: WithDefaultQuery | WithoutContext | WithQueryInterface})
+	g.WithTemplateFS(fstest.MapFS{
+		"Field.tmpl": {Data: []byte("package {{.Package}}\n\n// Overridden {{.StructName}}\nconst Table = \"{{.StructName}}\"\n")},
+		"model/dto.tmpl": {Data: []byte("package query\n\ntype {{.ModelStructName}}DTO struct {\n" +
+			"{{range .Fields}}\t{{.Name}} {{.Type}}\n{{end}}}\n")},
+		"package/registry.tmpl": {Data: []byte("package query\n\nvar Tables = []string{ {{range $name, $d := .Data}}\"{{$d.TableName}}\", {{end}} }\n")},
+	})
+	g.WithModelTemplate("ext", "package query\n\nfunc ({{.ModelStructName}}) Ext() string { return \"{{.TableName}}\" }\n")
+	g.ApplyBasic(g.GenerateModelFrom(testUserObject), g.GenerateModelFrom(testCompanyObject))
+	if err := g.ExecuteE(); err != nil {
+		t.Fatalf("execute fail: %s", err)
+	}
+
+	for file, expect := range map[string]string{
+		"users/users.gen.go":   "// Overridden User",
+		"users.dto.gen.go":     "type UserDTO struct {\n\tID   int64",
+		"companies.dto.gen.go": "type CompanyDTO struct",
+		"users.ext.gen.go":     `return "users"`,
+		"registry.gen.go":      `var Tables = []string{"companies", "users"}`,
+	} {
+		content, err := os.ReadFile(filepath.Join(outPath, file))
+		if err != nil {
+			t.Errorf("read %s fail: %s", file, err)
+			continue
+		}
+		if !strings.Contains(string(content), expect) {
+			t.Errorf("expect %s contains %q, got:\n%s", file, expect, content)
+		}
+	}
+}
+
+func TestGenerator_TemplatesUnknown(t *testing.T) {
+	g := NewGenerator(Config{OutPath: filepath.Join(newModuleDir(t), "query"), ModelPkgPath: "query"})
+	g.WithTemplateFS(fstest.MapFS{"Modle.tmpl": {Data: []byte("")}})
+	if err := g.ExecuteE(); err == nil || !strings.Contains(err.Error(), `unknown template "Modle.tmpl"`) {
+		t.Errorf("expect unknown template error, got %v", err)
+	}
+
+	g = NewGenerator(Config{OutPath: filepath.Join(newModuleDir(t), "query"), ModelPkgPath: "query"})
+	g.WithModelTemplate("broken", "{{.Name")
+	if err := g.ExecuteE(); err == nil || !strings.Contains(err.Error(), "parse template broken fail") {
+		t.Errorf("expect parse template error, got %v", err)
+	}
+}
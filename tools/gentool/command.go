@@ -0,0 +1,156 @@
+package main
+
+import (
+	_ "embed"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"gorm.io/gen"
+	"gorm.io/gorm"
+)
+
+//go:embed gen.yml
+var defaultConfig []byte
+
+// usage print commands and flags of fs
+func usage(fs *flag.FlagSet) func() {
+	return func() {
+		out := fs.Output()
+		fmt.Fprintf(out, `Usage: gentool [command] [flags]
+
+Commands:
+  init              create a commented gen.yml
+  generate          generate code of selected tables (default)
+  tables            list tables to generate, and why other tables are skipped
+  inspect <table>   print columns, indexes, go types and tags gen would use for table
+  watch             generate, then generate again on changes of gen.yml, interface files or schema
+  migrate <op>      apply sql migrations of migrationDir, op: up, down, status, goto <version>, unlock
+
+Flags of %s:
+`, fs.Name())
+		fs.PrintDefaults()
+	}
+}
+
+// initConfig write default gen.yml, existing file is kept
+func initConfig(args []string) error {
+	fs := flag.NewFlagSet("gentool init", flag.ExitOnError)
+	path := fs.String("c", "gen.yml", "is path for gen.yml to create")
+	_ = fs.Parse(args)
+
+	if _, err := os.Stat(*path); err == nil {
+		return fmt.Errorf("%s already exists", *path)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := os.WriteFile(*path, defaultConfig, 0640); err != nil {
+		return fmt.Errorf("create %s fail: %w", *path, err)
+	}
+	fmt.Printf("%s created, edit dsn and run: gentool -c %s\n", *path, *path)
+	return nil
+}
+
+// listTables print tables of database with the reason why they are skipped
+func listTables(args []string) error {
+	config, _, err := argParse("tables", args, nil)
+	if err != nil {
+		return fmt.Errorf("parse config fail: %w", err)
+	}
+	db, err := connectDB(DBType(config.DB), config.DSN)
+	if err != nil {
+		return fmt.Errorf("connect db server fail: %w", err)
+	}
+	tables, err := db.Migrator().GetTables()
+	if err != nil {
+		return fmt.Errorf("GORM migrator get all tables fail: %w", err)
+	}
+
+	skipped := make(map[string]string)
+	selected, err := gen.TableFilter{Include: config.Tables, Exclude: config.Exclude}.Filter(tables, func(table, reason string) {
+		skipped[table] = reason
+	})
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "TABLE\tSTATUS")
+	for _, table := range selected {
+		fmt.Fprintf(w, "%s\tgenerate\n", table)
+	}
+	for _, table := range tables {
+		if reason, ok := skipped[table]; ok {
+			fmt.Fprintf(w, "%s\tskip: %s\n", table, reason)
+		}
+	}
+	return w.Flush()
+}
+
+// inspectTable print columns and indexes of table with go types and tags gen would use
+func inspectTable(args []string) error {
+	config, rest, err := argParse("inspect", args, nil)
+	if err != nil {
+		return fmt.Errorf("parse config fail: %w", err)
+	}
+	if len(rest) != 1 {
+		return fmt.Errorf("usage: gentool inspect [flags] <table>")
+	}
+	table := rest[0]
+
+	config.Report = "text"
+	g, db, err := newGenerator(config, nil)
+	if err != nil {
+		return err
+	}
+	g.WithReporter(gen.ReporterFunc(func(gen.Event) {})) // keep progress out of the inspection
+
+	columns, err := db.Migrator().ColumnTypes(table)
+	if err != nil {
+		return fmt.Errorf("get columns of %s fail: %w", table, err)
+	}
+	meta, err := g.GenerateModelE(table)
+	if err != nil {
+		return err
+	}
+	fields := make(map[string]*gen.ModelField, len(meta.Fields))
+	for _, f := range meta.Fields {
+		fields[f.ColumnName] = f
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "table %s -> model %s\n\n", table, meta.ModelStructName)
+	fmt.Fprintln(w, "COLUMN\tDB TYPE\tCOLUMN TYPE\tNULLABLE\tFIELD\tGO TYPE\tTAGS")
+	for _, col := range columns {
+		colType, _ := col.ColumnType()
+		nullable := "-"
+		if v, ok := col.Nullable(); ok {
+			nullable = fmt.Sprint(v)
+		}
+		name, goType, tags := "-", "-", "-"
+		if f, ok := fields[col.Name()]; ok {
+			name, goType, tags = f.Name, f.Type, f.Tags()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", col.Name(), col.DatabaseTypeName(), colType, nullable, name, goType, tags)
+	}
+	printIndexes(w, db, table)
+	return w.Flush()
+}
+
+// printIndexes print indexes of table
+func printIndexes(w *tabwriter.Writer, db *gorm.DB, table string) {
+	indexes, err := db.Migrator().GetIndexes(table)
+	if err != nil {
+		fmt.Fprintf(w, "\nindexes: %s\n", err)
+		return
+	}
+	fmt.Fprintln(w, "\nINDEX\tCOLUMNS\tPRIMARY\tUNIQUE")
+	for _, idx := range indexes {
+		primary, _ := idx.PrimaryKey()
+		unique, _ := idx.Unique()
+		fmt.Fprintf(w, "%s\t%s\t%t\t%t\n", idx.Name(), strings.Join(idx.Columns(), ","), primary, unique)
+	}
+}
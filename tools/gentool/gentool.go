@@ -1,17 +1,13 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"os"
 	"strings"
 
-	"gopkg.in/yaml.v3"
-	"gorm.io/driver/mysql"
-	"gorm.io/driver/postgres"
-	"gorm.io/driver/sqlite"
-	"gorm.io/driver/sqlserver"
 	"gorm.io/gen"
 	"gorm.io/gorm"
 )
@@ -35,7 +31,8 @@ const (
 type CmdParams struct {
 	DSN               string   `yaml:"dsn"`               // consult[https://gorm.io/docs/connecting_to_the_database.html]"
 	DB                string   `yaml:"db"`                // input mysql or postgres or sqlite or sqlserver. consult[https://gorm.io/docs/connecting_to_the_database.html]
-	Tables            []string `yaml:"tables"`            // enter the required data table or leave it blank
+	Tables            []string `yaml:"tables"`            // enter the required data table or leave it blank, glob or /regexp/ rules are supported
+	Exclude           []string `yaml:"exclude"`           // tables skipped, glob or /regexp/ rules are supported
 	OnlyModel         bool     `yaml:"onlyModel"`         // only generate model
 	OutPath           string   `yaml:"outPath"`           // specify a directory for output
 	OutFile           string   `yaml:"outFile"`           // query code file name, default: gen.go
@@ -45,6 +42,24 @@ type CmdParams struct {
 	FieldWithIndexTag bool     `yaml:"fieldWithIndexTag"` // generate field with gorm index tag
 	FieldWithTypeTag  bool     `yaml:"fieldWithTypeTag"`  // generate field with gorm column type tag
 	FieldSignable     bool     `yaml:"fieldSignable"`     // detect integer field's unsigned type, adjust generated data type
+	InferRelations    bool     `yaml:"inferRelations"`    // generate relation fields from foreign keys and join tables
+	Incremental       *bool    `yaml:"incremental"`       // skip rendering models whose schema and config are unchanged since last run, default: true
+	ERDiagram         []string `yaml:"erDiagram"`         // entity relationship diagram formats written into outPath: mermaid, plantuml, dot
+	DataDictionary    []string `yaml:"dataDictionary"`    // data dictionary formats written into outPath: markdown, html
+	MigrationDir      string   `yaml:"migrationDir"`      // directory of versioned sql migrations applied by migrate command
+	MigrationTable    string   `yaml:"migrationTable"`    // history table of applied migrations, default: gen_migrations
+	Check             bool     `yaml:"-"`                 // compare generated code with files on disk, exit non-zero if stale
+	Force             bool     `yaml:"-"`                 // regenerate every model even if its schema and config are unchanged
+	Report            string   `yaml:"-"`                 // progress report format: text or json
+	ConfigFile        string   `yaml:"-"`                 // path of gen.yml, empty if not used
+	Env               string   `yaml:"-"`                 // overlay loaded over gen.yml, empty if not used
+
+	Interfaces []gen.InterfaceConf `yaml:"interfaces"` // DIY method interfaces applied to tables
+}
+
+// incremental report whether unchanged models are skipped, on unless disabled in gen.yml or forced
+func (c *CmdParams) incremental() bool {
+	return !c.Force && (c.Incremental == nil || *c.Incremental)
 }
 
 // YamlConfig is yaml config struct
@@ -55,79 +70,77 @@ type YamlConfig struct {
 
 // connectDB choose db type for connection to database
 func connectDB(t DBType, dsn string) (*gorm.DB, error) {
-	if dsn == "" {
-		return nil, fmt.Errorf("dsn cannot be empty")
-	}
-
-	switch t {
-	case dbMySQL:
-		return gorm.Open(mysql.Open(dsn))
-	case dbPostgres:
-		return gorm.Open(postgres.Open(dsn))
-	case dbSQLite:
-		return gorm.Open(sqlite.Open(dsn))
-	case dbSQLServer:
-		return gorm.Open(sqlserver.Open(dsn))
-	default:
-		return nil, fmt.Errorf("unknow db %q (support mysql || postgres || sqlite || sqlserver for now)", t)
-	}
+	return gen.OpenDB(string(t), dsn)
 }
 
-// genModels is gorm/gen generated models
-func genModels(g *gen.Generator, db *gorm.DB, tables []string) (models []interface{}, err error) {
-	var tablesList []string
-	if len(tables) == 0 {
-		// Execute tasks for all tables in the database
-		tablesList, err = db.Migrator().GetTables()
-		if err != nil {
-			return nil, fmt.Errorf("GORM migrator get all tables fail: %w", err)
-		}
-	} else {
-		tablesList = tables
+// closeDB close connection pool of db
+func closeDB(db *gorm.DB) {
+	if sqlDB, err := db.DB(); err == nil {
+		_ = sqlDB.Close()
 	}
-
-	// Execute some data table tasks
-	models = make([]interface{}, len(tablesList))
-	for i, tableName := range tablesList {
-		models[i] = g.GenerateModel(tableName)
-	}
-	return models, nil
 }
 
-// loadConfigFile load config file from path
-func loadConfigFile(path string) (*CmdParams, error) {
-	file, err := os.Open(path)
+// checkCode print diff of stale generated files and exit non-zero on drift
+func checkCode(g *gen.Generator) {
+	diffs, err := g.Check()
+	for _, d := range diffs {
+		fmt.Print(d.Diff)
+	}
+	if errors.Is(err, gen.ErrCodeStale) {
+		fmt.Fprintf(os.Stderr, "%d generated files are stale\n", len(diffs))
+		os.Exit(1)
+	}
 	if err != nil {
-		return nil, err
+		log.Fatalln("check generated code fail:", err)
 	}
-	defer file.Close() // nolint
+}
+
+// loadConfigFile load config file from path, overlay <name>.<env>.yml when env is not empty
+func loadConfigFile(path, env string) (*CmdParams, error) {
 	var yamlConfig YamlConfig
-	if cmdErr := yaml.NewDecoder(file).Decode(&yamlConfig); cmdErr != nil {
-		return nil, cmdErr
+	if err := gen.LoadConfigFile(path, env, &yamlConfig); err != nil {
+		return nil, err
 	}
 	return yamlConfig.Database, nil
 }
 
-// argParse is parser for cmd
-func argParse() *CmdParams {
+// argParse is parser for cmd, args left after flags are returned, extra defines flags of cmd
+func argParse(cmd string, arguments []string, extra func(fs *flag.FlagSet)) (*CmdParams, []string, error) {
+	fs := flag.NewFlagSet("gentool "+cmd, flag.ExitOnError)
+	fs.Usage = usage(fs)
+	if extra != nil {
+		extra(fs)
+	}
 	// choose is file or flag
-	genPath := flag.String("c", "", "is path for gen.yml")
-	dsn := flag.String("dsn", "", "consult[https://gorm.io/docs/connecting_to_the_database.html]")
-	db := flag.String("db", "mysql", "input mysql or postgres or sqlite or sqlserver. consult[https://gorm.io/docs/connecting_to_the_database.html]")
-	tableList := flag.String("tables", "", "enter the required data table or leave it blank")
-	onlyModel := flag.Bool("onlyModel", false, "only generate models (without query file)")
-	outPath := flag.String("outPath", "./dao/query", "specify a directory for output")
-	outFile := flag.String("outFile", "", "query code file name, default: gen.go")
-	withUnitTest := flag.Bool("withUnitTest", false, "generate unit test for query code")
-	modelPkgName := flag.String("modelPkgName", "", "generated model code's package name")
-	fieldNullable := flag.Bool("fieldNullable", false, "generate with pointer when field is nullable")
-	fieldWithIndexTag := flag.Bool("fieldWithIndexTag", false, "generate field with gorm index tag")
-	fieldWithTypeTag := flag.Bool("fieldWithTypeTag", false, "generate field with gorm column type tag")
-	fieldSignable := flag.Bool("fieldSignable", false, "detect integer field's unsigned type, adjust generated data type")
-	flag.Parse()
+	genPath := fs.String("c", "", "is path for gen.yml")
+	env := fs.String("env", os.Getenv(gen.ConfigEnv), "config overlay to load over gen.yml, e.g. dev loads gen.dev.yml, default: $"+gen.ConfigEnv)
+	dsn := fs.String("dsn", "", "consult[https://gorm.io/docs/connecting_to_the_database.html]")
+	db := fs.String("db", string(dbMySQL), "input mysql or postgres or sqlite or sqlserver, or dialect registered by gen.RegisterDialector. consult[https://gorm.io/docs/connecting_to_the_database.html]")
+	tableList := fs.String("tables", "", "enter the required data table or leave it blank, glob (order_*) or /regexp/ rules are supported")
+	excludeList := fs.String("exclude", "", "tables skipped, glob (*_bak) or /regexp/ rules are supported")
+	onlyModel := fs.Bool("onlyModel", false, "only generate models (without query file)")
+	outPath := fs.String("outPath", "./dao/query", "specify a directory for output")
+	outFile := fs.String("outFile", "", "query code file name, default: gen.go")
+	withUnitTest := fs.Bool("withUnitTest", false, "generate unit test for query code")
+	modelPkgName := fs.String("modelPkgName", "", "generated model code's package name")
+	fieldNullable := fs.Bool("fieldNullable", false, "generate with pointer when field is nullable")
+	fieldWithIndexTag := fs.Bool("fieldWithIndexTag", false, "generate field with gorm index tag")
+	fieldWithTypeTag := fs.Bool("fieldWithTypeTag", false, "generate field with gorm column type tag")
+	fieldSignable := fs.Bool("fieldSignable", false, "detect integer field's unsigned type, adjust generated data type")
+	inferRelations := fs.Bool("inferRelations", false, "generate relation fields from foreign keys and join tables")
+	erDiagram := fs.String("erDiagram", "", "entity relationship diagram formats written into outPath with generated code, comma separated: mermaid, plantuml, dot")
+	dataDictionary := fs.String("dataDictionary", "", "data dictionary formats written into outPath with generated code, comma separated: markdown, html")
+	check := fs.Bool("check", false, "compare generated code with files on disk without writing, print diff and exit non-zero if stale")
+	force := fs.Bool("force", false, "regenerate every table even if its schema and config are unchanged since last run")
+	report := fs.String("report", "text", "progress report format: text or json (one JSON object per line on stderr)")
+	_ = fs.Parse(arguments)
 	var cmdParse CmdParams
 	if *genPath != "" {
-		if configFileParams, err := loadConfigFile(*genPath); err == nil && configFileParams != nil {
+		configFileParams, err := loadConfigFile(*genPath, *env)
+		if err != nil {
+			return nil, nil, err
+		}
+		if configFileParams != nil {
 			cmdParse = *configFileParams
 		}
 	}
@@ -141,6 +154,9 @@ func argParse() *CmdParams {
 	if *tableList != "" {
 		cmdParse.Tables = strings.Split(*tableList, ",")
 	}
+	if *excludeList != "" {
+		cmdParse.Exclude = strings.Split(*excludeList, ",")
+	}
 	if *onlyModel {
 		cmdParse.OnlyModel = true
 	}
@@ -168,18 +184,58 @@ func argParse() *CmdParams {
 	if *fieldSignable {
 		cmdParse.FieldSignable = *fieldSignable
 	}
-	return &cmdParse
+	if *inferRelations {
+		cmdParse.InferRelations = *inferRelations
+	}
+	if *erDiagram != "" {
+		cmdParse.ERDiagram = strings.Split(*erDiagram, ",")
+	}
+	if *dataDictionary != "" {
+		cmdParse.DataDictionary = strings.Split(*dataDictionary, ",")
+	}
+	cmdParse.Check = *check
+	cmdParse.Force = *force
+	cmdParse.Report = *report
+	cmdParse.ConfigFile = *genPath
+	cmdParse.Env = *env
+	return &cmdParse, fs.Args(), nil
 }
 
 func main() {
-	// cmdParse
-	config := argParse()
-	if config == nil {
-		log.Fatalln("parse config fail")
+	cmd, args := "generate", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		cmd, args = args[0], args[1:]
+	}
+
+	var err error
+	switch cmd {
+	case "init":
+		err = initConfig(args)
+	case "generate":
+		err = generate(args)
+	case "tables":
+		err = listTables(args)
+	case "inspect":
+		err = inspectTable(args)
+	case "watch":
+		err = watch(args)
+	case "migrate":
+		err = runMigrate(args)
+	default:
+		err = fmt.Errorf("unknown command %q (support init || generate || tables || inspect || watch || migrate)", cmd)
 	}
-	db, err := connectDB(DBType(config.DB), config.DSN)
 	if err != nil {
-		log.Fatalln("connect db server fail:", err)
+		log.Fatalln(err)
+	}
+}
+
+// newGenerator create generator from config on db, database is connected when db is nil
+func newGenerator(config *CmdParams, db *gorm.DB) (*gen.Generator, *gorm.DB, error) {
+	if db == nil {
+		var err error
+		if db, err = connectDB(DBType(config.DB), config.DSN); err != nil {
+			return nil, nil, fmt.Errorf("connect db server fail: %w", err)
+		}
 	}
 
 	g := gen.NewGenerator(gen.Config{
@@ -191,18 +247,84 @@ func main() {
 		FieldWithIndexTag: config.FieldWithIndexTag,
 		FieldWithTypeTag:  config.FieldWithTypeTag,
 		FieldSignable:     config.FieldSignable,
+		Incremental:       config.incremental(),
 	})
+	g.WithDialect(config.DB)
+	g.WithTableFilter(config.Tables, config.Exclude)
+	if config.InferRelations {
+		g.WithRelationInference(gen.RelationInference{ForeignKeys: true, ManyToMany: true})
+	}
+	if len(config.ERDiagram) > 0 {
+		d := gen.ERDiagram{}
+		for _, format := range config.ERDiagram {
+			d.Formats = append(d.Formats, gen.ERDiagramFormat(strings.TrimSpace(format)))
+		}
+		g.Use(d)
+	}
+	if len(config.DataDictionary) > 0 {
+		d := gen.DataDictionary{}
+		for _, format := range config.DataDictionary {
+			d.Formats = append(d.Formats, gen.DataDictionaryFormat(strings.TrimSpace(format)))
+		}
+		g.Use(d)
+	}
+
+	switch config.Report {
+	case "text":
+	case "json":
+		g.WithReporter(gen.NewJSONReporter(os.Stderr)) // keep stdout for diffs of check and command output
+	default:
+		return nil, nil, fmt.Errorf("unknown report format %q (support text || json)", config.Report)
+	}
 
 	g.UseDB(db)
+	return g, db, nil
+}
+
+// generate generate code of selected tables
+func generate(args []string) error {
+	config, _, err := argParse("generate", args, nil)
+	if err != nil {
+		return fmt.Errorf("parse config fail: %w", err)
+	}
+	return runGenerate(config, nil)
+}
 
-	models, err := genModels(g, db, config.Tables)
+// runGenerate generate code with config on db, database is connected and closed after generating when db is nil
+func runGenerate(config *CmdParams, db *gorm.DB) error {
+	if db == nil {
+		var err error
+		if db, err = connectDB(DBType(config.DB), config.DSN); err != nil {
+			return fmt.Errorf("connect db server fail: %w", err)
+		}
+		defer closeDB(db)
+	}
+	g, _, err := newGenerator(config, db)
 	if err != nil {
-		log.Fatalln("get tables info fail:", err)
+		return err
+	}
+
+	models, err := g.GenerateAllTableE()
+	if err != nil {
+		return fmt.Errorf("get tables info fail: %w", err)
 	}
 
 	if !config.OnlyModel {
-		g.ApplyBasic(models...)
+		if err = g.ApplyBasicE(models...); err != nil {
+			return fmt.Errorf("apply basic fail: %w", err)
+		}
+		if err = g.ApplyInterfaceConfE(config.Interfaces, models...); err != nil {
+			return err
+		}
 	}
 
-	g.Execute()
+	if config.Check {
+		checkCode(g)
+		return nil
+	}
+
+	if err = g.ExecuteE(); err != nil {
+		return fmt.Errorf("generate code fail: %w", err)
+	}
+	return nil
 }
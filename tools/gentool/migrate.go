@@ -0,0 +1,96 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"gorm.io/gen/migrate"
+)
+
+// runMigrate apply, revert or list sql migrations of migrationDir
+func runMigrate(args []string) error {
+	var (
+		dir, table  string
+		lockTimeout time.Duration
+	)
+	extra := func(fs *flag.FlagSet) {
+		fs.StringVar(&dir, "migrationDir", "", "directory of <version>_<name>.up.sql and <version>_<name>.down.sql migrations (default \"migrations\")")
+		fs.StringVar(&table, "migrationTable", "", "history table of applied migrations (default \""+migrate.DefaultTable+"\")")
+		fs.DurationVar(&lockTimeout, "lockTimeout", time.Minute, "how long to wait for another instance holding the migration lock")
+	}
+	config, rest, err := argParse("migrate", args, extra)
+	if err != nil {
+		return fmt.Errorf("parse config fail: %w", err)
+	}
+	if dir != "" {
+		config.MigrationDir = dir
+	}
+	if config.MigrationDir == "" {
+		config.MigrationDir = "migrations"
+	}
+	if table != "" {
+		config.MigrationTable = table
+	}
+
+	errUsage := fmt.Errorf("usage: gentool migrate [flags] up || down || status || goto <version> || unlock")
+	if len(rest) == 0 {
+		return errUsage
+	}
+	db, err := connectDB(DBType(config.DB), config.DSN)
+	if err != nil {
+		return fmt.Errorf("connect db server fail: %w", err)
+	}
+	m := migrate.New(db, migrate.Config{
+		Dir:         config.MigrationDir,
+		Table:       config.MigrationTable,
+		LockTimeout: lockTimeout,
+		Logf:        log.Printf,
+	})
+
+	switch op := rest[0]; {
+	case op == "up" && len(rest) == 1:
+		return m.Up()
+	case op == "down" && len(rest) == 1:
+		return m.Down()
+	case op == "status" && len(rest) == 1:
+		return printMigrationStatus(m)
+	case op == "unlock" && len(rest) == 1:
+		return m.Unlock()
+	case op == "goto" && len(rest) == 2:
+		version, err := strconv.ParseInt(rest[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version %q: %w", rest[1], err)
+		}
+		return m.Goto(version)
+	}
+	return errUsage
+}
+
+// printMigrationStatus print migrations with their state
+func printMigrationStatus(m *migrate.Migrator) error {
+	status, err := m.Status()
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, s := range status {
+		state, appliedAt := "pending", "-"
+		if s.Applied {
+			state, appliedAt = "applied", s.AppliedAt.Format(time.RFC3339)
+		}
+		switch {
+		case s.Missing:
+			state += ", missing"
+		case s.Changed:
+			state += ", changed"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", s.Version, s.Name, state, appliedAt)
+	}
+	return w.Flush()
+}
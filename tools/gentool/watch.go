@@ -0,0 +1,185 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gen"
+	"gorm.io/gorm"
+)
+
+// watcher fingerprints of watched files and table schemas
+type watcher struct {
+	files  map[string]string // path -> modification time and size
+	tables map[string]string // table -> schema fingerprint
+
+	dsn string
+	db  *gorm.DB
+}
+
+// watch generate code, then poll files and schema and generate again on changes.
+// Incremental generation is always on after the first round, so only changed models are rendered again.
+func watch(args []string) error {
+	var interval time.Duration
+	extra := func(fs *flag.FlagSet) {
+		fs.DurationVar(&interval, "interval", 2*time.Second, "interval of polling files and database schema")
+	}
+	if _, _, err := argParse("watch", args, extra); err != nil {
+		return fmt.Errorf("parse config fail: %w", err)
+	}
+
+	w := &watcher{}
+	for first := true; ; first = false {
+		// parse again every round to pick up changes of gen.yml
+		config, _, err := argParse("watch", args, extra)
+		if err != nil {
+			log.Println("parse config fail:", err)
+			time.Sleep(interval)
+			continue
+		}
+		config.Check = false
+		incremental := true
+		config.Incremental = &incremental // -force applies to the first round only
+		config.Force = config.Force && first
+
+		changes, err := w.poll(config)
+		if err != nil {
+			log.Println("watch fail:", err)
+		}
+		if first || len(changes) > 0 {
+			if !first {
+				log.Printf("changed: %s, generating", strings.Join(changes, ", "))
+			}
+			// reuse connection of schema polling, a new pool every round would never be closed
+			if err = runGenerate(config, w.db); err != nil {
+				log.Println(err)
+			}
+		}
+		time.Sleep(interval)
+	}
+}
+
+// poll fingerprint watched files and schema, return what changed since last poll
+func (w *watcher) poll(config *CmdParams) (changes []string, err error) {
+	files := w.watchedFiles(config)
+	changes = append(changes, diffFingerprints(w.files, files, "file ")...)
+	w.files = files
+
+	tables, err := w.schemaFingerprints(config)
+	if err != nil {
+		return changes, err
+	}
+	changes = append(changes, diffFingerprints(w.tables, tables, "table ")...)
+	w.tables = tables
+	return changes, nil
+}
+
+// watchedFiles fingerprint gen.yml with its env overlay, interface source files and sql files of ddl dialects
+func (w *watcher) watchedFiles(config *CmdParams) map[string]string {
+	paths := []string{}
+	if config.ConfigFile != "" {
+		paths = append(paths, config.ConfigFile)
+		if config.Env != "" {
+			paths = append(paths, gen.ConfigOverlay(config.ConfigFile, config.Env))
+		}
+	}
+	if strings.HasSuffix(config.DB, "-ddl") {
+		for _, pattern := range strings.Split(config.DSN, ",") {
+			matches, _ := filepath.Glob(strings.TrimSpace(pattern))
+			paths = append(paths, matches...)
+		}
+	}
+	for _, conf := range config.Interfaces {
+		files, err := conf.SourceFiles()
+		if err != nil {
+			log.Printf("watch interface %s fail: %s", conf.Name, err)
+			continue
+		}
+		paths = append(paths, files...)
+	}
+
+	files := make(map[string]string, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			files[path] = "missing"
+			continue
+		}
+		files[path] = fmt.Sprintf("%d-%d", info.ModTime().UnixNano(), info.Size())
+	}
+	return files
+}
+
+// schemaFingerprints fingerprint columns and indexes of selected tables
+func (w *watcher) schemaFingerprints(config *CmdParams) (map[string]string, error) {
+	if w.db == nil || w.dsn != config.DB+config.DSN {
+		db, err := connectDB(DBType(config.DB), config.DSN)
+		if err != nil {
+			return nil, fmt.Errorf("connect db server fail: %w", err)
+		}
+		if w.db != nil {
+			closeDB(w.db)
+		}
+		w.db, w.dsn = db, config.DB+config.DSN
+	}
+
+	tables, err := w.db.Migrator().GetTables()
+	if err != nil {
+		return nil, fmt.Errorf("GORM migrator get all tables fail: %w", err)
+	}
+	if tables, err = (gen.TableFilter{Include: config.Tables, Exclude: config.Exclude}).Filter(tables, nil); err != nil {
+		return nil, err
+	}
+
+	fingerprints := make(map[string]string, len(tables))
+	for _, table := range tables {
+		columns, err := w.db.Migrator().ColumnTypes(table)
+		if err != nil {
+			return nil, fmt.Errorf("get columns of %s fail: %w", table, err)
+		}
+		h := sha256.New()
+		for _, col := range columns {
+			colType, _ := col.ColumnType()
+			nullable, _ := col.Nullable()
+			primary, _ := col.PrimaryKey()
+			def, _ := col.DefaultValue()
+			comment, _ := col.Comment()
+			fmt.Fprintf(h, "%s|%s|%s|%t|%t|%s|%s\n", col.Name(), col.DatabaseTypeName(), colType, nullable, primary, def, comment)
+		}
+		if indexes, err := w.db.Migrator().GetIndexes(table); err == nil {
+			for _, idx := range indexes {
+				unique, _ := idx.Unique()
+				fmt.Fprintf(h, "index|%s|%s|%t\n", idx.Name(), strings.Join(idx.Columns(), ","), unique)
+			}
+		}
+		fingerprints[table] = hex.EncodeToString(h.Sum(nil))
+	}
+	return fingerprints, nil
+}
+
+// diffFingerprints return sorted names added, removed or changed, nothing on first poll
+func diffFingerprints(prev, curr map[string]string, prefix string) (changes []string) {
+	if prev == nil {
+		return nil
+	}
+	for name, fp := range curr {
+		if prev[name] != fp {
+			changes = append(changes, prefix+name)
+		}
+	}
+	for name := range prev {
+		if _, ok := curr[name]; !ok {
+			changes = append(changes, prefix+name)
+		}
+	}
+	sort.Strings(changes)
+	return changes
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiffFingerprints(t *testing.T) {
+	if changes := diffFingerprints(nil, map[string]string{"a": "1"}, "file "); changes != nil {
+		t.Errorf("first poll should report nothing, got %v", changes)
+	}
+	prev := map[string]string{"same": "1", "changed": "1", "removed": "1"}
+	curr := map[string]string{"same": "1", "changed": "2", "added": "1"}
+	want := []string{"table added", "table changed", "table removed"}
+	if changes := diffFingerprints(prev, curr, "table "); !reflect.DeepEqual(changes, want) {
+		t.Errorf("expect %v, got %v", want, changes)
+	}
+}
+
+func TestWatcher_Poll(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "gen.yml")
+	if err := os.WriteFile(configFile, []byte("version: \"0.1\"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	config := &CmdParams{DB: string(dbSQLite), DSN: filepath.Join(dir, "watch.db"), ConfigFile: configFile, Env: "dev", Exclude: []string{"logs"}}
+
+	w := &watcher{}
+	poll := func(want ...string) {
+		t.Helper()
+		changes, err := w.poll(config)
+		if err != nil {
+			t.Fatalf("poll fail: %s", err)
+		}
+		if len(want) == 0 {
+			want = nil
+		}
+		if !reflect.DeepEqual(changes, want) {
+			t.Errorf("expect changes %v, got %v", want, changes)
+		}
+	}
+	exec := func(sql string) {
+		t.Helper()
+		if err := w.db.Exec(sql).Error; err != nil {
+			t.Fatalf("exec %s fail: %s", sql, err)
+		}
+	}
+
+	poll()
+	exec("CREATE TABLE users (id integer PRIMARY KEY)")
+	exec("CREATE TABLE logs (id integer PRIMARY KEY)")
+	poll("table users")
+	exec("ALTER TABLE users ADD COLUMN name text")
+	exec("ALTER TABLE logs ADD COLUMN message text")
+	poll("table users")
+	poll()
+
+	overlay := filepath.Join(dir, "gen.dev.yml")
+	if err := os.WriteFile(overlay, []byte("database:\n  outPath: ./dev\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	poll("file " + overlay)
+	later := time.Now().Add(time.Second)
+	if err := os.Chtimes(configFile, later, later); err != nil {
+		t.Fatal(err)
+	}
+	poll("file " + configFile)
+
+	old, err := w.db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.DSN = filepath.Join(dir, "other.db")
+	poll("table users")
+	if err = old.Ping(); err == nil {
+		t.Errorf("connection pool of previous dsn should be closed")
+	}
+}